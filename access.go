@@ -0,0 +1,218 @@
+package alog
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Wraps an http.Handler, logging each request once it completes, with the
+// message "request" and the fields
+//
+//	remote_addr user method uri proto status bytes referer user_agent duration
+//
+// user is the basic auth user name, if any, and status is 101 for hijacked
+// connections, such as WebSocket upgrades.  The request's logger is used
+// if its context has one, e.g. from RequestIDHandler.  See
+// AccessLogFormatter for Apache style output.
+func (a *Log) AccessLogHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		h.ServeHTTP(sw, r)
+
+		log := FromContext(r.Context())
+		if log == nil {
+			log = a
+		}
+		if sw.status == 0 {
+			sw.status = http.StatusOK
+		}
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		user, _, _ := r.BasicAuth()
+		log.output(InfoLevel, message{fmtString, "request", nil, []Field{
+			{"remote_addr", host},
+			{"user", user},
+			{"method", r.Method},
+			{"uri", r.RequestURI},
+			{"proto", r.Proto},
+			{"status", sw.status},
+			{"bytes", sw.bytes},
+			{"referer", r.Referer()},
+			{"user_agent", r.UserAgent()},
+			{"duration", time.Since(start)},
+		}})
+	})
+}
+
+// ResponseWriter recording the status and body size
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// For WebSocket libraries, which type-assert http.Hijacker
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// For http.ResponseController
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Formats the entries of AccessLogHandler in the NCSA common log format,
+// or with Combined, Apache's combined format:
+//
+//	10.0.0.1 - alice [02/Jan/2020:03:04:05 +0000] "GET /a?b=c HTTP/1.1" 200 512 "https://example.com/" "curl/7.68.0"
+//
+// Missing fields are written as "-".  The time is the entry's, so the
+// logger's flags should include a date; otherwise the current time is used.
+type AccessLogFormatter struct {
+	Combined bool
+}
+
+const ncsaTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+func (f AccessLogFormatter) Format(b []byte, e *Entry) []byte {
+	var host, user, method, uri, proto, referer, agent string
+	var status, bytes interface{}
+	for _, fl := range e.Fields {
+		switch fl.Key {
+		case "remote_addr":
+			host, _ = fl.Value.(string)
+		case "user":
+			user, _ = fl.Value.(string)
+		case "method":
+			method, _ = fl.Value.(string)
+		case "uri":
+			uri, _ = fl.Value.(string)
+		case "proto":
+			proto, _ = fl.Value.(string)
+		case "referer":
+			referer, _ = fl.Value.(string)
+		case "user_agent":
+			agent, _ = fl.Value.(string)
+		case "status":
+			status = fl.Value
+		case "bytes":
+			bytes = fl.Value
+		}
+	}
+
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	b = appendNCSAField(b, host)
+	b = append(b, " - "...)
+	b = appendNCSAField(b, user)
+	b = append(b, " ["...)
+	b = t.AppendFormat(b, ncsaTimeFormat)
+	b = append(b, "] "...)
+	if method == "" {
+		b = appendNCSAQuoted(b, "")
+	} else {
+		b = appendNCSAQuoted(b, method+" "+uri+" "+proto)
+	}
+	b = append(b, ' ')
+	b = appendNCSANumber(b, status)
+	b = append(b, ' ')
+	b = appendNCSANumber(b, bytes)
+	if f.Combined {
+		b = append(b, ' ')
+		b = appendNCSAQuoted(b, referer)
+		b = append(b, ' ')
+		b = appendNCSAQuoted(b, agent)
+	}
+	return b
+}
+
+func appendNCSAField(b []byte, s string) []byte {
+	if s == "" {
+		return append(b, '-')
+	}
+	return appendNCSAEscaped(b, s)
+}
+
+func appendNCSAQuoted(b []byte, s string) []byte {
+	if s == "" {
+		return append(b, `"-"`...)
+	}
+	b = append(b, '"')
+	b = appendNCSAEscaped(b, s)
+	return append(b, '"')
+}
+
+// Escapes backslashes, quotes and unprintable bytes as Apache does, so
+// fields can't break the line's structure
+func appendNCSAEscaped(b []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' || c == '"':
+			b = append(b, '\\', c)
+		case c < ' ' || c > '~':
+			b = append(b, '\\', 'x', hex[c>>4], hex[c&0xf])
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
+
+// Writes an integer field, or "-" for a missing or zero value
+func appendNCSANumber(b []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(b, '-')
+	case int:
+		return appendNCSANumber(b, int64(v))
+	case int64:
+		if v == 0 {
+			return append(b, '-')
+		}
+		return strconv.AppendInt(b, v, 10)
+	default:
+		// Changed by a key formatter
+		return appendNCSAField(b, string(appendValue(nil, v)))
+	}
+}
@@ -0,0 +1,103 @@
+package alog
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestAccessLogHandler(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+
+	h := log.AccessLogHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, "hello")
+	}))
+
+	r := httptest.NewRequest("GET", "/a?b=c", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.SetBasicAuth("alice", "secret")
+	r.Header.Set("User-Agent", "test")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	c.Assert(t.last(), check.Matches, `\[remote_addr=10.0.0.1 user=alice method=GET uri=/a\?b=c proto=HTTP/1.1 status=200 bytes=5 referer= user_agent=test duration=.*\] request\n`)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+	c.Assert(t.last(), check.Matches, `.* status=404 .*\n`)
+
+	// Combined format
+	log.SetFormatter(AccessLogFormatter{Combined: true})
+	r.Header.Set("Referer", "https://example.com/")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	c.Assert(t.last(), check.Matches, `10\.0\.0\.1 - alice \[\d\d/\w{3}/\d{4}:\d\d:\d\d:\d\d [-+]\d{4}\] "GET /a\?b=c HTTP/1\.1" 200 5 "https://example\.com/" "test"\n`)
+}
+
+func (s *Suite) TestAccessLogHijack(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+
+	h := log.AccessLogHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+	}))
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r)
+		close(done)
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "test")
+	resp, err := http.DefaultClient.Do(req)
+	c.Assert(err, check.IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, check.Equals, http.StatusSwitchingProtocols)
+
+	<-done
+	c.Assert(t.last(), check.Matches, `.* status=101 bytes=0 .*\n`)
+
+	// Not supported by the underlying ResponseWriter
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	c.Assert(t.last(), check.Matches, `.* status=500 .*\n`)
+}
+
+func (s *Suite) TestAccessLogFormatter(c *check.C) {
+	e := &Entry{
+		Time:    time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Message: "request",
+		Fields: []Field{
+			{"remote_addr", "::1"},
+			{"method", "POST"},
+			{"uri", `/"x"`},
+			{"proto", "HTTP/2.0"},
+			{"status", 204},
+			{"bytes", int64(0)},
+			{"user_agent", "a\tb"},
+		},
+	}
+	b := AccessLogFormatter{}.Format(nil, e)
+	c.Assert(string(b), check.Equals, `::1 - - [02/Jan/2020:03:04:05 +0000] "POST /\"x\" HTTP/2.0" 204 -`)
+	b = AccessLogFormatter{Combined: true}.Format(nil, e)
+	c.Assert(string(b), check.Equals, `::1 - - [02/Jan/2020:03:04:05 +0000] "POST /\"x\" HTTP/2.0" 204 - "-" "a\x09b"`)
+
+	// Other entries
+	b = AccessLogFormatter{}.Format(nil, &Entry{Time: e.Time, Message: "x"})
+	c.Assert(string(b), check.Equals, `- - - [02/Jan/2020:03:04:05 +0000] "-" - -`)
+}
@@ -1,16 +1,21 @@
 package alog
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"strings"
+	"runtime"
 	"sync"
+	"time"
 )
 
 const defaultCalldepth = 3
 
+// Replaced in tests
+var osExit = os.Exit
+
 // Logger used when *alog.Log is nil
 var fallback = log.New(os.Stdout, "", log.Flags())
 
@@ -18,23 +23,72 @@ var fallback = log.New(os.Stdout, "", log.Flags())
 type Log struct {
 	*log.Logger
 	*Meta
-	out       io.Writer
-	calldepth int
+	calldepth     int
+	prefix        string
+	fieldPosition FieldPosition
+	stackLevel    Level
+	hexdumpMax    int
+	writeMutex    *sync.Mutex
+	goroutineID   bool
+	sanitize      bool
+	multiline     Multiline
+	scrubbers     []Scrubber
+	location      *time.Location
+	exitCode      int
+	fatalGoexit   bool
+	onError       func(error)
+	paths         *pathTrim
+	skipPkgs      []string
+	keyPolicy     *KeyPolicy
+	duplicates    DuplicatePolicy
+	schema        Schema
+	providers     []FieldProvider
+	keyFormats    map[string]ValueFormatter
+	seq           *uint64
+	trace         *traceBuffer
+	runtimeTrace  bool
+	traceKeys     []string
+	traceCtx      context.Context
+	sampling      *sampling
+	level         *LevelVar
+	formatter     *formatterVar
 }
 
 func New(out io.Writer) *Log {
 	return newAdvanced(out, log.Flags(), defaultCalldepth)
 }
 
+// Returns a logger that writes nothing, for libraries to use when they are
+// given no logger.  Entries are discarded before they are formatted; Panic
+// and Fatal still panic and exit.
+func Nop() *Log {
+	return New(io.Discard)
+}
+
 func newAdvanced(out io.Writer, flags, calldepth int) *Log {
-	return &Log{log.New(out, "", flags), &Meta{}, out, calldepth}
+	return &Log{
+		Logger:     log.New(out, "", flags),
+		Meta:       &Meta{},
+		calldepth:  calldepth,
+		writeMutex: &sync.Mutex{},
+		level:      &LevelVar{},
+		sampling:   &sampling{exempt: PanicLevel},
+		formatter:  &formatterVar{},
+		exitCode:   1,
+		stackLevel: noStackLevel,
+		hexdumpMax: defaultHexdumpMax,
+	}
 }
 
 func (a *Log) Copy() *Log {
 	if a == nil {
 		return nil
 	}
-	return &Log{log.New(a.out, "", a.Logger.Flags()), a.Meta.copy(), a.out, defaultCalldepth}
+	b := *a
+	b.Logger = log.New(a.Logger.Writer(), "", a.Logger.Flags())
+	b.Meta = a.Meta.copy()
+	b.calldepth = defaultCalldepth
+	return &b
 }
 
 // Sets a key-value for inclusion in the log prefix
@@ -42,12 +96,94 @@ func (a *Log) Set(k string, v interface{}) *Log {
 	if a == nil {
 		return nil
 	}
-	a.Meta.set(k, v)
+	a.setMeta(k, v)
+	return a
+}
+
+// Sets each field, in order
+func (a *Log) SetFields(fields ...Field) *Log {
+	if a == nil {
+		return nil
+	}
+	for _, f := range fields {
+		a.setMeta(f.Key, f.Value)
+	}
+	return a
+}
+
+// Copies other's Meta into this logger, as if by Set: on conflict other's
+// value is used, and the key keeps its current position.  New keys are added
+// in other's order.
+func (a *Log) Merge(other *Log) *Log {
+	if a == nil {
+		return nil
+	}
+	if other != nil {
+		a.Meta.merge(other.Meta)
+	}
+	return a
+}
+
+// Includes a goroutine=N field in every entry.  The id is only useful for
+// telling goroutines apart while debugging; don't rely on it otherwise.
+func (a *Log) SetGoroutineID(enabled bool) *Log {
+	if a == nil {
+		return nil
+	}
+	a.goroutineID = enabled
+	return a
+}
+
+// Registers a provider whose fields are added to every entry at the time it
+// is written.  Copies made afterwards keep the provider.
+func (a *Log) AddFieldProvider(p FieldProvider) *Log {
+	if a == nil {
+		return nil
+	}
+	// Never append into a backing array shared with a copy
+	n := len(a.providers)
+	a.providers = append(a.providers[:n:n], p)
+	return a
+}
+
+// Sets a static prefix, such as "db: ", written after the header and level
+// and before the fields.  Unlike the embedded Logger's prefix, it keeps the
+// timestamp at the start of the line, and copies keep it.  Formatters
+// receive it at the start of the message.
+func (a *Log) SetMessagePrefix(prefix string) *Log {
+	if a == nil {
+		return nil
+	}
+	a.prefix = prefix
 	return a
 }
 
+func (a *Log) MessagePrefix() string {
+	if a == nil {
+		return ""
+	}
+	return a.prefix
+}
+
+// Sets err under "error".  The text prefix renders it in single quotes;
+// the Meta, field providers, formatters and EntryWriters see err itself.
 func (a *Log) SetError(err error) *Log {
-	return a.Set("error", fmt.Sprintf("'%s'", err))
+	return a.Set("error", err)
+}
+
+// Renders an error in single quotes, as the text prefix shows the
+// logger's error
+type quotedError struct {
+	err error
+}
+
+// Quotes an error under "error" among the first n fields, the logger's own
+func quoteError(fields []Field, n int) {
+	for i := 0; i < n; i++ {
+		if err, ok := fields[i].Value.(error); ok && fields[i].Key == "error" {
+			fields[i].Value = quotedError{err}
+		}
+	}
 }
 
 // Shorthand for .Copy().Set(k, v).  Use for temporary k:v values.
@@ -55,101 +191,281 @@ func (a *Log) With(k string, v interface{}) *Log {
 	return a.Copy().Set(k, v)
 }
 
-// Shorthand for .With("error", err),
-// and encapsulates the error string in single quotes.
+// Shorthand for .Copy().SetFields(fields...)
+func (a *Log) WithFields(fields ...Field) *Log {
+	return a.Copy().SetFields(fields...)
+}
+
+// Shorthand for .Copy().SetError(err)
 func (a *Log) WithError(err error) *Log {
 	return a.Copy().SetError(err)
 }
 
 func (a *Log) Sprint(v ...interface{}) string {
-	// Sprint doesn't put spaces between the arguments, add one for the prefix
-	var prefixArgs []interface{}
-	prefix := a.prefix()
-	if prefix != "" {
-		prefixArgs = []interface{}{prefix, " "}
-	}
-
-	return fmt.Sprint(append(prefixArgs, v...)...)
+	return string(a.appendMessage(nil, message{fmtPrint, "", v, nil}))
 }
 
 func (a *Log) Sprintf(f string, v ...interface{}) string {
-	return fmt.Sprintf(a.addPrefix(f), v...)
+	return string(a.appendMessage(nil, message{fmtPrintf, f, v, nil}))
 }
 
 func (a *Log) Sprintln(v ...interface{}) string {
-	var prefixArgs []interface{}
-	prefix := a.prefix()
-	if prefix != "" {
-		prefixArgs = []interface{}{prefix}
-	}
-
-	return fmt.Sprintln(append(prefixArgs, v...)...)
+	return string(a.appendMessage(nil, message{fmtPrintln, "", v, nil}))
 }
 
 func (a *Log) Fatal(v ...interface{}) {
-	a.output(a.Sprint(v...))
-	os.Exit(1)
+	a.output(FatalLevel, message{fmtPrint, "", v, nil})
+	a.exit()
 }
 
 func (a *Log) Fatalf(f string, v ...interface{}) {
-	a.output(a.Sprintf(f, v...))
-	os.Exit(1)
+	a.output(FatalLevel, message{fmtPrintf, f, v, nil})
+	a.exit()
 }
 
 func (a *Log) Fatalln(v ...interface{}) {
-	a.output(a.Sprintln(v...))
-	os.Exit(1)
+	a.output(FatalLevel, message{fmtPrintln, "", v, nil})
+	a.exit()
+}
+
+// Sets the status Fatal* exit with, 1 by default.  Copies keep the setting.
+func (a *Log) SetFatalExitCode(code int) *Log {
+	if a == nil {
+		return nil
+	}
+	a.exitCode = code
+	return a
+}
+
+// Flushes the writer and runs the exit hooks before exiting, so buffered
+// entries aren't lost
+func (a *Log) exit() {
+	a.Flush()
+	if a != nil && a.fatalGoexit {
+		runtime.Goexit()
+	}
+	runExitHooks()
+	if a == nil {
+		osExit(1)
+		return
+	}
+	osExit(a.exitCode)
 }
 
 func (a *Log) Panic(v ...interface{}) {
-	a.output(a.Sprint(v...))
-	panic(fmt.Sprint(v...))
+	s := fmt.Sprint(v...)
+	a.output(PanicLevel, message{fmtString, s, nil, nil})
+	panic(s)
 }
 
 func (a *Log) Panicf(f string, v ...interface{}) {
-	a.output(a.Sprintf(f, v...))
-	panic(fmt.Sprintf(f, v...))
+	s := fmt.Sprintf(f, v...)
+	a.output(PanicLevel, message{fmtString, s, nil, nil})
+	panic(s)
 }
 
 func (a *Log) Panicln(v ...interface{}) {
-	a.output(a.Sprintln(v...))
-	panic(fmt.Sprintln(v...))
+	s := fmt.Sprintln(v...)
+	a.output(PanicLevel, message{fmtString, s, nil, nil})
+	panic(s)
+}
+
+func (a *Log) Error(v ...interface{}) {
+	a.output(ErrorLevel, message{fmtPrint, "", v, nil})
+}
+
+func (a *Log) Errorf(f string, v ...interface{}) {
+	a.output(ErrorLevel, message{fmtPrintf, f, v, nil})
+}
+
+func (a *Log) Errorln(v ...interface{}) {
+	a.output(ErrorLevel, message{fmtPrintln, "", v, nil})
+}
+
+func (a *Log) Warn(v ...interface{}) {
+	a.output(WarnLevel, message{fmtPrint, "", v, nil})
 }
 
+func (a *Log) Warnf(f string, v ...interface{}) {
+	a.output(WarnLevel, message{fmtPrintf, f, v, nil})
+}
+
+func (a *Log) Warnln(v ...interface{}) {
+	a.output(WarnLevel, message{fmtPrintln, "", v, nil})
+}
+
+// Print* log at InfoLevel
 func (a *Log) Print(v ...interface{}) {
-	a.output(a.Sprint(v...))
+	a.output(InfoLevel, message{fmtPrint, "", v, nil})
 }
 
 func (a *Log) Printf(f string, v ...interface{}) {
-	a.output(a.Sprintf(f, v...))
+	a.output(InfoLevel, message{fmtPrintf, f, v, nil})
 }
 
 func (a *Log) Println(v ...interface{}) {
-	a.output(a.Sprintln(v...))
+	a.output(InfoLevel, message{fmtPrintln, "", v, nil})
+}
+
+// Print* with fields added to this entry alone, without copying or
+// changing the logger's Meta
+func (a *Log) PrintWith(fields []Field, v ...interface{}) {
+	a.output(InfoLevel, message{fmtPrint, "", v, fields})
+}
+
+func (a *Log) PrintfWith(fields []Field, f string, v ...interface{}) {
+	a.output(InfoLevel, message{fmtPrintf, f, v, fields})
+}
+
+func (a *Log) PrintlnWith(fields []Field, v ...interface{}) {
+	a.output(InfoLevel, message{fmtPrintln, "", v, fields})
+}
+
+func (a *Log) Debug(v ...interface{}) {
+	a.output(DebugLevel, message{fmtPrint, "", v, nil})
+}
+
+func (a *Log) Debugf(f string, v ...interface{}) {
+	a.output(DebugLevel, message{fmtPrintf, f, v, nil})
 }
 
-func (a *Log) output(s string) {
+func (a *Log) Debugln(v ...interface{}) {
+	a.output(DebugLevel, message{fmtPrintln, "", v, nil})
+}
+
+// Writes msg as an entry at level, with the logger's prefix, as the logging
+// methods do.  For wrappers and adapters that format messages themselves.
+// calldepth counts as for log.Logger.Output: 1 reports the caller of
+// Output.  Panic and Fatal entries are written without panicking or
+// exiting.
+//
+// Note that this shadows log.Logger's Output, which has no level.
+func (a *Log) Output(calldepth int, level Level, msg string) error {
 	if a == nil {
-		fallback.Output(defaultCalldepth, s)
-	} else {
-		a.Logger.Output(a.calldepth, s)
+		return fallback.Output(calldepth+1, level.tag()+msg)
+	}
+	if !a.enabled(level) {
+		return nil
 	}
+	return a.write(calldepth+1, level, message{fmtString, msg, nil, nil})
 }
 
-func (a *Log) prefix() string {
+// Writes the message, tagged with the level, unless the level is disabled or
+// the entry is sampled out
+func (a *Log) output(level Level, m message) {
 	if a == nil {
-		return ""
+		fallback.Output(defaultCalldepth, level.tag()+string(m.appendTo(nil)))
+		return
+	}
+	if !a.enabled(level) {
+		return
+	}
+	a.write(a.calldepth, level, m)
+}
+
+// Panic and Fatal entries are always written, unless the writer is
+// io.Discard, in which case nothing is.  With a trace buffer, entries below
+// the level are kept for it.
+func (a *Log) enabled(level Level) bool {
+	if level < PanicLevel {
+		if level < a.level.Level() && a.trace == nil {
+			return false
+		}
+		if !a.sampled(level) {
+			return false
+		}
+	}
+	return !discards(a.Logger.Writer())
+}
+
+// Reports whether w is io.Discard, or a logger from Build currently
+// writing only to io.Discard.  Other wrappers around io.Discard aren't
+// seen through, so their entries are still formatted.
+func discards(w io.Writer) bool {
+	if sw, ok := w.(*switchWriter); ok {
+		sw.mutex.RLock()
+		defer sw.mutex.RUnlock()
+		w = sw.w
+	}
+	return w == io.Discard
+}
+
+// Appends the prefix, "[k=v ...] ", if there are any fields.  extra follow
+// the logger's fields.
+func (a *Log) appendPrefix(b []byte, extra []Field) []byte {
+	return a.appendFieldBlock(b, extra, "", " ")
+}
+
+// Appends before, the fields in brackets, then after, if there are any
+// fields
+func (a *Log) appendFieldBlock(b []byte, extra []Field, before, after string) []byte {
+	fp := getFields()
+	defer putFields(fp)
+	*fp = a.appendEntryFields(*fp)
+	n := len(*fp)
+	*fp = append(*fp, extra...)
+	fields := a.formatValues(*fp)
+	if len(fields) == 0 {
+		return b
+	}
+	quoteError(fields, n)
+
+	b = append(b, before...)
+	b = append(b, '[')
+	b = appendFields(b, fields, " ")
+	b = append(b, ']')
+	return append(b, after...)
+}
+
+// Returns the fields of an entry written now: the Meta, then any provided
+// fields
+func (a *Log) entryFields() []Field {
+	return a.appendEntryFields(nil)
+}
+
+func (a *Log) appendEntryFields(fields []Field) []Field {
+	if a == nil {
+		return fields
+	}
+
+	fields = a.Meta.appendFields(fields)
+	for _, p := range a.providers {
+		fields = append(fields, p.Fields()...)
+	}
+	if a.goroutineID {
+		fields = append(fields, Field{"goroutine", goroutineID()})
+	}
+	if n := a.Meta.trimmedCount(); n > 0 {
+		fields = append(fields, Field{"meta_trimmed", n})
 	}
-	return a.Meta.format(" ", "[%s]")
+	return fields
 }
 
-// Prepends the prefix to the output string
-func (a *Log) addPrefix(s string) string {
-	prefix := a.prefix()
-	if prefix != "" {
-		return prefix + " " + s
+// Appends the static prefix and the message, with the fields before or
+// after it as set by SetFieldPosition.  A final newline stays last.
+func (a *Log) appendMessage(b []byte, m message) []byte {
+	if a != nil {
+		b = append(b, a.prefix...)
 	}
-	return s
+	if a == nil || a.fieldPosition == FieldsBeforeMessage {
+		return m.appendTo(a.appendPrefix(b, m.fields))
+	}
+
+	start := len(b)
+	b = m.appendTo(b)
+	nl := len(b) > start && b[len(b)-1] == '\n'
+	if nl {
+		b = b[:len(b)-1]
+	}
+	sep := " "
+	if len(b) == start {
+		sep = ""
+	}
+	b = a.appendFieldBlock(b, m.fields, sep, "")
+	if nl {
+		b = append(b, '\n')
+	}
+	return b
 }
 
 ////////////////////////////////////////////////
@@ -159,10 +475,19 @@ func (a *Log) addPrefix(s string) string {
 type MetaEntry struct {
 	value interface{}
 	order int
+	size  int // Only measured with a byte limit
 }
 
+// Entries are kept in insertion order.  An entry's order is its index in
+// keys.  While shared, entries and keys also belong to a snapshot, and are
+// copied before they are changed.
 type Meta struct {
 	entries map[string]MetaEntry
+	keys    []string
+	limit   metaLimit
+	size    int
+	trimmed int
+	shared  bool
 	mutex   sync.RWMutex
 }
 
@@ -175,43 +500,90 @@ func (m *Meta) get(k string) interface{} {
 func (m *Meta) set(k string, v interface{}) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
+	m.setLocked(k, v)
+}
 
+func (m *Meta) setLocked(k string, v interface{}) {
+	m.own()
 	if m.entries == nil {
 		m.entries = make(map[string]MetaEntry)
 	}
 
+	size, admitted := m.admit(k, v)
+	if !admitted {
+		return
+	}
+
 	vi, ok := m.entries[k]
 	if ok {
-		m.entries[k] = MetaEntry{v, vi.order}
+		m.entries[k] = MetaEntry{v, vi.order, size}
 	} else {
-		m.entries[k] = MetaEntry{v, len(m.entries)}
+		m.entries[k] = MetaEntry{v, len(m.keys), size}
+		m.keys = append(m.keys, k)
 	}
+	m.size += size - vi.size
 }
 
 func (m *Meta) del(k string) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
+	m.remove(k)
+}
+
+func (m *Meta) remove(k string) {
+	vi, ok := m.entries[k]
+	if !ok {
+		return
+	}
+	m.own()
 	delete(m.entries, k)
+	m.size -= vi.size
+
+	// Close the gap, renumbering the entries after it
+	m.keys = append(m.keys[:vi.order], m.keys[vi.order+1:]...)
+	for i := vi.order; i < len(m.keys); i++ {
+		e := m.entries[m.keys[i]]
+		e.order = i
+		m.entries[m.keys[i]] = e
+	}
 }
 
-func (m *Meta) format(delim, format string) string {
+// Sets each of o's entries, in order
+func (m *Meta) merge(o *Meta) {
+	for _, f := range o.fields() {
+		m.set(f.Key, f.Value)
+	}
+}
+
+// Returns the entries in insertion order
+func (m *Meta) fields() []Field {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
 	if len(m.entries) == 0 {
-		return ""
+		return nil
 	}
 
-	pts := make([]string, len(m.entries))
-	for k, vi := range m.entries {
-		pts[vi.order] = fmt.Sprintf("%s=%+v", k, vi.value)
+	fields := make([]Field, len(m.keys))
+	for i, k := range m.keys {
+		fields[i] = Field{k, m.entries[k].value}
 	}
+	return fields
+}
+
+// Appends the entries to fields, in insertion order
+func (m *Meta) appendFields(fields []Field) []Field {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
 
-	s := strings.Join(pts, delim)
-	if format == "" {
-		return s
+	for _, k := range m.keys {
+		fields = append(fields, Field{k, m.entries[k].value})
 	}
-	return fmt.Sprintf(format, s)
+	return fields
+}
+
+func (m *Meta) format(delim, format string) string {
+	return formatFields(m.fields(), delim, format)
 }
 
 func (m *Meta) copy() *Meta {
@@ -226,5 +598,11 @@ func (m *Meta) copy() *Meta {
 		}
 	}
 
-	return &Meta{entries, sync.RWMutex{}}
+	return &Meta{
+		entries: entries,
+		keys:    append([]string(nil), m.keys...),
+		limit:   m.limit,
+		size:    m.size,
+		trimmed: m.trimmed,
+	}
 }
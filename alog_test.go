@@ -2,7 +2,12 @@ package alog
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	stdlog "log"
+	"os"
+	"runtime"
 	"testing"
 
 	"gopkg.in/check.v1"
@@ -48,6 +53,41 @@ func (s *Suite) TestMeta(c *check.C) {
 	n.set("foo", "bar")
 	c.Assert(m.get("foo"), check.Equals, "baz")
 	c.Assert(n.get("foo"), check.Equals, "bar")
+	n.set("new", 1)
+	c.Assert(m.format(", ", ""), check.Equals, "foo=baz, t=7")
+}
+
+func (s *Suite) TestMetaDel(c *check.C) {
+	m := &Meta{}
+	m.set("a", 1)
+	m.set("b", 2)
+	m.set("c", 3)
+
+	// Deleting leaves no gap
+	m.del("b")
+	c.Assert(m.format(" ", ""), check.Equals, "a=1 c=3")
+	c.Assert(m.entries["c"].order, check.Equals, 1)
+
+	m.set("d", 4)
+	c.Assert(m.format(" ", ""), check.Equals, "a=1 c=3 d=4")
+
+	// Re-setting a deleted key appends it
+	m.del("a")
+	m.set("a", 5)
+	c.Assert(m.format(" ", ""), check.Equals, "c=3 d=4 a=5")
+
+	// Overwrite keeps position
+	m.set("d", 6)
+	c.Assert(m.format(" ", ""), check.Equals, "c=3 d=6 a=5")
+
+	// Missing keys are ignored
+	m.del("missing")
+	m.del("c")
+	m.del("d")
+	m.del("a")
+	c.Assert(m.format(" ", ""), check.Equals, "")
+	m.set("x", 1)
+	c.Assert(m.format(" ", ""), check.Equals, "x=1")
 }
 
 type Thief struct {
@@ -141,6 +181,67 @@ func (s *Suite) TestLog(c *check.C) {
 	c.Assert(t.last(), check.Matches, `\d{4}/\d\d/\d\d \d\d:\d\d:\d\d foo\n`)
 }
 
+func (s *Suite) TestSetErrorValue(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	err := &os.PathError{Op: "open", Path: "x", Err: os.ErrNotExist}
+	log.SetError(err)
+	c.Assert(log.Meta.get("error"), check.Equals, error(err))
+
+	log.SetFormatter(ECSFormatter{})
+	log.Print("failed")
+	c.Assert(t.last(), check.Matches, `.*"error":\{"message":"open x: file does not exist","type":"\*fs.PathError"\}.*\n`)
+
+	log.SetFormatter(JSONFormatter{})
+	log.Print("failed")
+	c.Assert(t.last(), check.Matches, `.*"error":"open x: file does not exist".*\n`)
+
+	log.SetFormatter(nil)
+	log.Print("failed")
+	checkLast(c, t, "[error='open x: file does not exist'] failed")
+
+	// Only the logger's own error is quoted
+	log.Set("req", Group("error", err))
+	log.Print("failed")
+	checkLast(c, t, "[error='open x: file does not exist' req.error=open x: file does not exist] failed")
+	log.SetFormatter(valueTypeFormatter{})
+	log.Print("failed")
+	checkLast(c, t, "*fs.PathError alog.GroupValue")
+}
+
+// Formats entries as their field values' types
+type valueTypeFormatter struct{}
+
+func (valueTypeFormatter) Format(b []byte, e *Entry) []byte {
+	for i, f := range e.Fields {
+		if i > 0 {
+			b = append(b, ' ')
+		}
+		b = fmt.Appendf(b, "%T", f.Value)
+	}
+	return b
+}
+
+func (s *Suite) TestPrintWith(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("app", "api")
+
+	log.PrintWith([]Field{{"n", 1}}, "a", 2)
+	checkLast(c, t, "[app=api n=1] a2")
+	log.PrintfWith([]Field{{"n", 2}}, "%d items", 3)
+	checkLast(c, t, "[app=api n=2] 3 items")
+	log.PrintlnWith(nil, "a", 2)
+	checkLast(c, t, "[app=api] a 2")
+	c.Assert(log.Meta.fields(), check.HasLen, 1)
+
+	log.SetFlags(stdlog.Lshortfile)
+	log.PrintWith([]Field{{"n", 1}}, "x")
+	c.Assert(t.last(), check.Matches, `alog_test\.go:\d+: \[app=api n=1\] x\n`)
+}
+
 func (s *Suite) TestPrintln(c *check.C) {
 	t := &Thief{}
 	log := New(t)
@@ -153,3 +254,133 @@ func (s *Suite) TestPrintln(c *check.C) {
 	log.Println("foo", "bar")
 	checkLast(c, t, "[foo=bar] foo bar")
 }
+
+func (s *Suite) BenchmarkPrintf(c *check.C) {
+	// Wrapped, since entries for io.Discard itself are skipped
+	log := New(struct{ io.Writer }{ioutil.Discard})
+	log.Set("foo", "bar")
+	log.Set("key", 7)
+	for i := 0; i < c.N; i++ {
+		log.Printf("%s %d", "test", i)
+	}
+}
+
+type countingStringer struct{ n int }
+
+func (s *countingStringer) String() string {
+	s.n++
+	return "counted"
+}
+
+func (s *Suite) TestPanicFormatsOnce(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("foo", "bar")
+
+	v := &countingStringer{}
+	c.Assert(func() { log.Panicf("%s", v) }, check.Panics, "counted")
+	checkLast(c, t, "PANIC [foo=bar] counted")
+	c.Assert(v.n, check.Equals, 1)
+
+	c.Assert(func() { log.Panicln(v) }, check.Panics, "counted\n")
+	checkLast(c, t, "PANIC [foo=bar] counted")
+	c.Assert(v.n, check.Equals, 2)
+}
+
+func (s *Suite) TestFatalExitCode(c *check.C) {
+	var codes []int
+	defer func(f func(int)) { osExit = f }(osExit)
+	osExit = func(code int) { codes = append(codes, code) }
+
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Fatal("a")
+	log.SetFatalExitCode(78)
+	log.Copy().Fatalf("%s", "b")
+	log.Must(errors.New("c"))
+	log.At(FatalLevel).Msg("d")
+	c.Assert(codes, check.DeepEquals, []int{1, 78, 78, 78})
+	checkLast(c, t, "FATAL d")
+}
+
+func (s *Suite) TestNop(c *check.C) {
+	log := Nop()
+	log.Set("foo", "bar")
+
+	// Nothing is formatted
+	v := &countingStringer{}
+	log.Printf("%s", v)
+	log.Error(v)
+	log.Info().Any("v", v).Msg("x")
+	c.Assert(v.n, check.Equals, 0)
+	c.Assert(log.Info(), check.IsNil)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		log.Print("x")
+		log.Debugf("%d", 1)
+		log.Info().Str("k", "v").Int("n", 1).Msg("x")
+	})
+	c.Assert(allocs, check.Equals, 0.0)
+
+	// Panic and Fatal keep their effects
+	defer func(f func(int)) { osExit = f }(osExit)
+	var codes []int
+	osExit = func(code int) { codes = append(codes, code) }
+	c.Assert(func() { log.Panic("boom") }, check.Panics, "boom")
+	c.Assert(func() { log.At(PanicLevel).Msg("boom") }, check.Panics, "boom")
+	log.Fatal("bye")
+	c.Assert(codes, check.DeepEquals, []int{1})
+	c.Assert(v.n, check.Equals, 0)
+}
+
+func (s *Suite) TestOutput(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(stdlog.Lshortfile)
+	log.Set("foo", "bar")
+
+	wrapper := func(msg string) {
+		log.Output(2, WarnLevel, msg)
+	}
+	_, _, line, _ := runtime.Caller(0)
+	wrapper("careful")
+	checkLast(c, t, fmt.Sprintf("alog_test.go:%d: WARN [foo=bar] careful", line+1))
+
+	// Disabled levels are skipped; Fatal is only written
+	n := len(t.msgs)
+	c.Assert(log.Output(1, DebugLevel, "hidden"), check.IsNil)
+	c.Assert(t.msgs, check.HasLen, n)
+	log.SetFlags(0)
+	log.Output(1, FatalLevel, "bye")
+	checkLast(c, t, "FATAL [foo=bar] bye")
+}
+
+func (s *Suite) TestMerge(c *check.C) {
+	t := &Thief{}
+	req := New(t)
+	req.SetFlags(0)
+	req.Set("request", 1)
+	req.Set("user", "a")
+
+	job := New(t)
+	job.Set("job", 2)
+	job.Set("user", "b")
+
+	c.Assert(req.Merge(job), check.Equals, req)
+	req.Print("test")
+	checkLast(c, t, "[request=1 user=b job=2] test")
+
+	// Other is unchanged
+	c.Assert(job.Meta.format(" ", ""), check.Equals, "job=2 user=b")
+
+	// Merging with itself is harmless
+	req.Merge(req)
+	req.Print("test")
+	checkLast(c, t, "[request=1 user=b job=2] test")
+
+	var nilLog *Log
+	c.Assert(req.Merge(nilLog), check.Equals, req)
+	c.Assert(nilLog.Merge(req), check.IsNil)
+}
@@ -0,0 +1,275 @@
+package alog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+var ErrClosed = errors.New("alog: writer is closed")
+
+// What an AsyncWriter does with an entry when its queue is full
+type Overflow struct {
+	mode  int
+	level Level
+}
+
+const (
+	overflowBlock = iota
+	overflowDropNewest
+	overflowDropOldest
+	overflowDropBelow
+)
+
+var (
+	// Waits for room in the queue
+	Block = Overflow{mode: overflowBlock}
+	// Discards the entry being written
+	DropNewest = Overflow{mode: overflowDropNewest}
+	// Discards the oldest queued entry to make room
+	DropOldest = Overflow{mode: overflowDropOldest}
+)
+
+// Discards entries below level, and waits for room for the others, so that
+// e.g. errors are kept while debug output is shed
+func DropBelow(level Level) Overflow {
+	return Overflow{mode: overflowDropBelow, level: level}
+}
+
+// Entries are copied into pooled buffers, returned once written or dropped
+type asyncEntry struct {
+	p    *[]byte
+	done chan struct{} // Set for flush markers
+}
+
+func (e asyncEntry) release() {
+	if e.p != nil {
+		putBuf(e.p)
+	}
+}
+
+// Writer that queues entries and writes them from a background goroutine,
+// so callers don't wait on a slow destination.  The queue holds size
+// entries; once it is full, overflow decides what happens.  Close or
+// Shutdown must be called to write out the queue and stop the goroutine.
+type AsyncWriter struct {
+	w        io.Writer
+	queue    chan asyncEntry
+	overflow Overflow
+	dropped  atomic.Uint64
+	pending  atomic.Int64 // Entries queued or being written
+	written  atomic.Uint64
+	closed   bool
+	counts   sync.Mutex // Keeps Shutdown's reads of the counts consistent
+	health   sinkHealth
+	errs     backgroundErrors
+	stopped  chan struct{}
+	abort    chan struct{}
+	mutex    sync.RWMutex
+}
+
+// What became of the entries queued when Shutdown was called
+type ShutdownResult struct {
+	Flushed int
+	Dropped int
+}
+
+func NewAsyncWriter(w io.Writer, size int, overflow Overflow) *AsyncWriter {
+	a := &AsyncWriter{
+		w:        w,
+		queue:    make(chan asyncEntry, size),
+		overflow: overflow,
+		stopped:  make(chan struct{}),
+		abort:    make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+func (a *AsyncWriter) run() {
+	defer close(a.stopped)
+	for e := range a.queue {
+		if e.done != nil {
+			close(e.done)
+			continue
+		}
+		written := false
+		select {
+		case <-a.abort:
+		default:
+			_, err := a.w.Write(*e.p)
+			if a.health.record(err) != nil {
+				a.errs.report(err)
+			}
+			written = true
+		}
+		// Counted as pending until written, for Shutdown
+		a.counts.Lock()
+		if written {
+			a.written.Add(1)
+		} else {
+			a.dropped.Add(1)
+		}
+		a.pending.Add(-1)
+		a.counts.Unlock()
+		e.release()
+	}
+}
+
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	return a.WriteLevel(InfoLevel, p)
+}
+
+func (a *AsyncWriter) WriteLevel(level Level, p []byte) (int, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	if a.closed {
+		return 0, ErrClosed
+	}
+
+	bp := getBuf()
+	*bp = append(*bp, p...)
+	e := asyncEntry{p: bp}
+	select {
+	case a.queue <- e:
+		a.pending.Add(1)
+		return len(p), nil
+	default:
+	}
+
+	switch a.overflow.mode {
+	case overflowDropNewest:
+		a.dropped.Add(1)
+		e.release()
+		return len(p), nil
+	case overflowDropOldest:
+		// Flush markers are never evicted.  Those in the way are set aside
+		// and queued again, ahead of e.
+		var markers []asyncEntry
+		for {
+			select {
+			case old := <-a.queue:
+				if old.done != nil {
+					markers = append(markers, old)
+					continue
+				}
+				a.pending.Add(-1)
+				a.dropped.Add(1)
+				old.release()
+			default:
+			}
+			for _, m := range markers {
+				a.queue <- m
+			}
+			markers = markers[:0]
+			select {
+			case a.queue <- e:
+				a.pending.Add(1)
+				return len(p), nil
+			default:
+			}
+		}
+	case overflowDropBelow:
+		if level < a.overflow.level {
+			a.dropped.Add(1)
+			e.release()
+			return len(p), nil
+		}
+	}
+
+	a.queue <- e
+	a.pending.Add(1)
+	return len(p), nil
+}
+
+// Returns the number of entries discarded because the queue was full
+func (a *AsyncWriter) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+func (a *AsyncWriter) Status() []SinkStatus {
+	s := a.health.status("async")
+	s.Queued = int(a.pending.Load())
+	s.Dropped = a.Dropped()
+	a.mutex.RLock()
+	s.Connected = !a.closed
+	a.mutex.RUnlock()
+	return append([]SinkStatus{s}, writerStatus(a.w)...)
+}
+
+// Calls h with the errors from writing to the destination, which happens
+// after Write has returned.  h runs on a goroutine of its own.
+func (a *AsyncWriter) SetErrorHandler(h func(error)) {
+	a.errs.set(h)
+}
+
+// Waits until the entries queued so far have been written, then flushes
+// the destination
+func (a *AsyncWriter) Flush() error {
+	a.mutex.RLock()
+	if a.closed {
+		a.mutex.RUnlock()
+		return ErrClosed
+	}
+	done := make(chan struct{})
+	a.queue <- asyncEntry{done: done}
+	a.mutex.RUnlock()
+
+	<-done
+	return flushWriter(a.w)
+}
+
+// Writes out the queue, stops the background goroutine and closes the
+// destination
+func (a *AsyncWriter) Close() error {
+	_, err := a.Shutdown(context.Background())
+	return err
+}
+
+// Writes out the queue, as Close does, until ctx is done.  Then the rest of
+// the queue is discarded and ctx's error is returned.  The goroutine stops
+// as soon as any write in progress returns, and then closes the
+// destination without flushing it.  The result counts the entries queued
+// at the call that were written and discarded; one being written as ctx
+// expires is counted as discarded.
+func (a *AsyncWriter) Shutdown(ctx context.Context) (ShutdownResult, error) {
+	a.mutex.Lock()
+	if a.closed {
+		a.mutex.Unlock()
+		return ShutdownResult{}, ErrClosed
+	}
+	a.closed = true
+	a.counts.Lock()
+	queued := int(a.pending.Load())
+	written := a.written.Load()
+	a.counts.Unlock()
+	close(a.queue)
+	a.mutex.Unlock()
+
+	var err error
+	select {
+	case <-a.stopped:
+		err = flushWriter(a.w)
+		if cerr := closeWriter(a.w); err == nil {
+			err = cerr
+		}
+		a.errs.close()
+	case <-ctx.Done():
+		close(a.abort)
+		err = ctx.Err()
+		// Closing the destination under a write in progress would race
+		// with it
+		go func() {
+			<-a.stopped
+			closeWriter(a.w)
+			a.errs.close()
+		}()
+	}
+
+	a.counts.Lock()
+	flushed := int(a.written.Load() - written)
+	a.counts.Unlock()
+	return ShutdownResult{Flushed: flushed, Dropped: queued - flushed}, err
+}
@@ -0,0 +1,214 @@
+package alog
+
+import (
+	"context"
+	"io/ioutil"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+// Writer that blocks until released
+type gatedWriter struct {
+	Thief
+	gate chan struct{}
+	// Receives once a write is waiting at the gate
+	entered chan struct{}
+	mutex   sync.Mutex
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{gate: make(chan struct{}), entered: make(chan struct{}, 1)}
+}
+
+func (g *gatedWriter) Write(p []byte) (int, error) {
+	select {
+	case g.entered <- struct{}{}:
+	default:
+	}
+	<-g.gate
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.Thief.Write(p)
+}
+
+func (g *gatedWriter) entries() []string {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return append([]string(nil), g.msgs...)
+}
+
+func (s *Suite) TestAsyncWriter(c *check.C) {
+	g := newGatedWriter()
+	close(g.gate)
+	a := NewAsyncWriter(g, 10, Block)
+	log := New(a)
+	log.SetFlags(0)
+
+	log.Print("one")
+	log.Error("two")
+	c.Assert(log.Flush(), check.IsNil)
+	c.Assert(g.entries(), check.DeepEquals, []string{"one\n", "ERROR two\n"})
+
+	log.Print("three")
+	c.Assert(log.Close(), check.IsNil)
+	c.Assert(g.entries(), check.HasLen, 3)
+
+	_, err := a.Write([]byte("x"))
+	c.Assert(err, check.Equals, ErrClosed)
+	c.Assert(a.Flush(), check.Equals, ErrClosed)
+	c.Assert(a.Close(), check.Equals, ErrClosed)
+}
+
+// Fills a queue of size 2 behind a blocked write, then writes more.
+// Returns the entries eventually written and the number dropped.
+func asyncOverflow(c *check.C, overflow Overflow) ([]string, uint64) {
+	g := newGatedWriter()
+	a := NewAsyncWriter(g, 2, overflow)
+	log := New(a)
+	log.SetFlags(0)
+	log.SetLevel(DebugLevel)
+
+	// Wait until the writer goroutine holds "first"
+	log.Print("first")
+	<-g.entered
+
+	log.Debug("a")
+	log.Debug("b")
+	log.Debug("c")
+	done := make(chan struct{})
+	go func() {
+		log.Error("d")
+		close(done)
+	}()
+
+	// Only the dropping policies return without room in the queue
+	if overflow == DropNewest || overflow == DropOldest {
+		<-done
+	}
+	close(g.gate)
+	<-done
+	c.Assert(a.Close(), check.IsNil)
+	return g.entries(), a.Dropped()
+}
+
+func (s *Suite) TestAsyncOverflow(c *check.C) {
+	entries, dropped := asyncOverflow(c, DropNewest)
+	c.Assert(entries, check.DeepEquals, []string{"first\n", "DEBUG a\n", "DEBUG b\n"})
+	c.Assert(dropped, check.Equals, uint64(2))
+
+	entries, dropped = asyncOverflow(c, DropOldest)
+	c.Assert(entries, check.DeepEquals, []string{"first\n", "DEBUG c\n", "ERROR d\n"})
+	c.Assert(dropped, check.Equals, uint64(2))
+
+	entries, dropped = asyncOverflow(c, DropBelow(ErrorLevel))
+	c.Assert(entries, check.DeepEquals, []string{"first\n", "DEBUG a\n", "DEBUG b\n", "ERROR d\n"})
+	c.Assert(dropped, check.Equals, uint64(1))
+}
+
+func (s *Suite) TestAsyncDropOldestFlush(c *check.C) {
+	g := newGatedWriter()
+	a := NewAsyncWriter(g, 2, DropOldest)
+	a.Write([]byte("first\n"))
+	<-g.entered
+
+	a.Write([]byte("a\n"))
+	flushed := make(chan error)
+	go func() { flushed <- a.Flush() }()
+	for len(a.queue) < 2 {
+		runtime.Gosched()
+	}
+	// Each write evicts the entry behind the flush marker, not the marker
+	a.Write([]byte("b\n"))
+	a.Write([]byte("c\n"))
+	select {
+	case <-flushed:
+		c.Fatal("flushed before the first entry was written")
+	default:
+	}
+
+	close(g.gate)
+	c.Assert(<-flushed, check.IsNil)
+	c.Assert(a.Close(), check.IsNil)
+	c.Assert(g.entries(), check.DeepEquals, []string{"first\n", "c\n"})
+	c.Assert(a.Dropped(), check.Equals, uint64(2))
+}
+
+func (s *Suite) TestAsyncErrorHandler(c *check.C) {
+	a := NewAsyncWriter(&flakyWriter{failures: 1}, 10, Block)
+	log := New(a)
+	errs := make(chan error, 1)
+	log.SetErrorHandler(func(err error) { errs <- err })
+	log.Print("a")
+	c.Assert(<-errs, check.ErrorMatches, "flaky")
+	c.Assert(log.Close(), check.IsNil)
+}
+
+func (s *Suite) TestAsyncShutdown(c *check.C) {
+	t := &Thief{}
+	a := NewAsyncWriter(t, 10, Block)
+	a.Write([]byte("a\n"))
+	a.Write([]byte("b\n"))
+	res, err := a.Shutdown(context.Background())
+	c.Assert(err, check.IsNil)
+	c.Assert(res, check.Equals, ShutdownResult{Flushed: 2})
+	c.Assert(t.msgs, check.HasLen, 2)
+	_, err = a.Shutdown(context.Background())
+	c.Assert(err, check.Equals, ErrClosed)
+
+	// Gives up at the deadline
+	g := &gatedCloser{gatedWriter: newGatedWriter(), closed: make(chan struct{})}
+	a = NewAsyncWriter(g, 10, Block)
+	for i := 0; i < 3; i++ {
+		a.Write([]byte("x\n"))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	res, err = a.Shutdown(ctx)
+	c.Assert(err, check.Equals, context.DeadlineExceeded)
+	c.Assert(res, check.Equals, ShutdownResult{Dropped: 3})
+
+	// The rest of the queue is discarded once the stuck write returns, and
+	// only then is the destination closed
+	close(g.gate)
+	<-a.stopped
+	<-g.closed
+	c.Assert(g.closedWriting, check.Equals, false)
+	c.Assert(len(g.entries()) <= 1, check.Equals, true)
+	c.Assert(a.Dropped() >= 2, check.Equals, true)
+}
+
+// gatedWriter that records whether it is closed during a write
+type gatedCloser struct {
+	*gatedWriter
+	writing       int32
+	closedWriting bool
+	closed        chan struct{}
+}
+
+func (g *gatedCloser) Write(p []byte) (int, error) {
+	atomic.StoreInt32(&g.writing, 1)
+	defer atomic.StoreInt32(&g.writing, 0)
+	return g.gatedWriter.Write(p)
+}
+
+func (g *gatedCloser) Close() error {
+	g.closedWriting = atomic.LoadInt32(&g.writing) == 1
+	close(g.closed)
+	return nil
+}
+
+func BenchmarkAsyncWriter(b *testing.B) {
+	a := NewAsyncWriter(ioutil.Discard, 1024, Block)
+	defer a.Close()
+	log := New(a)
+	log.Set("app", "api")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		log.Print("done")
+	}
+}
@@ -0,0 +1,123 @@
+package alog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+const auditSep = " hmac="
+
+// Writer that makes a log tamper-evident.  Each line is suffixed with an
+// HMAC-SHA256 over the previous line's HMAC and the line itself, so editing,
+// removing or reordering lines breaks the chain.  Use with New:
+//
+//	log := alog.New(alog.NewAuditWriter(f, key))
+type AuditWriter struct {
+	w     io.Writer
+	key   []byte
+	prev  []byte
+	mutex sync.Mutex
+}
+
+func NewAuditWriter(w io.Writer, key []byte) *AuditWriter {
+	return &AuditWriter{w: w, key: key}
+}
+
+// Returns an AuditWriter that continues the chain ending at head, as
+// returned by Head or VerifyAudit, e.g. when a restarted process appends
+// to its audit log:
+//
+//	head, err := alog.VerifyAudit(f, key)
+//	if err != nil {
+//		return err
+//	}
+//	w, err := alog.ResumeAuditWriter(f, key, head)
+func ResumeAuditWriter(w io.Writer, key []byte, head string) (*AuditWriter, error) {
+	prev, err := hex.DecodeString(head)
+	if err != nil {
+		return nil, fmt.Errorf("alog: malformed audit head %q", head)
+	}
+	return &AuditWriter{w: w, key: key, prev: prev}, nil
+}
+
+func (a *AuditWriter) Write(p []byte) (int, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	var buf bytes.Buffer
+	prev := a.prev
+	for _, line := range bytes.SplitAfter(p, []byte("\n")) {
+		line = bytes.TrimSuffix(line, []byte("\n"))
+		if len(line) == 0 {
+			continue
+		}
+		prev = auditMAC(a.key, prev, line)
+		buf.Write(line)
+		buf.WriteString(auditSep)
+		buf.WriteString(hex.EncodeToString(prev))
+		buf.WriteByte('\n')
+	}
+
+	if _, err := a.w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	a.prev = prev
+	return len(p), nil
+}
+
+// Returns the HMAC of the last line written, hex encoded.  Record it
+// somewhere safe; VerifyAudit's result must match it, otherwise the log
+// was truncated.
+func (a *AuditWriter) Head() string {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return hex.EncodeToString(a.prev)
+}
+
+// Checks the HMAC chain of a log written by an AuditWriter and returns the
+// head HMAC.  Returns an error naming the first line that fails to verify.
+func VerifyAudit(r io.Reader, key []byte) (string, error) {
+	br := bufio.NewReader(r)
+	var prev []byte
+	for n := 1; ; n++ {
+		line, err := br.ReadString('\n')
+		if err == io.EOF && line == "" {
+			return hex.EncodeToString(prev), nil
+		} else if err != nil && err != io.EOF {
+			return "", err
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+		i := strings.LastIndex(line, auditSep)
+		if i < 0 {
+			return "", fmt.Errorf("alog: audit line %d has no hmac", n)
+		}
+		mac, herr := hex.DecodeString(line[i+len(auditSep):])
+		if herr != nil {
+			return "", fmt.Errorf("alog: audit line %d has a malformed hmac", n)
+		}
+
+		prev = auditMAC(key, prev, []byte(line[:i]))
+		if !hmac.Equal(mac, prev) {
+			return "", fmt.Errorf("alog: audit line %d failed verification", n)
+		}
+
+		if err == io.EOF {
+			return hex.EncodeToString(prev), nil
+		}
+	}
+}
+
+func auditMAC(key, prev, line []byte) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write(prev)
+	m.Write(line)
+	return m.Sum(nil)
+}
@@ -0,0 +1,82 @@
+package alog
+
+import (
+	"bytes"
+	"strings"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestAudit(c *check.C) {
+	key := []byte("secret")
+	var buf bytes.Buffer
+	w := NewAuditWriter(&buf, key)
+	log := New(w)
+	log.SetFlags(0)
+
+	log.Set("foo", "bar")
+	log.Print("one")
+	log.Print("two")
+	log.Print("three\nfour")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	c.Assert(lines, check.HasLen, 4)
+	c.Assert(lines[0], check.Matches, `\[foo=bar\] one hmac=[0-9a-f]{64}`)
+
+	head, err := VerifyAudit(strings.NewReader(buf.String()), key)
+	c.Assert(err, check.IsNil)
+	c.Assert(head, check.Equals, w.Head())
+
+	// Wrong key
+	_, err = VerifyAudit(strings.NewReader(buf.String()), []byte("other"))
+	c.Assert(err, check.ErrorMatches, "alog: audit line 1 failed verification")
+
+	// Modified line
+	tampered := strings.Replace(buf.String(), "two", "TWO", 1)
+	_, err = VerifyAudit(strings.NewReader(tampered), key)
+	c.Assert(err, check.ErrorMatches, "alog: audit line 2 failed verification")
+
+	// Removed line
+	removed := strings.Join(append(lines[:1:1], lines[2:]...), "\n") + "\n"
+	_, err = VerifyAudit(strings.NewReader(removed), key)
+	c.Assert(err, check.ErrorMatches, "alog: audit line 2 failed verification")
+
+	// Truncation verifies, but the head no longer matches
+	truncated := strings.Join(lines[:3], "\n") + "\n"
+	head, err = VerifyAudit(strings.NewReader(truncated), key)
+	c.Assert(err, check.IsNil)
+	c.Assert(head, check.Not(check.Equals), w.Head())
+
+	// Missing hmac
+	_, err = VerifyAudit(strings.NewReader("foo\n"), key)
+	c.Assert(err, check.ErrorMatches, "alog: audit line 1 has no hmac")
+}
+
+func (s *Suite) TestAuditResume(c *check.C) {
+	key := []byte("secret")
+	var buf bytes.Buffer
+	log := New(NewAuditWriter(&buf, key))
+	log.SetFlags(0)
+	log.Print("before restart")
+
+	head, err := VerifyAudit(bytes.NewReader(buf.Bytes()), key)
+	c.Assert(err, check.IsNil)
+	w, err := ResumeAuditWriter(&buf, key, head)
+	c.Assert(err, check.IsNil)
+	c.Assert(w.Head(), check.Equals, head)
+	log = New(w)
+	log.SetFlags(0)
+	log.Print("after restart")
+
+	head, err = VerifyAudit(bytes.NewReader(buf.Bytes()), key)
+	c.Assert(err, check.IsNil)
+	c.Assert(head, check.Equals, w.Head())
+
+	// Resuming an empty log starts a new chain
+	w, err = ResumeAuditWriter(&buf, key, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(w.Head(), check.Equals, "")
+
+	_, err = ResumeAuditWriter(&buf, key, "zz")
+	c.Assert(err, check.ErrorMatches, `alog: malformed audit head "zz"`)
+}
@@ -0,0 +1,312 @@
+package alog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Limits for a BatchWriter.  A batch is sent when it reaches MaxEntries or
+// MaxBytes, whichever comes first, and at least every MaxDelay.  Zero
+// limits are unlimited.
+type BatchConfig struct {
+	MaxEntries int
+	MaxBytes   int
+	// 1s by default.  Negative for only when full and on Flush.
+	MaxDelay time.Duration
+}
+
+// Writer that collects entries and passes them on in batches, for sinks
+// that are cheaper to write to in bulk.  Close must be called to send the
+// last batch.  Batches are sent in the background; if sending one fails, it
+// is dropped and the error is returned from the next Flush or Close.
+type BatchWriter struct {
+	w     io.Writer
+	batch *batcher
+}
+
+// Writes each batch to w in one Write call, the entries one after another
+func NewBatchWriter(w io.Writer, cfg BatchConfig) *BatchWriter {
+	bw := NewBatchFunc(func(entries [][]byte) error {
+		_, err := w.Write(bytes.Join(entries, nil))
+		return err
+	}, cfg)
+	bw.w = w
+	return bw
+}
+
+// Calls send with each batch.  send must not retain the entries.
+func NewBatchFunc(send func(entries [][]byte) error, cfg BatchConfig) *BatchWriter {
+	if cfg.MaxDelay == 0 {
+		cfg.MaxDelay = time.Second
+	}
+	return &BatchWriter{batch: newBatcher(send, cfg.MaxEntries, cfg.MaxBytes, cfg.MaxDelay)}
+}
+
+func (w *BatchWriter) Write(p []byte) (int, error) {
+	return len(p), w.batch.add(append([]byte(nil), p...))
+}
+
+// Sends the current batch, then flushes the destination writer
+func (w *BatchWriter) Flush() error {
+	err := w.batch.flush()
+	if w.w != nil {
+		if ferr := flushWriter(w.w); err == nil {
+			err = ferr
+		}
+	}
+	return err
+}
+
+// Calls h with the errors from sending batches in the background.  h runs
+// on a goroutine of its own.
+func (w *BatchWriter) SetErrorHandler(h func(error)) {
+	w.batch.errs.set(h)
+}
+
+func (w *BatchWriter) Status() []SinkStatus {
+	s := []SinkStatus{w.batch.status("batch")}
+	if w.w != nil {
+		s = append(s, writerStatus(w.w)...)
+	}
+	return s
+}
+
+// Sends the last batch, stops the timer and closes the destination writer
+func (w *BatchWriter) Close() error {
+	err := w.batch.close()
+	if err == ErrClosed || w.w == nil {
+		return err
+	}
+	if ferr := flushWriter(w.w); err == nil {
+		err = ferr
+	}
+	if cerr := closeWriter(w.w); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Collects encoded items and sends them in groups, when the batch reaches
+// maxItems or maxBytes, when interval elapses, and on flush.  Each item
+// counts as its length plus overhead towards maxBytes.  Batches are sent in
+// order from a background goroutine, so writers don't wait on the network;
+// up to batchQueueSize batches wait to be sent, and once that many are
+// waiting, further full batches are dropped.  Flush and close wait for the
+// batches before them to be sent.  Once closed, add fails with ErrClosed.
+type batcher struct {
+	send     func(items [][]byte) error
+	maxItems int
+	maxBytes int
+	overhead int
+
+	items  [][]byte
+	size   int
+	closed bool
+	mutex  sync.Mutex
+
+	queue   chan pendingBatch
+	sent    chan struct{}
+	queued  atomic.Int64
+	dropped atomic.Uint64
+	health  sinkHealth
+	errs    backgroundErrors
+	// The first failure since the last flush, for flush to return
+	err      error
+	errMutex sync.Mutex
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// Batches waiting to be sent, per batcher
+const batchQueueSize = 4
+
+var errBatchQueueFull = errors.New("alog: too many batches waiting to be sent; batch dropped")
+
+type pendingBatch struct {
+	items [][]byte
+	// Set by flush and close, which wait for the result
+	done chan error
+}
+
+func newBatcher(send func([][]byte) error, maxItems, maxBytes int, interval time.Duration) *batcher {
+	b := &batcher{
+		send:     send,
+		maxItems: maxItems,
+		maxBytes: maxBytes,
+		queue:    make(chan pendingBatch, batchQueueSize),
+		sent:     make(chan struct{}),
+	}
+	go b.sendLoop()
+	if interval > 0 {
+		b.stop = make(chan struct{})
+		b.stopped = make(chan struct{})
+		go b.run(interval)
+	}
+	return b
+}
+
+func (b *batcher) run(interval time.Duration) {
+	defer close(b.stopped)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			b.mutex.Lock()
+			err := b.enqueueLocked()
+			b.mutex.Unlock()
+			if err != nil {
+				b.errs.report(err)
+			}
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+func (b *batcher) sendLoop() {
+	defer close(b.sent)
+	for p := range b.queue {
+		var err error
+		if len(p.items) > 0 {
+			err = b.health.record(b.send(p.items))
+		}
+		b.queued.Add(-int64(len(p.items)))
+		if err != nil {
+			b.dropped.Add(uint64(len(p.items)))
+		}
+		if p.done != nil {
+			p.done <- err
+		} else if err != nil {
+			b.fail(err)
+			b.errs.report(err)
+		}
+	}
+}
+
+// Records err for the next flush to return
+func (b *batcher) fail(err error) {
+	b.errMutex.Lock()
+	defer b.errMutex.Unlock()
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Adds item, queueing the batch first if item would not fit
+func (b *batcher) add(item []byte) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+
+	var err error
+	n := len(item) + b.overhead
+	if len(b.items) > 0 && b.maxBytes > 0 && b.size+n > b.maxBytes {
+		err = b.enqueueLocked()
+	}
+	b.items = append(b.items, item)
+	b.size += n
+	if b.maxItems > 0 && len(b.items) >= b.maxItems {
+		if qerr := b.enqueueLocked(); err == nil {
+			err = qerr
+		}
+	}
+	return err
+}
+
+// Takes the current batch, if any, leaving an empty one
+func (b *batcher) takeLocked() [][]byte {
+	items := b.items
+	b.items = nil
+	b.size = 0
+	if len(items) > 0 {
+		b.queued.Add(int64(len(items)))
+	}
+	return items
+}
+
+// Hands the current batch to the sender without waiting.  The batch is
+// dropped if the queue is full, so a slow destination can't block writers.
+func (b *batcher) enqueueLocked() error {
+	items := b.takeLocked()
+	if len(items) == 0 {
+		return nil
+	}
+	select {
+	case b.queue <- pendingBatch{items: items}:
+		return nil
+	default:
+		b.queued.Add(-int64(len(items)))
+		b.dropped.Add(uint64(len(items)))
+		return b.health.record(errBatchQueueFull)
+	}
+}
+
+// Sends the current batch after those queued before it, and waits for it.
+// Returns the batch's error, or else the first error from a batch sent in
+// the background since the last flush.  A batch is dropped even if sending
+// it fails, so one bad batch can't block all later ones.
+func (b *batcher) flush() error {
+	b.mutex.Lock()
+	if b.closed {
+		b.mutex.Unlock()
+		return nil
+	}
+	done := make(chan error, 1)
+	// The sender never takes the lock, so this can't deadlock
+	b.queue <- pendingBatch{items: b.takeLocked(), done: done}
+	b.mutex.Unlock()
+	return b.result(<-done)
+}
+
+func (b *batcher) result(err error) error {
+	b.errMutex.Lock()
+	defer b.errMutex.Unlock()
+	if err == nil {
+		err = b.err
+	}
+	b.err = nil
+	return err
+}
+
+func (b *batcher) status(name string) SinkStatus {
+	s := b.health.status(name)
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	s.Queued = len(b.items) + int(b.queued.Load())
+	s.Dropped = b.dropped.Load()
+	return s
+}
+
+// Stops the timer, sends what remains and stops the sender
+func (b *batcher) close() error {
+	b.mutex.Lock()
+	closed := b.closed
+	b.closed = true
+	b.mutex.Unlock()
+	if closed {
+		return ErrClosed
+	}
+
+	if b.stop != nil {
+		close(b.stop)
+		<-b.stopped
+	}
+
+	b.mutex.Lock()
+	done := make(chan error, 1)
+	b.queue <- pendingBatch{items: b.takeLocked(), done: done}
+	close(b.queue)
+	b.mutex.Unlock()
+	err := b.result(<-done)
+	<-b.sent
+	b.errs.close()
+	return err
+}
@@ -0,0 +1,111 @@
+package alog
+
+import (
+	"errors"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestBatcher(c *check.C) {
+	var sent [][]string
+	send := func(items [][]byte) error {
+		var batch []string
+		for _, i := range items {
+			batch = append(batch, string(i))
+		}
+		sent = append(sent, batch)
+		return nil
+	}
+
+	// Sends before exceeding maxBytes, and at maxItems
+	b := newBatcher(send, 3, 5, 0)
+	for _, s := range []string{"ab", "cd", "ef", "g", "h", "i"} {
+		c.Assert(b.add([]byte(s)), check.IsNil)
+	}
+	c.Assert(b.close(), check.IsNil)
+	c.Assert(sent, check.DeepEquals, [][]string{{"ab", "cd"}, {"ef", "g", "h"}, {"i"}})
+	c.Assert(b.flush(), check.IsNil)
+	c.Assert(sent, check.HasLen, 3)
+
+	// Full batches are sent in the background, and dropped once too many
+	// are waiting
+	gate := make(chan struct{})
+	b = newBatcher(func(items [][]byte) error {
+		<-gate
+		return nil
+	}, 1, 0, 0)
+	var n int
+	var err error
+	for ; n < 2*batchQueueSize && err == nil; n++ {
+		err = b.add([]byte("x"))
+	}
+	c.Assert(err, check.Equals, errBatchQueueFull)
+	c.Assert(n > batchQueueSize, check.Equals, true)
+	close(gate)
+	c.Assert(b.close(), check.IsNil)
+	c.Assert(b.status("b").Dropped, check.Equals, uint64(1))
+
+	// Timer
+	done := make(chan struct{}, 1)
+	b = newBatcher(func(items [][]byte) error {
+		done <- struct{}{}
+		return errors.New("dropped")
+	}, 0, 0, time.Millisecond)
+	b.add([]byte("x"))
+	<-done
+	c.Assert(b.close(), check.ErrorMatches, "dropped")
+}
+
+func (s *Suite) TestBatchWriter(c *check.C) {
+	t := &Thief{}
+	w := NewBatchWriter(t, BatchConfig{MaxEntries: 2, MaxDelay: -1})
+	log := New(w)
+	log.SetFlags(0)
+
+	log.Print("a")
+	c.Assert(t.msgs, check.HasLen, 0)
+	log.Print("b")
+	log.Print("c")
+	c.Assert(log.Flush(), check.IsNil)
+	c.Assert(t.msgs, check.DeepEquals, []string{"a\nb\n", "c\n"})
+
+	log.Print("d")
+	c.Assert(log.Close(), check.IsNil)
+	c.Assert(t.last(), check.Equals, "d\n")
+	c.Assert(w.Close(), check.Equals, ErrClosed)
+	_, err := w.Write([]byte("e\n"))
+	c.Assert(err, check.Equals, ErrClosed)
+
+	// Send errors are returned by the next Flush
+	fail := errors.New("fail")
+	w = NewBatchFunc(func(entries [][]byte) error {
+		if string(entries[0]) == "abc\n" {
+			return fail
+		}
+		return nil
+	}, BatchConfig{MaxBytes: 4})
+	_, err = w.Write([]byte("abc\n"))
+	c.Assert(err, check.IsNil)
+	_, err = w.Write([]byte("def\n"))
+	c.Assert(err, check.IsNil)
+	c.Assert(w.Flush(), check.Equals, fail)
+	c.Assert(w.Close(), check.IsNil)
+
+	// And passed to the logger's error handler as they happen
+	w = NewBatchFunc(func(entries [][]byte) error { return fail }, BatchConfig{MaxEntries: 1})
+	errs := make(chan error, 1)
+	New(w).SetErrorHandler(func(err error) { errs <- err }).Print("x")
+	c.Assert(<-errs, check.Equals, fail)
+	c.Assert(w.Close(), check.Equals, fail)
+
+	// Timer
+	sent := make(chan [][]byte, 1)
+	w = NewBatchFunc(func(entries [][]byte) error {
+		sent <- entries
+		return nil
+	}, BatchConfig{MaxDelay: time.Millisecond})
+	w.Write([]byte("x\n"))
+	c.Assert(<-sent, check.DeepEquals, [][]byte{[]byte("x\n")})
+	c.Assert(w.Close(), check.IsNil)
+}
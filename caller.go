@@ -0,0 +1,164 @@
+package alog
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// How caller paths are shortened under Llongfile.  Replaced, never
+// modified, so copies can share it.
+type pathTrim struct {
+	prefixes []string
+	module   bool
+}
+
+// Removes the longest of prefixes from caller paths reported under
+// Llongfile, e.g. the build directory.  Lshortfile still reports the bare
+// file name.  Copies keep the setting.
+func (a *Log) SetTrimPrefixes(prefixes ...string) *Log {
+	if a == nil {
+		return nil
+	}
+	t := pathTrim{prefixes: append([]string(nil), prefixes...)}
+	if a.paths != nil {
+		t.module = a.paths.module
+	}
+	a.paths = &t
+	return a
+}
+
+// Reports caller paths under Llongfile relative to their module: files in
+// the main module relative to its root, dependencies in the module cache as
+// module path and file, and the standard library relative to GOROOT/src.
+//
+//	/home/ci/app/internal/db/db.go                         -> internal/db/db.go
+//	/home/ci/go/pkg/mod/github.com/a/b@v1.2.0/c.go         -> github.com/a/b/c.go
+//	/usr/local/go/src/net/http/server.go                   -> net/http/server.go
+//
+// The main module's root is found by looking for its go.mod, so paths are
+// only trimmed on a host with the source; build with -trimpath elsewhere.
+// Prefixes from SetTrimPrefixes are tried first.  Copies keep the setting.
+func (a *Log) SetModulePaths(enabled bool) *Log {
+	if a == nil {
+		return nil
+	}
+	t := pathTrim{module: enabled}
+	if a.paths != nil {
+		t.prefixes = a.paths.prefixes
+	}
+	a.paths = &t
+	return a
+}
+
+// Reports the caller, under Lshortfile or Llongfile, and starts stacks
+// from SetStackLevel, at the first frame outside pkgs, so that helper
+// packages wrapping the logger aren't blamed for their callers' entries.
+// A package is skipped along with the packages under it, e.g.
+// "example.com/app/logutil".  Copies keep the setting.
+func (a *Log) SetSkipPackages(pkgs ...string) *Log {
+	if a == nil {
+		return nil
+	}
+	a.skipPkgs = append([]string(nil), pkgs...)
+	return a
+}
+
+// Returns calldepth, counted as for write, moved past frames in skipped
+// packages
+func (a *Log) skipFrames(calldepth int) int {
+	var pcs [32]uintptr
+	// Skip runtime.Callers and this function
+	n := runtime.Callers(calldepth+2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		if !more || !a.skipped(funcPackage(f.Function)) {
+			return calldepth
+		}
+		calldepth++
+	}
+}
+
+func (a *Log) skipped(pkg string) bool {
+	for _, p := range a.skipPkgs {
+		if pkg == p || strings.HasPrefix(pkg, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the package path of a function name such as
+// "example.com/app/db.(*Conn).Query"
+func funcPackage(fn string) string {
+	slash := strings.LastIndexByte(fn, '/') + 1
+	if dot := strings.IndexByte(fn[slash:], '.'); dot >= 0 {
+		return fn[:slash+dot]
+	}
+	return fn
+}
+
+func (t *pathTrim) trim(file string) string {
+	if t == nil {
+		return file
+	}
+	best := ""
+	for _, p := range t.prefixes {
+		if len(p) > len(best) && strings.HasPrefix(file, p) {
+			best = p
+		}
+	}
+	if best != "" {
+		return strings.TrimLeft(file[len(best):], "/")
+	}
+	if t.module {
+		return modulePath(file)
+	}
+	return file
+}
+
+func modulePath(file string) string {
+	if i := strings.Index(file, "/pkg/mod/"); i >= 0 {
+		rel := file[i+len("/pkg/mod/"):]
+		// Drop the version from module@version/
+		if at := strings.IndexByte(rel, '@'); at >= 0 {
+			if slash := strings.IndexByte(rel[at:], '/'); slash >= 0 {
+				rel = rel[:at] + rel[at+slash:]
+			}
+		}
+		return rel
+	}
+	if src := runtime.GOROOT() + "/src/"; strings.HasPrefix(file, src) {
+		return file[len(src):]
+	}
+	if root := moduleRoot(filepath.Dir(file)); root != "" {
+		return strings.TrimPrefix(file, root+"/")
+	}
+	return file
+}
+
+// Module root by source directory, or "" if there is no go.mod above it
+var moduleRoots sync.Map
+
+func moduleRoot(dir string) string {
+	if root, ok := moduleRoots.Load(dir); ok {
+		return root.(string)
+	}
+	root := ""
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			root = filepath.ToSlash(d)
+			break
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			break
+		}
+		d = parent
+	}
+	moduleRoots.Store(dir, root)
+	return root
+}
@@ -0,0 +1,84 @@
+package alog
+
+import (
+	"io/ioutil"
+	stdlog "log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestTrimPrefixes(c *check.C) {
+	_, file, _, _ := runtime.Caller(0)
+	dir := filepath.Dir(file)
+
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(stdlog.Llongfile)
+	log.SetTrimPrefixes("/nowhere", filepath.Dir(dir), dir)
+	log.Print("a")
+	c.Assert(t.last(), check.Matches, `caller_test\.go:\d+: a\n`)
+
+	// Copies keep it; Lshortfile is unaffected
+	log.Copy().SetFlags(stdlog.Lshortfile | stdlog.Llongfile)
+	log.Print("b")
+	c.Assert(t.last(), check.Matches, `caller_test\.go:\d+: b\n`)
+
+	log.SetTrimPrefixes()
+	log.Print("c")
+	c.Assert(t.last(), check.Matches, `/.*/caller_test\.go:\d+: c\n`)
+}
+
+func (s *Suite) TestModulePaths(c *check.C) {
+	c.Assert(modulePath("/home/ci/go/pkg/mod/github.com/a/b@v1.2.0/c/d.go"), check.Equals, "github.com/a/b/c/d.go")
+	c.Assert(modulePath(runtime.GOROOT()+"/src/net/http/server.go"), check.Equals, "net/http/server.go")
+
+	root := c.MkDir()
+	c.Assert(os.MkdirAll(filepath.Join(root, "internal", "db"), 0755), check.IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(root, "go.mod"), []byte("module x\n"), 0644), check.IsNil)
+	file := filepath.ToSlash(filepath.Join(root, "internal", "db", "db.go"))
+	c.Assert(modulePath(file), check.Equals, "internal/db/db.go")
+
+	// No go.mod above
+	c.Assert(modulePath("/nonexistent/a/b.go"), check.Equals, "/nonexistent/a/b.go")
+
+	// Prefixes are tried first
+	p := &pathTrim{prefixes: []string{filepath.ToSlash(root) + "/internal"}, module: true}
+	c.Assert(p.trim(file), check.Equals, "db/db.go")
+	var nilTrim *pathTrim
+	c.Assert(nilTrim.trim(file), check.Equals, file)
+
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(stdlog.Llongfile)
+	log.SetModulePaths(true)
+	log.Print("a")
+	c.Assert(t.last(), check.Matches, `.*caller_test\.go:\d+: a\n`)
+}
+
+func (s *Suite) TestSkipPackages(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(stdlog.Lshortfile)
+
+	// reflect stands in for a wrapper package
+	logPrint := reflect.ValueOf(log.Print)
+	logPrint.Call([]reflect.Value{reflect.ValueOf("a")})
+	c.Assert(t.last(), check.Matches, `value\.go:\d+: a\n`)
+
+	log.SetSkipPackages("example.com/none", "reflect")
+	logPrint.Call([]reflect.Value{reflect.ValueOf("b")})
+	c.Assert(t.last(), check.Matches, `caller_test\.go:\d+: b\n`)
+
+	// Stacks start at the same frame
+	log.SetFlags(0)
+	log.SetStackLevel(InfoLevel)
+	logPrint.Call([]reflect.Value{reflect.ValueOf("c")})
+	c.Assert(t.last(), check.Matches, `(?s)c\ngithub\.com/xsleonard/alog\.\(\*Suite\)\.TestSkipPackages\n.*`)
+
+	c.Assert(funcPackage("example.com/app/db.(*Conn).Query"), check.Equals, "example.com/app/db")
+	c.Assert(funcPackage("main.main"), check.Equals, "main")
+}
@@ -0,0 +1,149 @@
+package alog
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Formats entries in ArcSight's Common Event Format:
+//
+//	CEF:0|Acme|API|1.2|warn|login failed|5|rt=1577934245000 suser=alice src=10.0.0.1
+//
+// The signature id is the level, unless the SignatureKey field is set, and
+// the name is the message.  Keys maps field keys to CEF extension keys, such
+// as "user": "suser"; other fields keep their own keys, with characters
+// other than letters, digits and underscores removed.
+type CEFFormatter struct {
+	Vendor       string
+	Product      string
+	Version      string
+	SignatureKey string
+	Keys         map[string]string
+}
+
+// Severity on CEF and LEEF's scale of 0 to 10
+var siemSeverities = map[Level]int{
+	DebugLevel: 1,
+	InfoLevel:  3,
+	WarnLevel:  5,
+	ErrorLevel: 7,
+	PanicLevel: 9,
+	FatalLevel: 10,
+}
+
+var (
+	cefHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefValueEscaper  = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+)
+
+func (f CEFFormatter) Format(b []byte, e *Entry) []byte {
+	fields := flattenFields(e.Fields)
+	signature, fields := siemSignature(f.SignatureKey, e.Level, fields)
+
+	b = append(b, "CEF:0|"...)
+	for _, s := range []string{f.Vendor, f.Product, f.Version, signature, e.Message} {
+		b = append(b, cefHeaderEscaper.Replace(s)...)
+		b = append(b, '|')
+	}
+	b = strconv.AppendInt(b, int64(siemSeverities[e.Level]), 10)
+	b = append(b, '|')
+
+	sep := ""
+	if !e.Time.IsZero() {
+		b = append(b, "rt="...)
+		b = strconv.AppendInt(b, e.Time.UnixNano()/1e6, 10)
+		sep = " "
+	}
+	for _, fl := range fields {
+		k := siemKey(f.Keys, fl.Key)
+		if k == "" {
+			continue
+		}
+		b = append(b, sep...)
+		b = append(b, k...)
+		b = append(b, '=')
+		b = append(b, cefValueEscaper.Replace(string(appendValue(nil, fl.Value)))...)
+		sep = " "
+	}
+	return b
+}
+
+// Formats entries in QRadar's Log Event Extended Format, version 2.0, with
+// attributes separated by tabs:
+//
+//	LEEF:2.0|Acme|API|1.2|warn|devTime=1577934245000	sev=5	msg=login failed	usrName=alice
+//
+// The event id is the level, unless the EventIDKey field is set.  Keys maps
+// field keys to LEEF attribute names, such as "user": "usrName"; other
+// fields keep their own keys, with characters other than letters, digits
+// and underscores removed.
+type LEEFFormatter struct {
+	Vendor     string
+	Product    string
+	Version    string
+	EventIDKey string
+	Keys       map[string]string
+}
+
+var (
+	leefHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	leefValueEscaper  = strings.NewReplacer("\t", `\t`, "\n", `\n`, "\r", `\r`)
+)
+
+func (f LEEFFormatter) Format(b []byte, e *Entry) []byte {
+	fields := flattenFields(e.Fields)
+	eventID, fields := siemSignature(f.EventIDKey, e.Level, fields)
+
+	b = append(b, "LEEF:2.0|"...)
+	for _, s := range []string{f.Vendor, f.Product, f.Version, eventID} {
+		b = append(b, leefHeaderEscaper.Replace(s)...)
+		b = append(b, '|')
+	}
+
+	if !e.Time.IsZero() {
+		b = append(b, "devTime="...)
+		b = strconv.AppendInt(b, e.Time.UnixNano()/1e6, 10)
+		b = append(b, '\t')
+	}
+	b = append(b, "sev="...)
+	b = strconv.AppendInt(b, int64(siemSeverities[e.Level]), 10)
+	b = append(b, "\tmsg="...)
+	b = append(b, leefValueEscaper.Replace(e.Message)...)
+	for _, fl := range fields {
+		k := siemKey(f.Keys, fl.Key)
+		if k == "" {
+			continue
+		}
+		b = append(b, '\t')
+		b = append(b, k...)
+		b = append(b, '=')
+		b = append(b, leefValueEscaper.Replace(string(appendValue(nil, fl.Value)))...)
+	}
+	return b
+}
+
+// Returns the signature, from the field key if present, and the fields
+// without it
+func siemSignature(key string, level Level, fields []Field) (string, []Field) {
+	if key != "" {
+		for i, f := range fields {
+			if f.Key == key {
+				rest := append(fields[:i:i], fields[i+1:]...)
+				return string(appendValue(nil, f.Value)), rest
+			}
+		}
+	}
+	return level.String(), fields
+}
+
+func siemKey(keys map[string]string, k string) string {
+	if mapped, ok := keys[k]; ok {
+		return mapped
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return -1
+	}, k)
+}
@@ -0,0 +1,50 @@
+package alog
+
+import (
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestCEFFormatter(c *check.C) {
+	f := CEFFormatter{
+		Vendor:       "Acme",
+		Product:      "API|Gateway",
+		Version:      "1.2",
+		SignatureKey: "event",
+		Keys:         map[string]string{"user": "suser", "ip": "src"},
+	}
+	e := &Entry{
+		Time:    time.Unix(1577934245, 0),
+		Level:   WarnLevel,
+		Message: "login failed",
+		Fields: []Field{
+			{"user", "alice"},
+			{"event", "auth-401"},
+			{"ip", "10.0.0.1"},
+			{"http", Group("path", "/a=b\nc")},
+		},
+	}
+	c.Assert(string(f.Format(nil, e)), check.Equals,
+		`CEF:0|Acme|API\|Gateway|1.2|auth-401|login failed|5|rt=1577934245000 suser=alice src=10.0.0.1 httppath=/a\=b\nc`)
+
+	e = &Entry{Level: ErrorLevel, Message: "m"}
+	c.Assert(string(CEFFormatter{}.Format(nil, e)), check.Equals, "CEF:0||||error|m|7|")
+}
+
+func (s *Suite) TestLEEFFormatter(c *check.C) {
+	f := LEEFFormatter{
+		Vendor:  "Acme",
+		Product: "API",
+		Version: "1.2",
+		Keys:    map[string]string{"user": "usrName"},
+	}
+	e := &Entry{
+		Time:    time.Unix(1577934245, 0),
+		Level:   FatalLevel,
+		Message: "a\tb",
+		Fields:  []Field{{"user", "alice"}, {"req-id", 7}},
+	}
+	c.Assert(string(f.Format(nil, e)), check.Equals,
+		"LEEF:2.0|Acme|API|1.2|fatal|devTime=1577934245000\tsev=10\tmsg=a\\tb\tusrName=alice\treqid=7")
+}
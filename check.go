@@ -0,0 +1,26 @@
+package alog
+
+// Logs msg at ErrorLevel with err and fields added, if err is non-nil.
+// Returns whether it was, so callers can bail out:
+//
+//	if log.Check(err, "reading config", alog.Field{Key: "path", Value: path}) {
+//		return
+//	}
+func (a *Log) Check(err error, msg string, fields ...Field) bool {
+	if err == nil {
+		return false
+	}
+	fields = append([]Field{{"error", err}}, fields...)
+	a.output(ErrorLevel, message{fmtString, msg, nil, fields})
+	return true
+}
+
+// Logs err at FatalLevel and exits as Fatal does, if err is non-nil.  For
+// setup code in main, where an error can't be recovered from.
+func (a *Log) Must(err error) {
+	if err == nil {
+		return
+	}
+	a.output(FatalLevel, message{fmtString, err.Error(), nil, nil})
+	a.exit()
+}
@@ -0,0 +1,29 @@
+package alog
+
+import (
+	"errors"
+	stdlog "log"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestCheck(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("app", "api")
+
+	c.Assert(log.Check(nil, "reading config"), check.Equals, false)
+	c.Assert(t.msgs, check.HasLen, 0)
+
+	err := errors.New("not found")
+	c.Assert(log.Check(err, "reading config", Field{"path", "/etc/app"}), check.Equals, true)
+	checkLast(c, t, "ERROR [app=api error=not found path=/etc/app] reading config")
+
+	log.SetFlags(stdlog.Lshortfile)
+	log.Check(err, "here")
+	c.Assert(t.last(), check.Matches, `check_test\.go:\d+: .*\n`)
+
+	// Must only exits on error
+	log.Must(nil)
+}
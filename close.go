@@ -0,0 +1,51 @@
+package alog
+
+import (
+	"io"
+	"os"
+)
+
+// Implemented by writers that buffer entries, such as batching or
+// asynchronous writers.  Log.Flush and Log.Close call it.
+type Flusher interface {
+	Flush() error
+}
+
+// Writes out any entries buffered by the logger's writer
+func (a *Log) Flush() error {
+	if a == nil {
+		return nil
+	}
+	return flushWriter(a.Logger.Writer())
+}
+
+// Flushes and closes the logger's writer, for use with defer in main.  The
+// logger's copies share the writer, so must not be used afterwards.
+// Stdout and stderr are left open.
+func (a *Log) Close() error {
+	if a == nil {
+		return nil
+	}
+	err := a.Flush()
+	if cerr := closeWriter(a.Logger.Writer()); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func flushWriter(w io.Writer) error {
+	if f, ok := w.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func closeWriter(w io.Writer) error {
+	if w == os.Stdout || w == os.Stderr {
+		return nil
+	}
+	if c, ok := w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package alog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/check.v1"
+)
+
+type flushCloser struct {
+	Thief
+	flushed, closed int
+	err             error
+}
+
+func (f *flushCloser) Flush() error {
+	f.flushed++
+	return f.err
+}
+
+func (f *flushCloser) Close() error {
+	f.closed++
+	return nil
+}
+
+func (s *Suite) TestFlushClose(c *check.C) {
+	w := &flushCloser{}
+	log := New(w)
+	c.Assert(log.Flush(), check.IsNil)
+	c.Assert(w.flushed, check.Equals, 1)
+	c.Assert(log.Close(), check.IsNil)
+	c.Assert(w.flushed, check.Equals, 2)
+	c.Assert(w.closed, check.Equals, 1)
+
+	// Flush errors are reported, but Close still closes
+	w.err = errors.New("full")
+	c.Assert(log.Close(), check.ErrorMatches, "full")
+	c.Assert(w.closed, check.Equals, 2)
+
+	// Plain writers and stdio
+	c.Assert(New(&Thief{}).Close(), check.IsNil)
+	c.Assert(New(os.Stderr).Close(), check.IsNil)
+	_, err := os.Stderr.Stat()
+	c.Assert(err, check.IsNil)
+
+	var nilLog *Log
+	c.Assert(nilLog.Flush(), check.IsNil)
+	c.Assert(nilLog.Close(), check.IsNil)
+}
+
+func (s *Suite) TestBuiltClose(c *check.C) {
+	path := filepath.Join(c.MkDir(), "app.log")
+	log, err := Build(Config{Outputs: []OutputConfig{{Type: "stdout"}, {Type: "file", Path: path}}})
+	c.Assert(err, check.IsNil)
+	c.Assert(log.Close(), check.IsNil)
+
+	// The file was closed
+	f := log.Logger.Writer().(*switchWriter).outputs[1].(*FileWriter)
+	_, err = f.Write([]byte("x"))
+	c.Assert(err, check.NotNil)
+}
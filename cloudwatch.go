@@ -0,0 +1,205 @@
+package alog
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+// CloudWatch Logs limits on PutLogEvents
+const (
+	cloudWatchMaxEvents   = 10000
+	cloudWatchMaxBytes    = 1048576
+	cloudWatchOverhead    = 26 // Counted per event towards cloudWatchMaxBytes
+	cloudWatchMaxEventLen = 256*1024 - cloudWatchOverhead
+)
+
+// Returned by CloudWatchLogsAPI implementations when a log group or stream
+// being created already exists, or one being written to doesn't
+var (
+	ErrCloudWatchExists   = errors.New("alog: cloudwatch resource already exists")
+	ErrCloudWatchNotFound = errors.New("alog: cloudwatch resource not found")
+)
+
+// Returned by CloudWatchLogsAPI implementations when PutLogEvents is given
+// the wrong sequence token
+type CloudWatchSequenceError struct {
+	Expected string
+}
+
+func (e *CloudWatchSequenceError) Error() string {
+	return "alog: cloudwatch invalid sequence token, expected " + e.Expected
+}
+
+// A single CloudWatch log event
+type CloudWatchEvent struct {
+	Timestamp time.Time
+	Message   string
+}
+
+// The CloudWatch Logs calls CloudWatchWriter makes.  Implementations wrap an
+// AWS SDK client and translate its errors to ErrCloudWatchExists,
+// ErrCloudWatchNotFound and *CloudWatchSequenceError; see the
+// cloudwatchalog package.
+type CloudWatchLogsAPI interface {
+	CreateLogGroup(ctx context.Context, group string) error
+	CreateLogStream(ctx context.Context, group, stream string) error
+	// Returns the next sequence token
+	PutLogEvents(ctx context.Context, group, stream string, events []CloudWatchEvent, token string) (string, error)
+}
+
+// Settings for a CloudWatchWriter
+type CloudWatchConfig struct {
+	Client CloudWatchLogsAPI
+	Group  string
+	Stream string
+	// Events per request, at most and by default 10000.  Batches are also
+	// sent before exceeding CloudWatch's 1MB limit.
+	BatchSize int
+	// How often a partial batch is sent, 5s by default.  Negative for only
+	// when full and on Flush.
+	FlushInterval time.Duration
+	// For failed requests
+	Retry RetryPolicy
+	// Per request, 10s by default
+	Timeout time.Duration
+}
+
+// Writer that sends entries to CloudWatch Logs.  The log group and stream
+// are created if they don't exist.  Each line becomes an event, so use a
+// Formatter such as JSONFormatter for structured output; as the logger's
+// writer, events carry the entries' own timestamps.  Lines over
+// CloudWatch's 256KB limit are truncated.  Close must be called to send the
+// last batch.
+type CloudWatchWriter struct {
+	cfg   CloudWatchConfig
+	batch *batcher
+	sleep func(time.Duration)
+
+	// Only used by send, which runs on the batcher's one sending goroutine
+	token string
+}
+
+func NewCloudWatchWriter(cfg CloudWatchConfig) *CloudWatchWriter {
+	if cfg.BatchSize <= 0 || cfg.BatchSize > cloudWatchMaxEvents {
+		cfg.BatchSize = cloudWatchMaxEvents
+	}
+	if cfg.FlushInterval == 0 {
+		cfg.FlushInterval = 5 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	cfg.Retry = cfg.Retry.withDefaults()
+	w := &CloudWatchWriter{cfg: cfg, sleep: time.Sleep}
+	// Items carry an 8 byte timestamp, which isn't sent
+	w.batch = newBatcher(w.send, cfg.BatchSize, cloudWatchMaxBytes, cfg.FlushInterval)
+	w.batch.overhead = cloudWatchOverhead - 8
+	return w
+}
+
+func (w *CloudWatchWriter) Write(p []byte) (int, error) {
+	return len(p), w.batch.add(cloudWatchItem(time.Now(), p))
+}
+
+func (w *CloudWatchWriter) WriteEntry(e *Entry, p []byte) (int, error) {
+	return len(p), w.batch.add(cloudWatchItem(e.Time, p))
+}
+
+// Encodes the timestamp in milliseconds, then the line
+func cloudWatchItem(t time.Time, p []byte) []byte {
+	p = bytes.TrimRight(p, "\n")
+	if len(p) > cloudWatchMaxEventLen {
+		n := cloudWatchMaxEventLen
+		for n > 0 && !utf8.RuneStart(p[n]) {
+			n--
+		}
+		p = p[:n]
+	}
+	item := make([]byte, 8, 8+len(p))
+	binary.BigEndian.PutUint64(item, uint64(t.UnixNano()/int64(time.Millisecond)))
+	return append(item, p...)
+}
+
+func (w *CloudWatchWriter) send(items [][]byte) error {
+	events := make([]CloudWatchEvent, len(items))
+	for i, item := range items {
+		ms := int64(binary.BigEndian.Uint64(item))
+		events[i] = CloudWatchEvent{time.Unix(0, ms*int64(time.Millisecond)), string(item[8:])}
+	}
+	// Events must be in order, and entries from several goroutines may not be
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+
+	var err error
+	for n := 0; n < w.cfg.Retry.Attempts; n++ {
+		err = w.put(events)
+
+		var seqErr *CloudWatchSequenceError
+		switch {
+		case err == nil:
+			return nil
+		case errors.Is(err, ErrCloudWatchNotFound):
+			if err = w.create(); err == nil {
+				continue
+			}
+		case errors.As(err, &seqErr):
+			w.token = seqErr.Expected
+			continue
+		}
+		if n < w.cfg.Retry.Attempts-1 {
+			w.sleep(w.cfg.Retry.delay(n))
+		}
+	}
+	return err
+}
+
+func (w *CloudWatchWriter) put(events []CloudWatchEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.cfg.Timeout)
+	defer cancel()
+	token, err := w.cfg.Client.PutLogEvents(ctx, w.cfg.Group, w.cfg.Stream, events, w.token)
+	if err == nil {
+		w.token = token
+	}
+	return err
+}
+
+// Creates the log group and stream, unless they exist
+func (w *CloudWatchWriter) create() error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.cfg.Timeout)
+	defer cancel()
+	err := w.cfg.Client.CreateLogGroup(ctx, w.cfg.Group)
+	if err != nil && !errors.Is(err, ErrCloudWatchExists) {
+		return err
+	}
+	err = w.cfg.Client.CreateLogStream(ctx, w.cfg.Group, w.cfg.Stream)
+	if err != nil && !errors.Is(err, ErrCloudWatchExists) {
+		return err
+	}
+	w.token = ""
+	return nil
+}
+
+// Passes failed background sends to h
+func (w *CloudWatchWriter) SetErrorHandler(h func(error)) {
+	w.batch.errs.set(h)
+}
+
+func (w *CloudWatchWriter) Status() []SinkStatus {
+	return []SinkStatus{w.batch.status("cloudwatch " + w.cfg.Group + "/" + w.cfg.Stream)}
+}
+
+// Sends the current batch
+func (w *CloudWatchWriter) Flush() error {
+	return w.batch.flush()
+}
+
+// Sends the last batch and stops the flush timer
+func (w *CloudWatchWriter) Close() error {
+	return w.batch.close()
+}
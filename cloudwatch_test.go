@@ -0,0 +1,106 @@
+package alog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+// In-memory CloudWatch Logs
+type fakeCloudWatch struct {
+	groups  map[string]bool
+	streams map[string][]CloudWatchEvent
+	token   string
+	calls   []string
+	fail    int // PutLogEvents failures to return
+}
+
+func newFakeCloudWatch() *fakeCloudWatch {
+	return &fakeCloudWatch{groups: map[string]bool{}, streams: map[string][]CloudWatchEvent{}}
+}
+
+func (f *fakeCloudWatch) CreateLogGroup(ctx context.Context, group string) error {
+	f.calls = append(f.calls, "CreateLogGroup")
+	if f.groups[group] {
+		return ErrCloudWatchExists
+	}
+	f.groups[group] = true
+	return nil
+}
+
+func (f *fakeCloudWatch) CreateLogStream(ctx context.Context, group, stream string) error {
+	f.calls = append(f.calls, "CreateLogStream")
+	if _, ok := f.streams[group+"/"+stream]; ok {
+		return ErrCloudWatchExists
+	}
+	f.streams[group+"/"+stream] = []CloudWatchEvent{}
+	return nil
+}
+
+func (f *fakeCloudWatch) PutLogEvents(ctx context.Context, group, stream string, events []CloudWatchEvent, token string) (string, error) {
+	f.calls = append(f.calls, "PutLogEvents")
+	if f.fail > 0 {
+		f.fail--
+		return "", errors.New("throttled")
+	}
+	s, ok := f.streams[group+"/"+stream]
+	if !ok {
+		return "", ErrCloudWatchNotFound
+	}
+	if token != f.token {
+		return "", &CloudWatchSequenceError{f.token}
+	}
+	f.streams[group+"/"+stream] = append(s, events...)
+	f.token += "x"
+	return f.token, nil
+}
+
+func (s *Suite) TestCloudWatchWriter(c *check.C) {
+	api := newFakeCloudWatch()
+	w := NewCloudWatchWriter(CloudWatchConfig{Client: api, Group: "g", Stream: "s", FlushInterval: -1})
+	var sleeps []time.Duration
+	w.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+	log := New(w)
+	log.SetFlags(0)
+	log.Print("one")
+	w.Write([]byte("two\n"))
+	c.Assert(w.Flush(), check.IsNil)
+
+	// Created on demand
+	c.Assert(api.calls, check.DeepEquals, []string{"PutLogEvents", "CreateLogGroup", "CreateLogStream", "PutLogEvents"})
+	events := api.streams["g/s"]
+	c.Assert(events, check.HasLen, 2)
+	c.Assert(events[0].Message, check.Equals, "one")
+	c.Assert(events[1].Message, check.Equals, "two")
+	c.Assert(events[0].Timestamp.After(events[1].Timestamp), check.Equals, false)
+
+	// A stale token is corrected, and failures retried
+	api.token = "other"
+	api.fail = 1
+	api.calls = nil
+	log.Print("three")
+	c.Assert(w.Close(), check.IsNil)
+	c.Assert(api.calls, check.DeepEquals, []string{"PutLogEvents", "PutLogEvents", "PutLogEvents"})
+	c.Assert(sleeps, check.HasLen, 1)
+	c.Assert(api.streams["g/s"], check.HasLen, 3)
+
+	// Gives up after the attempts
+	api.fail = 10
+	w = NewCloudWatchWriter(CloudWatchConfig{Client: api, Group: "g", Stream: "s", FlushInterval: -1})
+	w.sleep = func(time.Duration) {}
+	w.Write([]byte("x\n"))
+	c.Assert(w.Flush(), check.ErrorMatches, "throttled")
+}
+
+func (s *Suite) TestCloudWatchItem(c *check.C) {
+	t := time.Unix(1, 2e6)
+	item := cloudWatchItem(t, []byte("line\n"))
+	c.Assert(string(item[8:]), check.Equals, "line")
+
+	long := cloudWatchItem(t, []byte(strings.Repeat("x", 300*1024)))
+	c.Assert(len(long)-8, check.Equals, cloudWatchMaxEventLen)
+}
@@ -0,0 +1,98 @@
+// Package cloudwatchalog adapts the AWS SDK's CloudWatch Logs client to
+// alog.CloudWatchLogsAPI, for use with alog.CloudWatchWriter:
+//
+//	cfg, err := config.LoadDefaultConfig(ctx)
+//	w := alog.NewCloudWatchWriter(alog.CloudWatchConfig{
+//		Client: cloudwatchalog.New(cloudwatchlogs.NewFromConfig(cfg)),
+//		Group:  "/ecs/api",
+//		Stream: taskID,
+//	})
+package cloudwatchalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/xsleonard/alog"
+)
+
+// The client methods used
+type API interface {
+	CreateLogGroup(ctx context.Context, in *cloudwatchlogs.CreateLogGroupInput, opts ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogGroupOutput, error)
+	CreateLogStream(ctx context.Context, in *cloudwatchlogs.CreateLogStreamInput, opts ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogStreamOutput, error)
+	PutLogEvents(ctx context.Context, in *cloudwatchlogs.PutLogEventsInput, opts ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutLogEventsOutput, error)
+}
+
+type client struct {
+	api API
+}
+
+// Returns an alog.CloudWatchLogsAPI calling api, normally a
+// *cloudwatchlogs.Client
+func New(api API) alog.CloudWatchLogsAPI {
+	return &client{api}
+}
+
+func (c *client) CreateLogGroup(ctx context.Context, group string) error {
+	_, err := c.api.CreateLogGroup(ctx, &cloudwatchlogs.CreateLogGroupInput{
+		LogGroupName: aws.String(group),
+	})
+	return translate(err)
+}
+
+func (c *client) CreateLogStream(ctx context.Context, group, stream string) error {
+	_, err := c.api.CreateLogStream(ctx, &cloudwatchlogs.CreateLogStreamInput{
+		LogGroupName:  aws.String(group),
+		LogStreamName: aws.String(stream),
+	})
+	return translate(err)
+}
+
+func (c *client) PutLogEvents(ctx context.Context, group, stream string, events []alog.CloudWatchEvent, token string) (string, error) {
+	in := &cloudwatchlogs.PutLogEventsInput{
+		LogGroupName:  aws.String(group),
+		LogStreamName: aws.String(stream),
+		LogEvents:     make([]types.InputLogEvent, len(events)),
+	}
+	if token != "" {
+		in.SequenceToken = aws.String(token)
+	}
+	for i, e := range events {
+		in.LogEvents[i] = types.InputLogEvent{
+			Timestamp: aws.Int64(e.Timestamp.UnixMilli()),
+			Message:   aws.String(e.Message),
+		}
+	}
+
+	out, err := c.api.PutLogEvents(ctx, in)
+	if err != nil {
+		return "", translate(err)
+	}
+	return aws.ToString(out.NextSequenceToken), nil
+}
+
+// Maps the SDK's errors to the ones CloudWatchWriter handles
+func translate(err error) error {
+	var exists *types.ResourceAlreadyExistsException
+	var notFound *types.ResourceNotFoundException
+	var seq *types.InvalidSequenceTokenException
+	var accepted *types.DataAlreadyAcceptedException
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &exists):
+		return fmt.Errorf("%w: %v", alog.ErrCloudWatchExists, err)
+	case errors.As(err, &notFound):
+		return fmt.Errorf("%w: %v", alog.ErrCloudWatchNotFound, err)
+	case errors.As(err, &seq):
+		return &alog.CloudWatchSequenceError{Expected: aws.ToString(seq.ExpectedSequenceToken)}
+	case errors.As(err, &accepted):
+		return &alog.CloudWatchSequenceError{Expected: aws.ToString(accepted.ExpectedSequenceToken)}
+	default:
+		return err
+	}
+}
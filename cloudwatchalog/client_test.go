@@ -0,0 +1,66 @@
+package cloudwatchalog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/xsleonard/alog"
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type Suite struct{}
+
+var _ = check.Suite(&Suite{})
+
+type fakeAPI struct {
+	err error
+	put *cloudwatchlogs.PutLogEventsInput
+}
+
+func (f *fakeAPI) CreateLogGroup(ctx context.Context, in *cloudwatchlogs.CreateLogGroupInput, opts ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogGroupOutput, error) {
+	return &cloudwatchlogs.CreateLogGroupOutput{}, f.err
+}
+
+func (f *fakeAPI) CreateLogStream(ctx context.Context, in *cloudwatchlogs.CreateLogStreamInput, opts ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogStreamOutput, error) {
+	return &cloudwatchlogs.CreateLogStreamOutput{}, f.err
+}
+
+func (f *fakeAPI) PutLogEvents(ctx context.Context, in *cloudwatchlogs.PutLogEventsInput, opts ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutLogEventsOutput, error) {
+	f.put = in
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &cloudwatchlogs.PutLogEventsOutput{NextSequenceToken: aws.String("next")}, nil
+}
+
+func (s *Suite) TestClient(c *check.C) {
+	api := &fakeAPI{}
+	cl := New(api)
+	ctx := context.Background()
+
+	token, err := cl.PutLogEvents(ctx, "g", "s", []alog.CloudWatchEvent{{Timestamp: time.Unix(1, 0), Message: "hi"}}, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(token, check.Equals, "next")
+	c.Assert(aws.ToString(api.put.LogGroupName), check.Equals, "g")
+	c.Assert(api.put.SequenceToken, check.IsNil)
+	c.Assert(aws.ToInt64(api.put.LogEvents[0].Timestamp), check.Equals, int64(1000))
+	c.Assert(aws.ToString(api.put.LogEvents[0].Message), check.Equals, "hi")
+
+	api.err = &types.ResourceAlreadyExistsException{}
+	c.Assert(errors.Is(cl.CreateLogGroup(ctx, "g"), alog.ErrCloudWatchExists), check.Equals, true)
+	api.err = &types.ResourceNotFoundException{}
+	_, err = cl.PutLogEvents(ctx, "g", "s", nil, "t")
+	c.Assert(errors.Is(err, alog.ErrCloudWatchNotFound), check.Equals, true)
+	api.err = &types.InvalidSequenceTokenException{ExpectedSequenceToken: aws.String("abc")}
+	_, err = cl.PutLogEvents(ctx, "g", "s", nil, "t")
+	c.Assert(err, check.DeepEquals, &alog.CloudWatchSequenceError{Expected: "abc"})
+	api.err = errors.New("boom")
+	c.Assert(cl.CreateLogStream(ctx, "g", "s"), check.ErrorMatches, "boom")
+}
@@ -0,0 +1,15 @@
+package alog
+
+import "io"
+
+// Prepares w for ANSI color escapes, such as ConsoleFormatter's, and
+// reports whether they can be used.  Only terminals can show them, so
+// false is returned for files, pipes and writers other than an *os.File.
+// On Windows, virtual terminal processing is turned on for a console, and
+// false is returned for a console that doesn't support it, such as cmd.exe
+// before Windows 10.
+//
+//	log.SetFormatter(alog.ConsoleFormatter{Color: alog.EnableColor(os.Stderr)})
+func EnableColor(w io.Writer) bool {
+	return enableColor(w)
+}
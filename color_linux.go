@@ -0,0 +1,5 @@
+package alog
+
+import "syscall"
+
+const ioctlReadTermios = syscall.TCGETS
@@ -0,0 +1,10 @@
+//go:build !windows && !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package alog
+
+import "io"
+
+// No terminal to detect
+func enableColor(w io.Writer) bool {
+	return false
+}
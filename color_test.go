@@ -0,0 +1,23 @@
+package alog
+
+import (
+	"bytes"
+	"os"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestEnableColor(c *check.C) {
+	c.Assert(EnableColor(&bytes.Buffer{}), check.Equals, false)
+
+	r, w, err := os.Pipe()
+	c.Assert(err, check.IsNil)
+	defer r.Close()
+	defer w.Close()
+	c.Assert(EnableColor(w), check.Equals, false)
+
+	f, err := os.Create(c.MkDir() + "/out.log")
+	c.Assert(err, check.IsNil)
+	defer f.Close()
+	c.Assert(EnableColor(f), check.Equals, false)
+}
@@ -0,0 +1,315 @@
+package alog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// Declarative logger configuration, for use with Build.  Format is text (the
+// default), one of json, pretty-json, console, gcp, datadog and ecs for the
+// corresponding Formatter, pretty-json-compact for a PrettyJSONFormatter
+// with Compact, or common or combined for an AccessLogFormatter.  Color
+// sets the Color option of the console and pretty-json formats.
+// LoadConfig reads a JSON file, and yamlalog.LoadConfig the same settings
+// from YAML.
+//
+//	{
+//	  "level": "debug",
+//	  "outputs": [{"type": "stderr"}, {"type": "file", "path": "/var/log/app.log"}],
+//	  "sampling": {"key": "trace_id", "rate": 0.1}
+//	}
+type Config struct {
+	Level    Level           `json:"level" yaml:"level"`
+	Format   string          `json:"format" yaml:"format"`
+	Color    bool            `json:"color" yaml:"color"`
+	Outputs  []OutputConfig  `json:"outputs" yaml:"outputs"`
+	Sampling *SamplingConfig `json:"sampling" yaml:"sampling"`
+}
+
+// Destination for log output.  Type is one of stdout, stderr, file, syslog,
+// tcp, udp, unix, unixgram or http.  Path is required for file and the Unix
+// socket types, Address for tcp and udp, and URL for http, which posts
+// batches of entries to it.  For syslog, an empty Network and Address use
+// the local syslog daemon.  MaxSize and MaxBackups rotate a file output;
+// see FileWriter.SetRotation.
+type OutputConfig struct {
+	Type       string `json:"type" yaml:"type"`
+	Path       string `json:"path" yaml:"path"`
+	Network    string `json:"network" yaml:"network"`
+	Address    string `json:"address" yaml:"address"`
+	URL        string `json:"url" yaml:"url"`
+	Tag        string `json:"tag" yaml:"tag"`
+	MaxSize    int64  `json:"max_size" yaml:"max_size"`
+	MaxBackups int    `json:"max_backups" yaml:"max_backups"`
+}
+
+// See Log.SetSampling
+type SamplingConfig struct {
+	Key  string  `json:"key" yaml:"key"`
+	Rate float64 `json:"rate" yaml:"rate"`
+}
+
+// Reads a JSON Config from a file
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	f, err := os.Open(path)
+	if err != nil {
+		return cfg, err
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(&cfg); err != nil {
+		return cfg, fmt.Errorf("alog: parsing %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// Creates a logger described by cfg.  With no outputs, it writes to stderr.
+// The logger and its copies can be reconfigured later with Apply.
+func Build(cfg Config) (*Log, error) {
+	sw := &switchWriter{}
+	a := New(sw)
+	if err := a.Apply(cfg); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Reconfigures a logger created by Build, and all of its copies.  Outputs
+// are opened before the old ones are closed, so no entries are lost.
+// On error, the logger is unchanged.
+func (a *Log) Apply(cfg Config) error {
+	if a == nil {
+		return nil
+	}
+	sw, ok := a.Logger.Writer().(*switchWriter)
+	if !ok {
+		return errors.New("alog: only loggers created by Build can be reconfigured")
+	}
+
+	formatter, err := buildFormatter(cfg.Format, cfg.Color)
+	if err != nil {
+		return err
+	}
+
+	w, outputs, err := buildOutputs(cfg.Outputs)
+	if err != nil {
+		return err
+	}
+
+	sw.swap(w, outputs)
+	a.SetFormatter(formatter)
+	a.SetLevel(cfg.Level)
+	if cfg.Sampling != nil {
+		a.SetSampling(cfg.Sampling.Key, cfg.Sampling.Rate)
+	} else {
+		a.SetSampling("", 0)
+	}
+	return nil
+}
+
+// Reloads the config file at path and applies it whenever one of sigs is
+// received, typically syscall.SIGHUP.  Failures are logged and leave the
+// current configuration in place.  Call stop to stop watching.
+func (a *Log) ReloadOnSignal(path string, sigs ...os.Signal) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		for {
+			select {
+			case <-ch:
+				cfg, err := LoadConfig(path)
+				if err == nil {
+					err = a.Apply(cfg)
+				}
+				if err != nil {
+					a.Errorf("config reload failed: %v", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+// Writer whose destination can be replaced while in use
+type switchWriter struct {
+	w       io.Writer
+	outputs []io.Writer
+	mutex   sync.RWMutex
+}
+
+func (s *switchWriter) Write(p []byte) (int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.w.Write(p)
+}
+
+func (s *switchWriter) Flush() error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var err error
+	for _, w := range s.outputs {
+		if ferr := flushWriter(w); err == nil {
+			err = ferr
+		}
+	}
+	return err
+}
+
+func (s *switchWriter) Status() []SinkStatus {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var status []SinkStatus
+	for _, w := range s.outputs {
+		status = append(status, writerStatus(w)...)
+	}
+	return status
+}
+
+func (s *switchWriter) Close() error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return closeWriters(s.outputs)
+}
+
+// Replaces the destination, flushing and closing the previous outputs
+func (s *switchWriter) swap(w io.Writer, outputs []io.Writer) {
+	s.mutex.Lock()
+	old := s.outputs
+	s.w, s.outputs = w, outputs
+	s.mutex.Unlock()
+
+	for _, w := range old {
+		flushWriter(w)
+	}
+	closeWriters(old)
+}
+
+func closeWriters(ws []io.Writer) error {
+	var err error
+	for _, w := range ws {
+		if cerr := closeWriter(w); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func buildFormatter(format string, color bool) (Formatter, error) {
+	switch format {
+	case "", "text":
+		return nil, nil
+	case "json":
+		return JSONFormatter{}, nil
+	case "console":
+		return ConsoleFormatter{Color: color}, nil
+	case "pretty-json":
+		return PrettyJSONFormatter{Color: color}, nil
+	case "pretty-json-compact":
+		return PrettyJSONFormatter{Color: color, Compact: true}, nil
+	case "gcp":
+		return GCPFormatter{}, nil
+	case "datadog":
+		return DatadogFormatter{}, nil
+	case "ecs":
+		return ECSFormatter{}, nil
+	case "common":
+		return AccessLogFormatter{}, nil
+	case "combined":
+		return AccessLogFormatter{Combined: true}, nil
+	default:
+		return nil, fmt.Errorf("alog: unknown format %q", format)
+	}
+}
+
+// Returns the combined writer and the individual outputs
+func buildOutputs(cfgs []OutputConfig) (io.Writer, []io.Writer, error) {
+	if len(cfgs) == 0 {
+		return os.Stderr, []io.Writer{os.Stderr}, nil
+	}
+
+	ws := make([]io.Writer, 0, len(cfgs))
+	for _, c := range cfgs {
+		w, err := buildOutput(c)
+		if err != nil {
+			closeWriters(ws)
+			return nil, nil, err
+		}
+		ws = append(ws, w)
+	}
+
+	if len(ws) == 1 {
+		return ws[0], ws, nil
+	}
+	return io.MultiWriter(ws...), ws, nil
+}
+
+func buildOutput(c OutputConfig) (io.Writer, error) {
+	switch c.Type {
+	case "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	case "file":
+		if c.Path == "" {
+			return nil, fmt.Errorf("alog: file output has no path")
+		}
+		w, err := OpenFile(c.Path)
+		if err != nil {
+			return nil, err
+		}
+		w.SetRotation(c.MaxSize, c.MaxBackups)
+		return w, nil
+	case "syslog":
+		return dialSyslog(c)
+	case "tcp", "udp":
+		if c.Address == "" {
+			return nil, fmt.Errorf("alog: %s output has no address", c.Type)
+		}
+		return NewNetWriter(c.Type, c.Address, NetOptions{}), nil
+	case "unix", "unixgram":
+		if c.Path == "" {
+			return nil, fmt.Errorf("alog: %s output has no path", c.Type)
+		}
+		return NewNetWriter(c.Type, c.Path, NetOptions{}), nil
+	case "http":
+		if c.URL == "" {
+			return nil, fmt.Errorf("alog: http output has no url")
+		}
+		return newHTTPOutput(c.URL), nil
+	default:
+		return nil, fmt.Errorf("alog: unknown output type %q", c.Type)
+	}
+}
+
+// Posts batches of entries, one after another, to url
+func newHTTPOutput(url string) *BatchWriter {
+	return NewBatchFunc(func(entries [][]byte) error {
+		req, err := http.NewRequest("POST", url, bytes.NewReader(bytes.Join(entries, nil)))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+		return doRequest(http.DefaultClient, req, "http output")
+	}, BatchConfig{MaxEntries: 100})
+}
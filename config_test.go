@@ -0,0 +1,133 @@
+package alog
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestBuild(c *check.C) {
+	dir := c.MkDir()
+	path := filepath.Join(dir, "app.log")
+	cfgPath := filepath.Join(dir, "log.json")
+	err := ioutil.WriteFile(cfgPath, []byte(`{
+		"level": "warn",
+		"outputs": [{"type": "file", "path": "`+path+`"}],
+		"sampling": {"key": "trace", "rate": 0}
+	}`), 0644)
+	c.Assert(err, check.IsNil)
+
+	cfg, err := LoadConfig(cfgPath)
+	c.Assert(err, check.IsNil)
+	c.Assert(cfg.Level, check.Equals, WarnLevel)
+	c.Assert(cfg.Sampling, check.DeepEquals, &SamplingConfig{"trace", 0})
+
+	log, err := Build(cfg)
+	c.Assert(err, check.IsNil)
+	log.SetFlags(0)
+	log.Print("info")
+	log.Warn("warn")
+	log.With("trace", 1).Warn("sampled")
+	log.Logger.Writer().(*switchWriter).swap(ioutil.Discard, nil)
+	c.Assert(log.Enabled(ErrorLevel), check.Equals, false)
+	c.Assert(log.At(ErrorLevel), check.IsNil)
+
+	b, err := ioutil.ReadFile(path)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(b), check.Equals, "WARN warn\n")
+
+	// Defaults
+	log, err = Build(Config{})
+	c.Assert(err, check.IsNil)
+	c.Assert(log.Logger.Writer().(*switchWriter).w, check.Equals, os.Stderr)
+	c.Assert(log.Level(), check.Equals, InfoLevel)
+	c.Assert(log.formatter.get(), check.IsNil)
+
+	log, err = Build(Config{Format: "json"})
+	c.Assert(err, check.IsNil)
+	c.Assert(log.formatter.get(), check.Equals, JSONFormatter{})
+
+	// Errors
+	_, err = Build(Config{Format: "xml"})
+	c.Assert(err, check.ErrorMatches, `alog: unknown format "xml"`)
+	_, err = Build(Config{Outputs: []OutputConfig{{Type: "file"}}})
+	c.Assert(err, check.ErrorMatches, "alog: file output has no path")
+	_, err = Build(Config{Outputs: []OutputConfig{{Type: "stdout"}, {Type: "pigeon"}}})
+	c.Assert(err, check.ErrorMatches, `alog: unknown output type "pigeon"`)
+
+	ioutil.WriteFile(cfgPath, []byte(`{"level": "loud"}`), 0644)
+	_, err = LoadConfig(cfgPath)
+	c.Assert(err, check.ErrorMatches, `alog: parsing .*log.json: alog: unknown level "loud"`)
+}
+
+func (s *Suite) TestBuildOutputs(c *check.C) {
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	dir := c.MkDir()
+	path := filepath.Join(dir, "app.log")
+	log, err := Build(Config{Outputs: []OutputConfig{
+		{Type: "http", URL: srv.URL},
+		{Type: "file", Path: path, MaxSize: 4, MaxBackups: 1},
+	}})
+	c.Assert(err, check.IsNil)
+	log.SetFlags(0)
+	log.Print("one")
+	log.Print("two")
+	c.Assert(log.Close(), check.IsNil)
+
+	c.Assert(string(body), check.Equals, "one\ntwo\n")
+	b, err := ioutil.ReadFile(path + ".1")
+	c.Assert(err, check.IsNil)
+	c.Assert(string(b), check.Equals, "one\n")
+
+	_, err = Build(Config{Outputs: []OutputConfig{{Type: "http"}}})
+	c.Assert(err, check.ErrorMatches, "alog: http output has no url")
+}
+
+func (s *Suite) TestApply(c *check.C) {
+	dir := c.MkDir()
+	path1 := filepath.Join(dir, "1.log")
+	path2 := filepath.Join(dir, "2.log")
+
+	log, err := Build(Config{Outputs: []OutputConfig{{Type: "file", Path: path1}}})
+	c.Assert(err, check.IsNil)
+	log.SetFlags(0)
+	log2 := log.With("x", 1)
+	log2.Debug("hidden")
+	log2.Print("one")
+
+	// Applies to copies too
+	err = log.Apply(Config{
+		Level:    DebugLevel,
+		Outputs:  []OutputConfig{{Type: "file", Path: path2}},
+		Sampling: &SamplingConfig{"x", 0},
+	})
+	c.Assert(err, check.IsNil)
+	log2.Print("sampled")
+	log.Debug("two")
+
+	// Errors leave the configuration alone
+	err = log.Apply(Config{Outputs: []OutputConfig{{Type: "pigeon"}}})
+	c.Assert(err, check.NotNil)
+	log.Debug("three")
+
+	b, err := ioutil.ReadFile(path1)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(b), check.Equals, "[x=1] one\n")
+	b, err = ioutil.ReadFile(path2)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(b), check.Equals, "DEBUG two\nDEBUG three\n")
+
+	// Only for built loggers
+	c.Assert(New(ioutil.Discard).Apply(Config{}), check.ErrorMatches, "alog: only loggers .*")
+	var nilLog *Log
+	c.Assert(nilLog.Apply(Config{}), check.IsNil)
+}
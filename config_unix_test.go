@@ -0,0 +1,31 @@
+//go:build unix
+
+package alog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestReloadOnSignal(c *check.C) {
+	dir := c.MkDir()
+	cfgPath := filepath.Join(dir, "log.json")
+	ioutil.WriteFile(cfgPath, []byte(`{"level": "error"}`), 0644)
+
+	log, err := Build(Config{})
+	c.Assert(err, check.IsNil)
+	stop := log.ReloadOnSignal(cfgPath, syscall.SIGUSR2)
+	defer stop()
+
+	c.Assert(syscall.Kill(os.Getpid(), syscall.SIGUSR2), check.IsNil)
+	for i := 0; i < 100 && log.Level() != ErrorLevel; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Assert(log.Level(), check.Equals, ErrorLevel)
+	stop()
+}
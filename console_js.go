@@ -0,0 +1,79 @@
+//go:build js && wasm
+
+package alog
+
+import (
+	"bytes"
+	"syscall/js"
+)
+
+// Writer for browser and Node builds that calls the JavaScript console
+// method matching each entry's level: console.debug, info, warn or error.
+// As the logger's writer, the message is passed with the fields as an
+// object, so developer tools can expand them; other lines are passed as
+// they are.
+type ConsoleWriter struct {
+	console js.Value
+}
+
+func NewConsoleWriter() *ConsoleWriter {
+	return &ConsoleWriter{js.Global().Get("console")}
+}
+
+func (w *ConsoleWriter) Write(p []byte) (int, error) {
+	w.console.Call("log", string(bytes.TrimRight(p, "\n")))
+	return len(p), nil
+}
+
+func (w *ConsoleWriter) WriteLevel(level Level, p []byte) (int, error) {
+	w.console.Call(consoleMethod(level), string(bytes.TrimRight(p, "\n")))
+	return len(p), nil
+}
+
+func (w *ConsoleWriter) WriteEntry(e *Entry, p []byte) (int, error) {
+	method := consoleMethod(e.Level)
+	if len(e.Fields) == 0 {
+		w.console.Call(method, e.Message)
+	} else {
+		w.console.Call(method, e.Message, jsObject(e.Fields))
+	}
+	return len(p), nil
+}
+
+func consoleMethod(level Level) string {
+	switch {
+	case level <= DebugLevel:
+		return "debug"
+	case level == InfoLevel:
+		return "info"
+	case level == WarnLevel:
+		return "warn"
+	default:
+		return "error"
+	}
+}
+
+func jsObject(fields []Field) js.Value {
+	o := js.Global().Get("Object").New()
+	for _, f := range fields {
+		o.Set(f.Key, jsValue(resolveValue(f.Value)))
+	}
+	return o
+}
+
+// Converts v to a JavaScript value: numbers, strings and booleans as such,
+// groups as objects, and anything else as its %+v string
+func jsValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case nil, bool, string, float64, float32,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64:
+		return x
+	case GroupValue:
+		return jsObject(x)
+	case error:
+		return x.Error()
+	default:
+		return string(appendValue(nil, x))
+	}
+}
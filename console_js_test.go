@@ -0,0 +1,47 @@
+//go:build js && wasm
+
+package alog
+
+import (
+	"errors"
+	"syscall/js"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestConsoleWriter(c *check.C) {
+	// Record calls on a stand-in console object
+	var calls [][]js.Value
+	record := func(method string) js.Func {
+		return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			calls = append(calls, append([]js.Value{js.ValueOf(method)}, args...))
+			return nil
+		})
+	}
+	console := js.Global().Get("Object").New()
+	for _, m := range []string{"log", "debug", "info", "warn", "error"} {
+		f := record(m)
+		defer f.Release()
+		console.Set(m, f)
+	}
+
+	w := &ConsoleWriter{console}
+	log := New(w)
+	log.SetFlags(0)
+	log.Set("user", "alice").Set("n", 3).Set("http", Group("status", 500))
+	log.Warn("slow")
+	log.Copy().SetFields(Field{"error", errors.New("x")}).Info().Msg("done")
+	w.Write([]byte("raw\n"))
+
+	c.Assert(calls, check.HasLen, 3)
+	c.Assert(calls[0][0].String(), check.Equals, "warn")
+	c.Assert(calls[0][1].String(), check.Equals, "slow")
+	fields := calls[0][2]
+	c.Assert(fields.Get("user").String(), check.Equals, "alice")
+	c.Assert(fields.Get("n").Int(), check.Equals, 3)
+	c.Assert(fields.Get("http").Get("status").Int(), check.Equals, 500)
+	c.Assert(calls[1][0].String(), check.Equals, "info")
+	c.Assert(calls[1][2].Get("error").String(), check.Equals, "x")
+	c.Assert(calls[2][0].String(), check.Equals, "log")
+	c.Assert(calls[2][1].String(), check.Equals, "raw")
+}
@@ -0,0 +1,21 @@
+package alog
+
+import "context"
+
+type contextKey int
+
+const (
+	logKey contextKey = iota
+	requestIDKey
+)
+
+// Returns a copy of ctx carrying the logger
+func NewContext(ctx context.Context, a *Log) context.Context {
+	return context.WithValue(ctx, logKey, a)
+}
+
+// Returns the logger carried by ctx, or nil, which is safe to use
+func FromContext(ctx context.Context) *Log {
+	a, _ := ctx.Value(logKey).(*Log)
+	return a
+}
@@ -0,0 +1,49 @@
+package alog
+
+import (
+	"bytes"
+	"encoding/csv"
+	"time"
+)
+
+// Formats entries as CSV rows, quoted per RFC 4180: time, level, message,
+// then the value of each of Keys, empty if the entry doesn't have it.
+// Groups are addressed by their dotted keys, e.g. http.status.  Other
+// fields are left out.
+//
+//	2020-01-02T03:04:05Z,warn,slow request,alice,"a, b"
+type CSVFormatter struct {
+	Keys []string
+}
+
+// Returns the header row, without a newline, for writing before the first
+// entry
+func (f CSVFormatter) Header() []byte {
+	return csvRecord(nil, append([]string{"time", "level", "message"}, f.Keys...))
+}
+
+func (f CSVFormatter) Format(b []byte, e *Entry) []byte {
+	record := make([]string, 3+len(f.Keys))
+	if !e.Time.IsZero() {
+		record[0] = e.Time.Format(time.RFC3339Nano)
+	}
+	record[1] = e.Level.String()
+	record[2] = e.Message
+	fields := flattenFields(e.Fields)
+	for i, k := range f.Keys {
+		for _, fl := range fields {
+			if fl.Key == k {
+				record[3+i] = string(appendValue(nil, fl.Value))
+			}
+		}
+	}
+	return csvRecord(b, record)
+}
+
+func csvRecord(b []byte, record []string) []byte {
+	buf := bytes.NewBuffer(b)
+	w := csv.NewWriter(buf)
+	w.Write(record)
+	w.Flush()
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
@@ -0,0 +1,33 @@
+package alog
+
+import (
+	"encoding/csv"
+	"strings"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestCSVFormatter(c *check.C) {
+	f := CSVFormatter{Keys: []string{"user", "http.status", "missing"}}
+	c.Assert(string(f.Header()), check.Equals, "time,level,message,user,http.status,missing")
+
+	e := &Entry{
+		Time:    time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   WarnLevel,
+		Message: `slow "request", again`,
+		Fields:  []Field{{"user", "a\nb"}, {"other", 1}, {"http", Group("status", 200)}},
+	}
+	row := string(f.Format([]byte("x"), e))
+	c.Assert(row, check.Equals, "x2020-01-02T03:04:05Z,warn,\"slow \"\"request\"\", again\",\"a\nb\",200,")
+
+	// Round trips
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.SetFormatter(f)
+	log.Set("user", "alice, bob").Print("done")
+	records, err := csv.NewReader(strings.NewReader(t.last())).ReadAll()
+	c.Assert(err, check.IsNil)
+	c.Assert(records, check.DeepEquals, [][]string{{"", "info", "done", "alice, bob", "", ""}})
+}
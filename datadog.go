@@ -0,0 +1,117 @@
+package alog
+
+import (
+	"strconv"
+	"time"
+)
+
+// Formats entries as JSON using Datadog's reserved attributes, so no
+// remapping pipeline is needed:
+//
+//	{"date":"...","status":"error","message":"failed","logger":{"name":"db"},"error":{"message":"timeout","kind":"*net.OpError"},"dd":{"trace_id":"123"},"user":"alice"}
+//
+// These fields are mapped; the others are written as attributes:
+//
+//	logger             logger.name
+//	error (an error)   error.message, error.kind
+//	stack              error.stack
+//	trace_id, span_id  dd.trace_id, dd.span_id
+//
+// date and caller are included when the logger's flags ask for them.
+type DatadogFormatter struct{}
+
+var datadogStatuses = map[Level]string{
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warning",
+	ErrorLevel: "error",
+	PanicLevel: "critical",
+	FatalLevel: "emergency",
+}
+
+func (DatadogFormatter) Format(b []byte, e *Entry) []byte {
+	var logger, stack, traceID, spanID interface{}
+	var err error
+	attrs := make([]Field, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		switch f.Key {
+		case "logger":
+			logger = f.Value
+		case "stack":
+			stack = f.Value
+		case "trace_id":
+			traceID = f.Value
+		case "span_id":
+			spanID = f.Value
+		case "error":
+			if x, ok := f.Value.(error); ok {
+				err = x
+				continue
+			}
+			attrs = append(attrs, f)
+		default:
+			attrs = append(attrs, f)
+		}
+	}
+
+	b = append(b, '{')
+	if !e.Time.IsZero() {
+		b = append(b, `"date":"`...)
+		b = e.Time.AppendFormat(b, time.RFC3339Nano)
+		b = append(b, `",`...)
+	}
+	status, ok := datadogStatuses[e.Level]
+	if !ok {
+		status = e.Level.String()
+	}
+	b = append(b, `"status":`...)
+	b = appendJSONString(b, status)
+	b = append(b, `,"message":`...)
+	b = appendJSONString(b, e.Message)
+	if e.File != "" {
+		b = append(b, `,"caller":`...)
+		b = appendJSONString(b, e.File+":"+strconv.Itoa(e.Line))
+	}
+	if logger != nil {
+		b = append(b, `,"logger":{"name":`...)
+		b = appendJSONValue(b, logger)
+		b = append(b, '}')
+	}
+	if err != nil || stack != nil {
+		b = append(b, `,"error":{`...)
+		if err != nil {
+			b = append(b, `"message":`...)
+			b = appendJSONString(b, err.Error())
+			b = append(b, `,"kind":`...)
+			b = appendJSONString(b, errorTypeName(err))
+		}
+		if stack != nil {
+			if err != nil {
+				b = append(b, ',')
+			}
+			b = append(b, `"stack":`...)
+			b = appendJSONString(b, string(appendValue(nil, stack)))
+		}
+		b = append(b, '}')
+	}
+	if traceID != nil || spanID != nil {
+		b = append(b, `,"dd":{`...)
+		if traceID != nil {
+			b = append(b, `"trace_id":`...)
+			b = appendJSONString(b, string(appendValue(nil, traceID)))
+		}
+		if spanID != nil {
+			if traceID != nil {
+				b = append(b, ',')
+			}
+			b = append(b, `"span_id":`...)
+			b = appendJSONString(b, string(appendValue(nil, spanID)))
+		}
+		b = append(b, '}')
+	}
+	for _, f := range attrs {
+		b = append(b, ',')
+		b = appendJSONField(b, f.Key, f.Value)
+	}
+	return append(b, '}')
+}
@@ -0,0 +1,38 @@
+package alog
+
+import (
+	"errors"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestDatadogFormatter(c *check.C) {
+	e := &Entry{
+		Time:    time.Date(2020, 1, 2, 3, 4, 5, 6000000, time.UTC),
+		Level:   ErrorLevel,
+		Message: "failed",
+		Fields: []Field{
+			{"logger", "db"},
+			{"trace_id", uint64(123)},
+			{"user", "alice"},
+			{"error", errors.New("timeout")},
+			{"stack", "main.go:12"},
+		},
+		File: "main.go",
+		Line: 12,
+	}
+	c.Assert(string(DatadogFormatter{}.Format(nil, e)), check.Equals,
+		`{"date":"2020-01-02T03:04:05.006Z","status":"error","message":"failed","caller":"main.go:12",`+
+			`"logger":{"name":"db"},"error":{"message":"timeout","kind":"*errors.errorString","stack":"main.go:12"},`+
+			`"dd":{"trace_id":"123"},"user":"alice"}`)
+
+	// Non-error values under "error" stay attributes
+	e = &Entry{Level: WarnLevel, Message: "m", Fields: []Field{{"error", "x"}, {"span_id", 1}}}
+	c.Assert(string(DatadogFormatter{}.Format(nil, e)), check.Equals,
+		`{"status":"warning","message":"m","dd":{"span_id":"1"},"error":"x"}`)
+
+	log, err := Build(Config{Format: "datadog"})
+	c.Assert(err, check.IsNil)
+	c.Assert(log.formatter.get(), check.Equals, DatadogFormatter{})
+}
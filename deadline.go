@@ -0,0 +1,146 @@
+package alog
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+var ErrWriteTimeout = errors.New("alog: write timed out")
+
+// Writer that gives up on writes taking longer than its timeout, so a hung
+// destination, such as a stuck socket or a FIFO nobody reads, can't block
+// the logger.  A timed out write fails with ErrWriteTimeout, which reaches
+// the logger's error handler, and makes a FallbackWriter wrapping it use
+// its fallback.  The timed out write keeps running in the background;
+// until it returns, later writes fail immediately with ErrWriteTimeout.
+// Entries reach a LevelWriter or EntryWriter destination as they would
+// without the TimeoutWriter.
+type TimeoutWriter struct {
+	w       io.Writer
+	timeout time.Duration
+
+	buf     []byte
+	entry   Entry
+	jobs    chan timeoutJob
+	results chan error
+	stuck   bool
+	closed  bool
+	mutex   sync.Mutex
+}
+
+func NewTimeoutWriter(w io.Writer, timeout time.Duration) *TimeoutWriter {
+	t := &TimeoutWriter{
+		w:       w,
+		timeout: timeout,
+		jobs:    make(chan timeoutJob),
+		results: make(chan error, 1),
+	}
+	go t.run()
+	return t
+}
+
+type timeoutJob struct {
+	level Level
+	e     *Entry
+	p     []byte
+}
+
+func (t *TimeoutWriter) run() {
+	ew, _ := t.w.(EntryWriter)
+	for j := range t.jobs {
+		var err error
+		if j.e != nil {
+			_, err = ew.WriteEntry(j.e, j.p)
+		} else {
+			err = dispatch(t.w, nil, j.level, nil, j.p)
+		}
+		t.results <- err
+	}
+}
+
+func (t *TimeoutWriter) Write(p []byte) (int, error) {
+	return t.write(InfoLevel, nil, p)
+}
+
+func (t *TimeoutWriter) WriteLevel(level Level, p []byte) (int, error) {
+	return t.write(level, nil, p)
+}
+
+func (t *TimeoutWriter) WriteEntry(e *Entry, p []byte) (int, error) {
+	return t.write(e.Level, e, p)
+}
+
+// The logger only builds entries for an EntryWriter destination
+func (t *TimeoutWriter) wantsEntries() bool {
+	_, ok := t.w.(EntryWriter)
+	return ok
+}
+
+func (t *TimeoutWriter) write(level Level, e *Entry, p []byte) (int, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.closed {
+		return 0, ErrClosed
+	}
+	if t.stuck {
+		select {
+		case <-t.results:
+			t.stuck = false
+		default:
+			return 0, ErrWriteTimeout
+		}
+	}
+
+	// The worker is idle, so the buffers are free to reuse.  The write may
+	// outlive this call, so it gets copies.
+	t.buf = append(t.buf[:0], p...)
+	j := timeoutJob{level: level, p: t.buf}
+	if e != nil && t.wantsEntries() {
+		fields := append(t.entry.Fields[:0], e.Fields...)
+		t.entry = *e
+		t.entry.Fields = fields
+		j.e = &t.entry
+	}
+	t.jobs <- j
+
+	timer := time.NewTimer(t.timeout)
+	defer timer.Stop()
+	select {
+	case err := <-t.results:
+		if err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	case <-timer.C:
+		t.stuck = true
+		return 0, ErrWriteTimeout
+	}
+}
+
+func (t *TimeoutWriter) Status() []SinkStatus {
+	t.mutex.Lock()
+	stuck := t.stuck
+	t.mutex.Unlock()
+	s := SinkStatus{Name: "timeout", Connected: true, Failing: stuck}
+	return append([]SinkStatus{s}, writerStatus(t.w)...)
+}
+
+func (t *TimeoutWriter) Flush() error {
+	return flushWriter(t.w)
+}
+
+// Stops the background goroutine, once any stuck write returns, and closes
+// the destination, which may be what unblocks it
+func (t *TimeoutWriter) Close() error {
+	t.mutex.Lock()
+	if t.closed {
+		t.mutex.Unlock()
+		return ErrClosed
+	}
+	t.closed = true
+	close(t.jobs)
+	t.mutex.Unlock()
+	return closeWriter(t.w)
+}
@@ -0,0 +1,70 @@
+package alog
+
+import (
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestTimeoutWriter(c *check.C) {
+	g := newGatedWriter()
+	w := NewTimeoutWriter(g, 10*time.Millisecond)
+
+	var errs []error
+	log := New(w)
+	log.SetFlags(0)
+	log.SetErrorHandler(func(err error) { errs = append(errs, err) })
+
+	log.Print("stuck")
+	c.Assert(errs, check.DeepEquals, []error{ErrWriteTimeout})
+	c.Assert(log.Healthy(), check.Equals, false)
+
+	// Fails fast while the write is stuck; handing it to the stuck worker
+	// would block
+	_, err := w.Write([]byte("fast\n"))
+	c.Assert(err, check.Equals, ErrWriteTimeout)
+
+	// Recovers once it returns
+	close(g.gate)
+	for {
+		if _, err := w.Write([]byte("again\n")); err == nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	log.Print("ok")
+	c.Assert(g.entries()[0], check.Equals, "stuck\n")
+	c.Assert(g.entries()[len(g.entries())-1], check.Equals, "ok\n")
+	c.Assert(log.Healthy(), check.Equals, true)
+
+	c.Assert(w.Close(), check.IsNil)
+	c.Assert(w.Close(), check.Equals, ErrClosed)
+	_, err = w.Write([]byte("x"))
+	c.Assert(err, check.Equals, ErrClosed)
+}
+
+func (s *Suite) TestTimeoutWriterFallback(c *check.C) {
+	g := newGatedWriter()
+	defer close(g.gate)
+	fallback := &Thief{}
+	log := New(NewFallbackWriter(NewTimeoutWriter(g, time.Millisecond), fallback, time.Hour))
+	log.SetFlags(0)
+	log.Print("a")
+	log.Print("b")
+	c.Assert(fallback.msgs, check.DeepEquals, []string{"a\n", "b\n"})
+}
+
+func (s *Suite) TestTimeoutWriterForwards(c *check.C) {
+	lt := &levelThief{}
+	log := New(NewTimeoutWriter(lt, time.Second))
+	log.SetFlags(0)
+	log.Warn("a")
+	c.Assert(lt.levels, check.DeepEquals, []Level{WarnLevel})
+
+	et := &entryThief{}
+	log = New(NewTimeoutWriter(et, time.Second))
+	log.Set("k", "v").Error("b")
+	c.Assert(et.entries, check.HasLen, 1)
+	c.Assert(et.entries[0].Message, check.Equals, "b")
+	c.Assert(et.entries[0].Fields, check.DeepEquals, []Field{{"k", "v"}})
+}
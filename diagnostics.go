@@ -0,0 +1,107 @@
+package alog
+
+import (
+	"bytes"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Logs the state of the process at level: an entry with memory and GC
+// statistics, one per sink with its status (see Status), and one per
+// goroutine with its stack as the message and its id and state as fields.
+func (a *Log) LogDiagnostics(level Level) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	a.output(level, message{fmtString, "memory stats", nil, []Field{
+		{"goroutines", runtime.NumGoroutine()},
+		{"heap_alloc", ms.HeapAlloc},
+		{"heap_inuse", ms.HeapInuse},
+		{"heap_objects", ms.HeapObjects},
+		{"sys", ms.Sys},
+		{"next_gc", ms.NextGC},
+		{"num_gc", ms.NumGC},
+		{"gc_pause_total", time.Duration(ms.PauseTotalNs)},
+	}})
+
+	for _, s := range a.Status() {
+		fields := []Field{
+			{"sink", s.Name},
+			{"connected", s.Connected},
+			{"failing", s.Failing},
+			{"queued", s.Queued},
+			{"dropped", s.Dropped},
+		}
+		if s.LastError != nil {
+			fields = append(fields, Field{"last_error", s.LastError}, Field{"last_error_time", s.LastErrorTime})
+		}
+		a.output(level, message{fmtString, "sink status", nil, fields})
+	}
+
+	for _, g := range goroutineStacks() {
+		a.output(level, message{fmtString, g.stack, nil, []Field{{"goroutine", g.id}, {"state", g.state}}})
+	}
+}
+
+// Logs diagnostics at level, as LogDiagnostics does, whenever one of sigs
+// is received, typically syscall.SIGUSR1 or syscall.SIGQUIT.  Catching
+// SIGQUIT replaces the runtime's dump to stderr and exit.  Call stop to
+// stop handling the signals.
+func (a *Log) DiagnosticsOnSignal(level Level, sigs ...os.Signal) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		for {
+			select {
+			case <-ch:
+				a.LogDiagnostics(level)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+type goroutineStack struct {
+	id    uint64
+	state string
+	stack string
+}
+
+// Splits a dump of all goroutines into goroutines, each starting with a
+// header, "goroutine 18 [chan receive, 2 minutes]:"
+func goroutineStacks() []goroutineStack {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	var stacks []goroutineStack
+	for _, b := range bytes.Split(buf, []byte("\n\n")) {
+		header, stack, _ := bytes.Cut(b, []byte("\n"))
+		header = bytes.TrimPrefix(header, []byte("goroutine "))
+		id, state, _ := bytes.Cut(header, []byte(" "))
+		state = bytes.TrimSuffix(bytes.TrimPrefix(state, []byte("[")), []byte("]:"))
+		n, _ := strconv.ParseUint(string(id), 10, 64)
+		stacks = append(stacks, goroutineStack{n, string(state), string(bytes.TrimRight(stack, "\n"))})
+	}
+	return stacks
+}
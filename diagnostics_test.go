@@ -0,0 +1,36 @@
+package alog
+
+import (
+	"strings"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestLogDiagnostics(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+
+	block := make(chan struct{})
+	defer close(block)
+	go func() { <-block }()
+
+	log.LogDiagnostics(WarnLevel)
+	c.Assert(t.msgs[0], check.Matches, `WARN \[goroutines=\d+ heap_alloc=\d+ .* gc_pause_total=\S+\] memory stats\n`)
+	c.Assert(t.msgs[1], check.Equals, "WARN [sink=*alog.Thief connected=true failing=false queued=0 dropped=0] sink status\n")
+
+	var found bool
+	for _, m := range t.msgs[2:] {
+		c.Assert(m, check.Matches, `(?s)WARN \[goroutine=\d+ state=[^\]]+\] .*\n`)
+		if strings.Contains(m, "alog.(*Suite).TestLogDiagnostics.func") {
+			found = true
+		}
+	}
+	c.Assert(found, check.Equals, true)
+
+	// Disabled level
+	t.msgs = nil
+	log.SetLevel(ErrorLevel)
+	log.LogDiagnostics(InfoLevel)
+	c.Assert(t.msgs, check.HasLen, 0)
+}
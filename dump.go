@@ -0,0 +1,211 @@
+package alog
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// How Dump renders values.  Structs, maps and slices are written one
+// element per line, indented, unless Compact is set.  Values nested deeper
+// than MaxDepth, and elements after the first MaxItems of a map or slice,
+// are elided as "...".  Zero limits are unlimited.
+type DumpFormat struct {
+	Compact  bool
+	MaxDepth int
+	MaxItems int
+}
+
+// Used by Log.Dump.  Set it before logging starts.
+var DefaultDumpFormat = DumpFormat{MaxDepth: 8, MaxItems: 100}
+
+// Writes v, rendered by DefaultDumpFormat, as a debug entry headed by
+// label.  The value is only rendered if debug entries are enabled.
+func (a *Log) Dump(label string, v interface{}) {
+	if a != nil && !a.enabled(DebugLevel) {
+		return
+	}
+	a.output(DebugLevel, message{fmtString, label + ": " + DefaultDumpFormat.Sprint(v), nil, nil})
+}
+
+// Renders v in Go-like syntax, with map keys sorted.
+// Pointers already being rendered are written as <cycle>, so
+// self-referencing values can be dumped.  Errors and fmt.Stringers are
+// rendered as their strings.
+func (f DumpFormat) Sprint(v interface{}) string {
+	d := dumper{f: f, visiting: map[uintptr]bool{}}
+	d.value(reflect.ValueOf(v), 0)
+	return d.b.String()
+}
+
+type dumper struct {
+	f        DumpFormat
+	b        strings.Builder
+	visiting map[uintptr]bool
+}
+
+var (
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+func (d *dumper) value(v reflect.Value, depth int) {
+	if !v.IsValid() {
+		d.b.WriteString("nil")
+		return
+	}
+	if v.CanInterface() && (v.Kind() != reflect.Ptr || !v.IsNil()) {
+		switch {
+		case v.Type().Implements(errorType):
+			d.b.WriteString(strconv.Quote(v.Interface().(error).Error()))
+			return
+		case v.Type().Implements(stringerType):
+			d.b.WriteString(v.Interface().(fmt.Stringer).String())
+			return
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		d.b.WriteString(strconv.Quote(v.String()))
+	case reflect.Bool:
+		d.b.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		d.b.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		d.b.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		d.b.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()))
+	case reflect.Complex64, reflect.Complex128:
+		d.b.WriteString(strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits()))
+	case reflect.Interface:
+		d.value(v.Elem(), depth)
+	case reflect.Ptr:
+		if v.IsNil() {
+			d.b.WriteString("nil")
+			return
+		}
+		if d.enter(v.Pointer()) {
+			defer delete(d.visiting, v.Pointer())
+			d.b.WriteByte('&')
+			d.value(v.Elem(), depth)
+		}
+	case reflect.Struct:
+		d.b.WriteString(v.Type().String())
+		d.open(depth, v.NumField(), func(i int) {
+			d.b.WriteString(v.Type().Field(i).Name)
+			d.b.WriteString(": ")
+			d.value(v.Field(i), depth+1)
+		})
+	case reflect.Map:
+		if v.IsNil() {
+			d.b.WriteString("nil")
+			return
+		}
+		if !d.enter(v.Pointer()) {
+			return
+		}
+		defer delete(d.visiting, v.Pointer())
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		d.b.WriteString(v.Type().String())
+		d.open(depth, len(keys), func(i int) {
+			d.value(keys[i], depth+1)
+			d.b.WriteString(": ")
+			d.value(v.MapIndex(keys[i]), depth+1)
+		})
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice {
+			if v.IsNil() {
+				d.b.WriteString("nil")
+				return
+			}
+			if v.Len() > 0 {
+				if !d.enter(v.Pointer()) {
+					return
+				}
+				defer delete(d.visiting, v.Pointer())
+			}
+		}
+		d.b.WriteString(v.Type().String())
+		d.open(depth, v.Len(), func(i int) {
+			d.value(v.Index(i), depth+1)
+		})
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if v.IsNil() {
+			d.b.WriteString("nil")
+			return
+		}
+		fmt.Fprintf(&d.b, "%s(%#x)", v.Type(), v.Pointer())
+	default:
+		fmt.Fprintf(&d.b, "%v", v)
+	}
+}
+
+// Marks p as being rendered, or writes <cycle> and returns false if it
+// already is
+func (d *dumper) enter(p uintptr) bool {
+	if d.visiting[p] {
+		d.b.WriteString("<cycle>")
+		return false
+	}
+	d.visiting[p] = true
+	return true
+}
+
+// Writes n elements between braces, with elem writing each
+func (d *dumper) open(depth, n int, elem func(i int)) {
+	if n == 0 {
+		d.b.WriteString("{}")
+		return
+	}
+	if d.f.MaxDepth > 0 && depth >= d.f.MaxDepth {
+		d.b.WriteString("{...}")
+		return
+	}
+
+	d.b.WriteByte('{')
+	more := d.f.MaxItems > 0 && n > d.f.MaxItems
+	if more {
+		n = d.f.MaxItems
+	}
+	for i := 0; i < n; i++ {
+		d.separate(depth+1, i == 0)
+		elem(i)
+	}
+	if more {
+		d.separate(depth+1, false)
+		d.b.WriteString("...")
+	}
+	if d.f.Compact {
+		d.b.WriteByte('}')
+		return
+	}
+	d.b.WriteString(",\n")
+	d.indent(depth)
+	d.b.WriteByte('}')
+}
+
+func (d *dumper) separate(depth int, first bool) {
+	if d.f.Compact {
+		if !first {
+			d.b.WriteString(", ")
+		}
+		return
+	}
+	if !first {
+		d.b.WriteByte(',')
+	}
+	d.b.WriteByte('\n')
+	d.indent(depth)
+}
+
+func (d *dumper) indent(depth int) {
+	for i := 0; i < depth; i++ {
+		d.b.WriteString("  ")
+	}
+}
@@ -0,0 +1,60 @@
+package alog
+
+import (
+	"errors"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+type dumpConfig struct {
+	Name    string
+	Timeout time.Duration
+	Tags    []string
+	Limits  map[string]int
+	Err     error
+	Next    *dumpConfig
+	secret  int
+}
+
+func (s *Suite) TestDump(c *check.C) {
+	cfg := &dumpConfig{
+		Name:    "api",
+		Timeout: 5 * time.Second,
+		Tags:    []string{"a", "b"},
+		Limits:  map[string]int{"rps": 10, "burst": 20},
+		Err:     errors.New("bad"),
+		secret:  7,
+	}
+	cfg.Next = cfg
+
+	c.Assert(DumpFormat{Compact: true}.Sprint(cfg), check.Equals,
+		`&alog.dumpConfig{Name: "api", Timeout: 5s, Tags: []string{"a", "b"}, `+
+			`Limits: map[string]int{"burst": 20, "rps": 10}, Err: "bad", Next: <cycle>, secret: 7}`)
+
+	c.Assert(DumpFormat{}.Sprint(map[string][]int{"x": {1}, "y": {}}), check.Equals, `map[string][]int{
+  "x": []int{
+    1,
+  },
+  "y": []int{},
+}`)
+
+	// Limits
+	f := DumpFormat{Compact: true, MaxDepth: 1, MaxItems: 2}
+	c.Assert(f.Sprint([]interface{}{1, []int{2}, 3}), check.Equals, "[]interface {}{1, []int{...}, ...}")
+	c.Assert(f.Sprint(nil), check.Equals, "nil")
+	c.Assert(f.Sprint((*dumpConfig)(nil)), check.Equals, "nil")
+
+	// Entries
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.SetLevel(DebugLevel)
+	log.Dump("limits", map[string]int{"rps": 10})
+	c.Assert(t.last(), check.Equals, "DEBUG limits: map[string]int{\n  \"rps\": 10,\n}\n")
+
+	n := len(t.msgs)
+	log.SetLevel(InfoLevel)
+	log.Dump("skipped", cfg)
+	c.Assert(t.msgs, check.HasLen, n)
+}
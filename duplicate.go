@@ -0,0 +1,58 @@
+package alog
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// What Set does with a key the logger already has.  See
+// Log.SetDuplicatePolicy.
+type DuplicatePolicy int
+
+const (
+	// Replaces the value, keeping the key's position, the default
+	OverwriteDuplicate DuplicatePolicy = iota
+	// Keeps the current value and drops the new one
+	KeepFirst
+	// Adds the new value under the key with the first free suffix, e.g.
+	// user.2, then user.3
+	SuffixDuplicate
+	// Keeps the current value and reports the key to the error handler
+	ReportDuplicate
+)
+
+// Sets what Set and SetFields do with keys the logger already has,
+// including keys copied from the logger With was called on, so layers of
+// middleware can't silently clobber each other's fields.  Copies keep the
+// setting.
+func (a *Log) SetDuplicatePolicy(p DuplicatePolicy) *Log {
+	if a == nil {
+		return nil
+	}
+	a.duplicates = p
+	return a
+}
+
+// Sets k to v, unless k exists, in which case p decides.  Returns an error
+// for ReportDuplicate.
+func (m *Meta) setUnique(k string, v interface{}, p DuplicatePolicy) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.entries[k]; ok {
+		switch p {
+		case KeepFirst:
+			return nil
+		case ReportDuplicate:
+			return fmt.Errorf("alog: duplicate key %q", k)
+		case SuffixDuplicate:
+			base := k
+			for n := 2; ok; n++ {
+				k = base + "." + strconv.Itoa(n)
+				_, ok = m.entries[k]
+			}
+		}
+	}
+	m.setLocked(k, v)
+	return nil
+}
@@ -0,0 +1,29 @@
+package alog
+
+import "gopkg.in/check.v1"
+
+func (s *Suite) TestDuplicatePolicy(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	var errs []string
+	log.SetErrorHandler(func(err error) { errs = append(errs, err.Error()) })
+	log.Set("user", "alice")
+
+	log.SetDuplicatePolicy(KeepFirst)
+	log.With("user", "bob").Print("x")
+	checkLast(c, t, "[user=alice] x")
+
+	log.SetDuplicatePolicy(SuffixDuplicate)
+	log.With("user", "bob").Set("user", "carol").Print("x")
+	checkLast(c, t, "[user=alice user.2=bob user.3=carol] x")
+
+	log.SetDuplicatePolicy(ReportDuplicate)
+	log.SetFields(Field{"user", "bob"}, Field{"id", 1}).Print("x")
+	checkLast(c, t, "[user=alice id=1] x")
+	c.Assert(errs, check.DeepEquals, []string{`alog: duplicate key "user"`})
+
+	log.SetDuplicatePolicy(OverwriteDuplicate)
+	log.Set("user", "bob").Print("x")
+	checkLast(c, t, "[user=bob id=1] x")
+}
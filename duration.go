@@ -0,0 +1,60 @@
+package alog
+
+import (
+	"strconv"
+	"time"
+)
+
+// How Dur and Since render durations: as a plain number of Units, with
+// Precision decimals, so log parsers can aggregate them.  1234567ns with
+// {time.Millisecond, 3} renders as 1.235.
+type DurationFormat struct {
+	Unit      time.Duration
+	Precision int
+}
+
+// Used by the package-level Dur and Since.  Set it before logging starts.
+var DefaultDurationFormat = DurationFormat{time.Millisecond, 3}
+
+// Returns a field rendering d according to f
+func (f DurationFormat) Dur(k string, d time.Duration) Field {
+	return Field{k, durationValue{d, f}}
+}
+
+// Returns a field rendering the time elapsed since start according to f
+func (f DurationFormat) Since(k string, start time.Time) Field {
+	return f.Dur(k, time.Since(start))
+}
+
+// Returns a field rendering d as a number of milliseconds, or as set by
+// DefaultDurationFormat
+func Dur(k string, d time.Duration) Field {
+	return DefaultDurationFormat.Dur(k, d)
+}
+
+// Returns a field rendering the time elapsed since start, as Dur does
+func Since(k string, start time.Time) Field {
+	return DefaultDurationFormat.Since(k, start)
+}
+
+type durationValue struct {
+	d time.Duration
+	f DurationFormat
+}
+
+func (v durationValue) String() string {
+	return string(v.append(nil))
+}
+
+// Encoded as a JSON number
+func (v durationValue) MarshalJSON() ([]byte, error) {
+	return v.append(nil), nil
+}
+
+func (v durationValue) append(b []byte) []byte {
+	unit := v.f.Unit
+	if unit <= 0 {
+		unit = time.Nanosecond
+	}
+	return strconv.AppendFloat(b, float64(v.d)/float64(unit), 'f', v.f.Precision, 64)
+}
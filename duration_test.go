@@ -0,0 +1,29 @@
+package alog
+
+import (
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestDur(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+
+	log.WithFields(Dur("latency", 1234567*time.Nanosecond)).Print("done")
+	checkLast(c, t, "[latency=1.235] done")
+
+	secs := DurationFormat{time.Second, 1}
+	log.Info().Fields(secs.Dur("took", 90*time.Second), Dur("zero", 0)).Msg("done")
+	checkLast(c, t, "[took=90.0 zero=0.000] done")
+
+	f := Since("elapsed", time.Now().Add(-time.Second))
+	c.Assert(f.Key, check.Equals, "elapsed")
+	c.Assert(f.Value.(durationValue).d >= time.Second, check.Equals, true)
+
+	// Numbers in JSON
+	log.SetFormatter(JSONFormatter{})
+	log.WithFields(Dur("latency", 2*time.Millisecond)).Print("done")
+	checkLast(c, t, `{"level":"info","msg":"done","latency":2.000}`)
+}
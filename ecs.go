@@ -0,0 +1,89 @@
+package alog
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ECS version recorded in ecs.version
+const ecsVersion = "8.11.0"
+
+// Formats entries in the Elastic Common Schema, as the ecs-logging
+// libraries do, so Elasticsearch needs no ingest pipeline:
+//
+//	{"@timestamp":"...","log.level":"error","message":"failed","ecs.version":"8.11.0","error":{"message":"timeout","type":"*net.OpError"},"labels":{"user":"alice"}}
+//
+// An error under the "error" field becomes error.message and error.type,
+// and a "stack" field error.stack_trace.  Other fields become labels, which
+// are strings; groups are flattened with underscores, since label names
+// can't contain dots.  @timestamp and log.origin are included when the
+// logger's flags ask for them.
+type ECSFormatter struct{}
+
+func (ECSFormatter) Format(b []byte, e *Entry) []byte {
+	var stack interface{}
+	var err error
+	labels := make([]Field, 0, len(e.Fields))
+	for _, f := range flattenFields(e.Fields) {
+		switch f.Key {
+		case "stack":
+			stack = f.Value
+			continue
+		case "error":
+			if x, ok := f.Value.(error); ok {
+				err = x
+				continue
+			}
+		}
+		labels = append(labels, f)
+	}
+
+	b = append(b, '{')
+	if !e.Time.IsZero() {
+		b = append(b, `"@timestamp":"`...)
+		b = e.Time.UTC().AppendFormat(b, "2006-01-02T15:04:05.000Z")
+		b = append(b, `",`...)
+	}
+	b = append(b, `"log.level":`...)
+	b = appendJSONString(b, e.Level.String())
+	b = append(b, `,"message":`...)
+	b = appendJSONString(b, e.Message)
+	b = append(b, `,"ecs.version":"`+ecsVersion+`"`...)
+	if e.File != "" {
+		b = append(b, `,"log":{"origin":{"file":{"name":`...)
+		b = appendJSONString(b, e.File)
+		b = append(b, `,"line":`...)
+		b = strconv.AppendInt(b, int64(e.Line), 10)
+		b = append(b, `}}}`...)
+	}
+	if err != nil || stack != nil {
+		b = append(b, `,"error":{`...)
+		if err != nil {
+			b = append(b, `"message":`...)
+			b = appendJSONString(b, err.Error())
+			b = append(b, `,"type":`...)
+			b = appendJSONString(b, errorTypeName(err))
+		}
+		if stack != nil {
+			if err != nil {
+				b = append(b, ',')
+			}
+			b = append(b, `"stack_trace":`...)
+			b = appendJSONString(b, string(appendValue(nil, stack)))
+		}
+		b = append(b, '}')
+	}
+	if len(labels) > 0 {
+		b = append(b, `,"labels":{`...)
+		for i, f := range labels {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendJSONString(b, strings.ReplaceAll(f.Key, ".", "_"))
+			b = append(b, ':')
+			b = appendJSONString(b, string(appendValue(nil, f.Value)))
+		}
+		b = append(b, '}')
+	}
+	return append(b, '}')
+}
@@ -0,0 +1,37 @@
+package alog
+
+import (
+	"errors"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestECSFormatter(c *check.C) {
+	e := &Entry{
+		Time:    time.Date(2020, 1, 2, 3, 4, 5, 6000000, time.FixedZone("X", 3600)),
+		Level:   ErrorLevel,
+		Message: "failed",
+		Fields: []Field{
+			{"user", "alice"},
+			{"http", Group("status", 500)},
+			{"error", errors.New("timeout")},
+			{"stack", "main.go:12"},
+		},
+		File: "main.go",
+		Line: 12,
+	}
+	c.Assert(string(ECSFormatter{}.Format(nil, e)), check.Equals,
+		`{"@timestamp":"2020-01-02T02:04:05.006Z","log.level":"error","message":"failed","ecs.version":"8.11.0",`+
+			`"log":{"origin":{"file":{"name":"main.go","line":12}}},`+
+			`"error":{"message":"timeout","type":"*errors.errorString","stack_trace":"main.go:12"},`+
+			`"labels":{"user":"alice","http_status":"500"}}`)
+
+	e = &Entry{Level: InfoLevel, Message: "m"}
+	c.Assert(string(ECSFormatter{}.Format(nil, e)), check.Equals,
+		`{"log.level":"info","message":"m","ecs.version":"8.11.0"}`)
+
+	log, err := Build(Config{Format: "ecs"})
+	c.Assert(err, check.IsNil)
+	c.Assert(log.formatter.get(), check.Equals, ECSFormatter{})
+}
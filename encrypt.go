@@ -0,0 +1,150 @@
+package alog
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Frame types in an encrypted log stream
+const (
+	frameKey  = 'K'
+	frameData = 'D'
+)
+
+// Writer that encrypts each write for an X25519 public key before passing it
+// to the underlying writer.  Only the holder of the private key can read the
+// output back, with Decrypt.  A fresh ephemeral key is used per EncryptWriter,
+// so several writers may append to the same file.
+type EncryptWriter struct {
+	w      io.Writer
+	header []byte
+	aead   cipher.AEAD
+	seq    uint64
+	mutex  sync.Mutex
+}
+
+func NewEncryptWriter(w io.Writer, pub *ecdh.PublicKey) (*EncryptWriter, error) {
+	if pub.Curve() != ecdh.X25519() {
+		return nil, errors.New("alog: encryption key must be X25519")
+	}
+	eph, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := eph.ECDH(pub)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newLogAEAD(shared, eph.PublicKey().Bytes(), pub.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptWriter{
+		w:      w,
+		header: appendFrame(nil, frameKey, eph.PublicKey().Bytes()),
+		aead:   aead,
+	}, nil
+}
+
+func (e *EncryptWriter) Write(p []byte) (int, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	// The key frame is written ahead of the first entry
+	buf := e.header
+	sealed := e.aead.Seal(nil, logNonce(e.seq), p, nil)
+	buf = appendFrame(buf, frameData, sealed)
+
+	if _, err := e.w.Write(buf); err != nil {
+		return 0, err
+	}
+	e.header = nil
+	e.seq++
+	return len(p), nil
+}
+
+// Decrypts a stream written by one or more EncryptWriters, writing the
+// plaintext log to dst.
+func Decrypt(dst io.Writer, src io.Reader, priv *ecdh.PrivateKey) error {
+	var aead cipher.AEAD
+	var seq uint64
+	hdr := make([]byte, 5)
+	for {
+		if _, err := io.ReadFull(src, hdr); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		payload := make([]byte, binary.BigEndian.Uint32(hdr[1:]))
+		if _, err := io.ReadFull(src, payload); err != nil {
+			return err
+		}
+
+		switch hdr[0] {
+		case frameKey:
+			eph, err := ecdh.X25519().NewPublicKey(payload)
+			if err != nil {
+				return err
+			}
+			shared, err := priv.ECDH(eph)
+			if err != nil {
+				return err
+			}
+			aead, err = newLogAEAD(shared, payload, priv.PublicKey().Bytes())
+			if err != nil {
+				return err
+			}
+			seq = 0
+		case frameData:
+			if aead == nil {
+				return errors.New("alog: encrypted log has data before a key")
+			}
+			p, err := aead.Open(nil, logNonce(seq), payload, nil)
+			if err != nil {
+				return fmt.Errorf("alog: encrypted log entry %d: %v", seq, err)
+			}
+			if _, err := dst.Write(p); err != nil {
+				return err
+			}
+			seq++
+		default:
+			return fmt.Errorf("alog: unknown encrypted log frame %q", hdr[0])
+		}
+	}
+}
+
+func newLogAEAD(shared, ephPub, pub []byte) (cipher.AEAD, error) {
+	h := sha256.New()
+	h.Write([]byte("alog"))
+	h.Write(shared)
+	h.Write(ephPub)
+	h.Write(pub)
+	block, err := aes.NewCipher(h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Nonces are a counter; each writer has its own ephemeral key so they are
+// never reused
+func logNonce(seq uint64) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[4:], seq)
+	return nonce
+}
+
+func appendFrame(b []byte, typ byte, payload []byte) []byte {
+	b = append(b, typ, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(b[len(b)-4:], uint32(len(payload)))
+	return append(b, payload...)
+}
@@ -0,0 +1,53 @@
+package alog
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestEncrypt(c *check.C) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	c.Assert(err, check.IsNil)
+
+	var buf bytes.Buffer
+	w, err := NewEncryptWriter(&buf, priv.PublicKey())
+	c.Assert(err, check.IsNil)
+	log := New(w)
+	log.SetFlags(0)
+	log.Set("user", "alice")
+	log.Print("one")
+	log.Print("two")
+	c.Assert(bytes.Contains(buf.Bytes(), []byte("alice")), check.Equals, false)
+
+	// A second writer appending to the same stream
+	w, err = NewEncryptWriter(&buf, priv.PublicKey())
+	c.Assert(err, check.IsNil)
+	log = New(w)
+	log.SetFlags(0)
+	log.Print("three")
+
+	var out bytes.Buffer
+	c.Assert(Decrypt(&out, bytes.NewReader(buf.Bytes()), priv), check.IsNil)
+	c.Assert(out.String(), check.Equals, "[user=alice] one\n[user=alice] two\nthree\n")
+
+	// Wrong key
+	other, err := ecdh.X25519().GenerateKey(rand.Reader)
+	c.Assert(err, check.IsNil)
+	err = Decrypt(&out, bytes.NewReader(buf.Bytes()), other)
+	c.Assert(err, check.ErrorMatches, "alog: encrypted log entry 0: .*")
+
+	// Tampering
+	b := buf.Bytes()
+	b[len(b)-1] ^= 1
+	err = Decrypt(&out, bytes.NewReader(b), priv)
+	c.Assert(err, check.ErrorMatches, "alog: encrypted log entry 0: .*")
+
+	// Only X25519 keys
+	p256, err := ecdh.P256().GenerateKey(rand.Reader)
+	c.Assert(err, check.IsNil)
+	_, err = NewEncryptWriter(&buf, p256.PublicKey())
+	c.Assert(err, check.ErrorMatches, "alog: encryption key must be X25519")
+}
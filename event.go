@@ -0,0 +1,155 @@
+package alog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Single entry built up with typed fields, then written by Msg:
+//
+//	log.Info().Str("user", u).Int("count", n).Err(err).Msg("done")
+//
+// The fields belong to the entry alone; the logger's Meta is not copied.
+// Events for disabled levels are nil, and every method on a nil Event does
+// nothing, so building one costs little when it won't be written.  An Event
+// must not be used after Msg, Msgf or Send.
+type Event struct {
+	log    *Log
+	level  Level
+	fields []Field
+}
+
+var eventPool = sync.Pool{
+	New: func() interface{} {
+		return &Event{fields: make([]Field, 0, 8)}
+	},
+}
+
+// Starts an entry at level.  Returns nil if the level is disabled, or the
+// writer is io.Discard.
+func (a *Log) At(level Level) *Event {
+	if a != nil && level < PanicLevel && ((level < a.level.Level() && a.trace == nil) || discards(a.Logger.Writer())) {
+		return nil
+	}
+	e := eventPool.Get().(*Event)
+	e.log = a
+	e.level = level
+	return e
+}
+
+// Shorthand for At(InfoLevel).  The other levels' names are taken by the
+// Print-style methods, so use At for them.
+func (a *Log) Info() *Event {
+	return a.At(InfoLevel)
+}
+
+func (e *Event) add(k string, v interface{}) *Event {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, Field{k, v})
+	return e
+}
+
+func (e *Event) Str(k, v string) *Event {
+	return e.add(k, v)
+}
+
+func (e *Event) Int(k string, v int) *Event {
+	return e.add(k, v)
+}
+
+func (e *Event) Int64(k string, v int64) *Event {
+	return e.add(k, v)
+}
+
+func (e *Event) Uint64(k string, v uint64) *Event {
+	return e.add(k, v)
+}
+
+func (e *Event) Float64(k string, v float64) *Event {
+	return e.add(k, v)
+}
+
+func (e *Event) Bool(k string, v bool) *Event {
+	return e.add(k, v)
+}
+
+func (e *Event) Dur(k string, v time.Duration) *Event {
+	return e.add(k, v)
+}
+
+func (e *Event) Time(k string, v time.Time) *Event {
+	return e.add(k, v)
+}
+
+// Adds v as is, formatted like a value passed to Set
+func (e *Event) Any(k string, v interface{}) *Event {
+	return e.add(k, v)
+}
+
+// Adds fields, such as those from Dur and Since
+func (e *Event) Fields(fields ...Field) *Event {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, fields...)
+	return e
+}
+
+// Adds err under "error".  Does nothing if err is nil.
+func (e *Event) Err(err error) *Event {
+	if err == nil {
+		return e
+	}
+	return e.add("error", err)
+}
+
+// Writes the entry with msg as its message
+func (e *Event) Msg(msg string) {
+	if e == nil {
+		return
+	}
+	m := message{fmtString, msg, nil, e.fields}
+	e.log.output(e.level, m)
+	e.done(m.f)
+}
+
+func (e *Event) Msgf(f string, v ...interface{}) {
+	if e == nil {
+		return
+	}
+	m := message{fmtString, fmt.Sprintf(f, v...), nil, e.fields}
+	e.log.output(e.level, m)
+	e.done(m.f)
+}
+
+// Writes the entry without a message
+func (e *Event) Send() {
+	if e == nil {
+		return
+	}
+	m := message{fmtString, "", nil, e.fields}
+	e.log.output(e.level, m)
+	e.done(m.f)
+}
+
+// Returns the written Event to the pool.  Panic entries then panic with
+// the message, and Fatal entries exit, as Log.Panic and Log.Fatal do.
+func (e *Event) done(msg string) {
+	level, log := e.level, e.log
+	for i := range e.fields {
+		e.fields[i] = Field{}
+	}
+	e.fields = e.fields[:0]
+	e.log = nil
+	eventPool.Put(e)
+
+	switch level {
+	case PanicLevel:
+		panic(msg)
+	case FatalLevel:
+		log.exit()
+	}
+}
@@ -0,0 +1,64 @@
+package alog
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	stdlog "log"
+	"testing"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestEvent(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("app", "api")
+
+	log.Info().Str("user", "alice").Int("count", 3).Bool("ok", true).
+		Dur("took", 2*time.Second).Err(errors.New("partial")).Msg("done")
+	checkLast(c, t, "[app=api user=alice count=3 ok=true took=2s error=partial] done")
+
+	// Fields belong to the entry alone
+	log.Info().Err(nil).Msgf("n=%d", 1)
+	checkLast(c, t, "[app=api] n=1")
+	c.Assert(log.Meta.fields(), check.HasLen, 1)
+
+	log.At(WarnLevel).Float64("load", 0.5).Send()
+	checkLast(c, t, "WARN [app=api load=0.5] ")
+
+	// Disabled levels give a nil Event
+	n := len(t.msgs)
+	c.Assert(log.At(DebugLevel), check.IsNil)
+	log.At(DebugLevel).Str("k", "v").Msg("hidden")
+	c.Assert(t.msgs, check.HasLen, n)
+
+	c.Assert(func() { log.At(PanicLevel).Str("k", "v").Msg("boom") }, check.PanicMatches, "boom")
+	checkLast(c, t, "PANIC [app=api k=v] boom")
+
+	// Caller is the Msg call site
+	log.SetFlags(stdlog.Lshortfile)
+	log.Info().Msg("here")
+	c.Assert(t.last(), check.Matches, `event_test\.go:\d+: \[app=api\] here\n`)
+}
+
+func (s *Suite) TestEventNil(c *check.C) {
+	var log *Log
+	defer func(f *stdlog.Logger) { fallback = f }(fallback)
+	t := &Thief{}
+	fallback = stdlog.New(t, "", 0)
+
+	log.Info().Str("k", "v").Msg("hello")
+	checkLast(c, t, "hello")
+}
+
+func BenchmarkEvent(b *testing.B) {
+	log := New(struct{ io.Writer }{ioutil.Discard})
+	log.Set("app", "api")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		log.Info().Str("user", "alice").Int("count", 3).Msg("done")
+	}
+}
@@ -0,0 +1,95 @@
+package alog
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Machine-readable identifier of an event, such as USER_LOGIN, written
+// under "event" alongside the free-text message.  Register codes with
+// RegisterEvent, at init, so they can be enumerated and mistakes are caught
+// at startup:
+//
+//	var UserLogin = alog.RegisterEvent("USER_LOGIN")
+//
+//	log.Info().Code(UserLogin).Str("user", u).Msg("logged in")
+//
+// Codes can only be made by RegisterEvent and LookupEvent.  The zero
+// EventCode is no code.
+type EventCode struct {
+	name string
+}
+
+var eventCodes = struct {
+	m     map[string]bool
+	mutex sync.RWMutex
+}{m: map[string]bool{}}
+
+// Registers code and returns it.  Panics if code is already registered, or
+// isn't upper case letters, digits and underscores starting with a letter,
+// so that a duplicate or misspelt code stops the program at init.
+func RegisterEvent(code string) EventCode {
+	if !validEventCode(code) {
+		panic(fmt.Sprintf("alog: invalid event code %q", code))
+	}
+	eventCodes.mutex.Lock()
+	defer eventCodes.mutex.Unlock()
+	if eventCodes.m[code] {
+		panic(fmt.Sprintf("alog: event code %s registered twice", code))
+	}
+	eventCodes.m[code] = true
+	return EventCode{code}
+}
+
+// Returns the registered code, or the zero EventCode and false if code
+// isn't registered
+func LookupEvent(code string) (EventCode, bool) {
+	eventCodes.mutex.RLock()
+	defer eventCodes.mutex.RUnlock()
+	if !eventCodes.m[code] {
+		return EventCode{}, false
+	}
+	return EventCode{code}, true
+}
+
+// Returns the registered codes, sorted, e.g. for documenting them
+func Events() []EventCode {
+	eventCodes.mutex.RLock()
+	codes := make([]EventCode, 0, len(eventCodes.m))
+	for c := range eventCodes.m {
+		codes = append(codes, EventCode{c})
+	}
+	eventCodes.mutex.RUnlock()
+	sort.Slice(codes, func(i, j int) bool { return codes[i].name < codes[j].name })
+	return codes
+}
+
+func (c EventCode) String() string {
+	return c.name
+}
+
+// Returns the code as an event field, for PrintWith and SetFields
+func (c EventCode) Field() Field {
+	return Field{"event", c.name}
+}
+
+// Adds the code under "event", unless it is the zero EventCode
+func (e *Event) Code(c EventCode) *Event {
+	if c.name == "" {
+		return e
+	}
+	return e.add("event", c.name)
+}
+
+func validEventCode(s string) bool {
+	if s == "" || s[0] < 'A' || s[0] > 'Z' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
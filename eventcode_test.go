@@ -0,0 +1,42 @@
+package alog
+
+import (
+	"sort"
+
+	"gopkg.in/check.v1"
+)
+
+var testUserLogin = RegisterEvent("TEST_USER_LOGIN")
+
+func (s *Suite) TestEventCode(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+
+	log.Info().Code(testUserLogin).Str("user", "alice").Msg("logged in")
+	checkLast(c, t, "[event=TEST_USER_LOGIN user=alice] logged in")
+	log.PrintWith([]Field{testUserLogin.Field()}, "again")
+	checkLast(c, t, "[event=TEST_USER_LOGIN] again")
+
+	code, ok := LookupEvent("TEST_USER_LOGIN")
+	c.Assert(ok, check.Equals, true)
+	c.Assert(code, check.Equals, testUserLogin)
+	code, ok = LookupEvent("TEST_USER_LOGNI")
+	c.Assert(ok, check.Equals, false)
+	c.Assert(code, check.Equals, EventCode{})
+	log.Info().Code(code).Msg("unknown")
+	checkLast(c, t, "unknown")
+
+	// Other tests may register codes too
+	codes := Events()
+	c.Assert(sort.SliceIsSorted(codes, func(i, j int) bool { return codes[i].String() < codes[j].String() }), check.Equals, true)
+	found := false
+	for _, code := range codes {
+		found = found || code == testUserLogin
+	}
+	c.Assert(found, check.Equals, true)
+
+	c.Assert(func() { RegisterEvent("TEST_USER_LOGIN") }, check.PanicMatches, `alog: event code TEST_USER_LOGIN registered twice`)
+	c.Assert(func() { RegisterEvent("user_login") }, check.PanicMatches, `alog: invalid event code "user_login"`)
+	c.Assert(func() { RegisterEvent("") }, check.PanicMatches, `alog: invalid event code ""`)
+}
@@ -0,0 +1,49 @@
+package alog
+
+import "sync"
+
+// Makes Fatal, Fatalf, Fatalln and Must end only the calling goroutine,
+// with runtime.Goexit, instead of exiting the process.  Deferred calls run,
+// so locks are released and temporary files removed, and a test can log
+// fatally from a goroutine and check what happened, as with
+// testing.T.FailNow.  The exit hooks don't run, since the process doesn't
+// exit.  If the main goroutine calls Fatal, the program crashes once the
+// other goroutines finish.  Copies keep the setting.
+func (a *Log) SetFatalGoexit(enabled bool) *Log {
+	if a == nil {
+		return nil
+	}
+	a.fatalGoexit = enabled
+	return a
+}
+
+var exitHooks struct {
+	hooks []func()
+	mutex sync.Mutex
+}
+
+// Registers h to run when Fatal, Fatalf, Fatalln or Must exits, before the
+// process does, e.g. to report to an error tracker or write a crash marker.
+// Hooks run in the order registered, after the logger's writer has been
+// flushed.  A hook that panics is skipped, so the others still run and the
+// process still exits.
+func RegisterExitHook(h func()) {
+	exitHooks.mutex.Lock()
+	defer exitHooks.mutex.Unlock()
+	exitHooks.hooks = append(exitHooks.hooks, h)
+}
+
+func runExitHooks() {
+	exitHooks.mutex.Lock()
+	hooks := make([]func(), len(exitHooks.hooks))
+	copy(hooks, exitHooks.hooks)
+	exitHooks.mutex.Unlock()
+	for _, h := range hooks {
+		runExitHook(h)
+	}
+}
+
+func runExitHook(h func()) {
+	defer func() { recover() }()
+	h()
+}
@@ -0,0 +1,53 @@
+package alog
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestExitHooks(c *check.C) {
+	defer func(hooks []func()) { exitHooks.hooks = hooks }(exitHooks.hooks)
+	exitHooks.hooks = nil
+
+	var calls []string
+	w := &flushCloser{}
+	defer func(f func(int)) { osExit = f }(osExit)
+	osExit = func(code int) { calls = append(calls, "exit") }
+
+	RegisterExitHook(func() {
+		c.Assert(w.flushed, check.Equals, 1)
+		calls = append(calls, "a")
+	})
+	RegisterExitHook(func() { panic("b") })
+	RegisterExitHook(func() { calls = append(calls, "c") })
+
+	log := New(w)
+	log.Print("x")
+	c.Assert(calls, check.HasLen, 0)
+	log.Fatal("y")
+	c.Assert(calls, check.DeepEquals, []string{"a", "c", "exit"})
+	c.Assert(w.flushed, check.Equals, 1)
+}
+
+func (s *Suite) TestFatalGoexit(c *check.C) {
+	var codes []int
+	defer func(f func(int)) { osExit = f }(osExit)
+	osExit = func(code int) { codes = append(codes, code) }
+
+	t := &Thief{}
+	log := New(t).SetFatalGoexit(true)
+	log.SetFlags(0)
+
+	var deferred, after bool
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() { deferred = true }()
+		log.Copy().Fatal("x")
+		after = true
+	}()
+	<-done
+	c.Assert(deferred, check.Equals, true)
+	c.Assert(after, check.Equals, false)
+	c.Assert(codes, check.HasLen, 0)
+	checkLast(c, t, "FATAL x")
+}
@@ -0,0 +1,106 @@
+package alog
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Writer that sends entries to a fallback, such as stderr or a spill file,
+// while its primary destination is failing.  After a failed write, the
+// primary is tried again once retryAfter has passed.  An error is only
+// returned if the fallback fails too.  Entries reach LevelWriter and
+// EntryWriter destinations as they would without the FallbackWriter.
+type FallbackWriter struct {
+	primary    io.Writer
+	fallback   io.Writer
+	retryAfter time.Duration
+	failedAt   time.Time
+	now        func() time.Time
+	mutex      sync.Mutex
+}
+
+func NewFallbackWriter(primary, fallback io.Writer, retryAfter time.Duration) *FallbackWriter {
+	return &FallbackWriter{
+		primary:    primary,
+		fallback:   fallback,
+		retryAfter: retryAfter,
+		now:        time.Now,
+	}
+}
+
+func (f *FallbackWriter) Write(p []byte) (int, error) {
+	return f.write(InfoLevel, nil, p)
+}
+
+func (f *FallbackWriter) WriteLevel(level Level, p []byte) (int, error) {
+	return f.write(level, nil, p)
+}
+
+func (f *FallbackWriter) WriteEntry(e *Entry, p []byte) (int, error) {
+	return f.write(e.Level, e, p)
+}
+
+// The logger only builds entries when either destination is an EntryWriter
+func (f *FallbackWriter) wantsEntries() bool {
+	_, primary := f.primary.(EntryWriter)
+	_, fallback := f.fallback.(EntryWriter)
+	return primary || fallback
+}
+
+func (f *FallbackWriter) write(level Level, e *Entry, p []byte) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.failedAt.IsZero() || f.now().Sub(f.failedAt) >= f.retryAfter {
+		if err := forward(f.primary, level, e, p); err == nil {
+			f.failedAt = time.Time{}
+			return len(p), nil
+		}
+		f.failedAt = f.now()
+	}
+	if err := forward(f.fallback, level, e, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Passes an entry to w as the logger would, given the entry if it was built
+func forward(w io.Writer, level Level, e *Entry, p []byte) error {
+	ew, _ := w.(EntryWriter)
+	if e == nil {
+		ew = nil
+	}
+	return dispatch(w, ew, level, e, p)
+}
+
+// Reports whether entries are currently going to the fallback
+func (f *FallbackWriter) Failing() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return !f.failedAt.IsZero()
+}
+
+// Reports the fallback as failing while it is in use, followed by the
+// primary and fallback writers' statuses
+func (f *FallbackWriter) Status() []SinkStatus {
+	s := []SinkStatus{{Name: "fallback", Connected: true, Failing: f.Failing()}}
+	s = append(s, writerStatus(f.primary)...)
+	return append(s, writerStatus(f.fallback)...)
+}
+
+func (f *FallbackWriter) Flush() error {
+	err := flushWriter(f.primary)
+	if ferr := flushWriter(f.fallback); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+func (f *FallbackWriter) Close() error {
+	err := closeWriter(f.primary)
+	if cerr := closeWriter(f.fallback); err == nil {
+		err = cerr
+	}
+	return err
+}
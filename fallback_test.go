@@ -0,0 +1,55 @@
+package alog
+
+import (
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestFallbackWriter(c *check.C) {
+	primary := &flakyWriter{failures: 2}
+	spill := &Thief{}
+	f := NewFallbackWriter(primary, spill, time.Minute)
+	now := time.Now()
+	f.now = func() time.Time { return now }
+
+	log := New(f)
+	log.SetFlags(0)
+	log.Print("one")
+	c.Assert(spill.msgs, check.DeepEquals, []string{"one\n"})
+	c.Assert(f.Failing(), check.Equals, true)
+
+	// The primary isn't retried until retryAfter has passed
+	log.Print("two")
+	c.Assert(spill.msgs, check.DeepEquals, []string{"one\n", "two\n"})
+
+	// Retried and still failing
+	now = now.Add(time.Minute)
+	log.Print("three")
+	c.Assert(spill.msgs, check.HasLen, 3)
+	c.Assert(primary.failures, check.Equals, 0)
+
+	// Recovers
+	now = now.Add(time.Minute)
+	log.Print("four")
+	c.Assert(f.Failing(), check.Equals, false)
+	c.Assert(spill.msgs, check.HasLen, 3)
+	c.Assert(primary.last(), check.Equals, "four\n")
+}
+
+func (s *Suite) TestFallbackWriterForwards(c *check.C) {
+	primary := &flakyEntryWriter{failures: 1}
+	spill := &levelThief{}
+	f := NewFallbackWriter(primary, spill, 0)
+	log := New(f)
+	log.SetFlags(0)
+
+	log.Warn("a")
+	c.Assert(primary.entries, check.HasLen, 0)
+	c.Assert(spill.levels, check.DeepEquals, []Level{WarnLevel})
+
+	log.Error("b")
+	c.Assert(primary.entries, check.HasLen, 1)
+	c.Assert(primary.entries[0].Message, check.Equals, "b")
+	c.Assert(primary.entries[0].Level, check.Equals, ErrorLevel)
+}
@@ -0,0 +1,160 @@
+package alog
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+const badKey = "!BADKEY"
+
+// Key-value pair
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Supplies fields that are evaluated each time an entry is written, for
+// values that change over the logger's lifetime.  See Log.AddFieldProvider.
+type FieldProvider interface {
+	Fields() []Field
+}
+
+// Adapts a function to a FieldProvider
+type FieldProviderFunc func() []Field
+
+func (f FieldProviderFunc) Fields() []Field {
+	return f()
+}
+
+// Set of fields nested under a single key.  Created with Group.
+type GroupValue []Field
+
+// Groups alternating keys and values under one key:
+//
+//	log.Set("http", alog.Group("method", "GET", "status", 200))
+//
+// renders as http.method=GET http.status=200.  Groups may be nested.
+// A trailing key without a value is stored under !BADKEY.
+func Group(kv ...interface{}) GroupValue {
+	g := make(GroupValue, 0, (len(kv)+1)/2)
+	for len(kv) > 0 {
+		if len(kv) == 1 {
+			g = append(g, Field{badKey, kv[0]})
+			break
+		}
+		k, ok := kv[0].(string)
+		if !ok {
+			k = fmt.Sprint(kv[0])
+		}
+		g = append(g, Field{k, kv[1]})
+		kv = kv[2:]
+	}
+	return g
+}
+
+// Joins the formatted fields with delim and wraps them in format, if given
+func formatFields(fields []Field, delim, format string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	s := string(appendFields(nil, fields, delim))
+	if format == "" {
+		return s
+	}
+	return fmt.Sprintf(format, s)
+}
+
+// Appends k=v for each field, separated by delim
+func appendFields(b []byte, fields []Field, delim string) []byte {
+	for i, f := range fields {
+		if i > 0 {
+			b = append(b, delim...)
+		}
+		b = appendField(b, "", f.Key, f.Value, delim)
+	}
+	return b
+}
+
+// Returns the value to render for v.  Values are only formatted when an
+// entry is written, so a slog.LogValuer is resolved then too, and
+// fmt.Stringers are called then by fmt.
+func resolveValue(v interface{}) interface{} {
+	lv, ok := v.(slog.LogValuer)
+	if !ok {
+		return v
+	}
+	return slogValue(slog.AnyValue(lv).Resolve())
+}
+
+func slogValue(v slog.Value) interface{} {
+	if v.Kind() != slog.KindGroup {
+		return v.Any()
+	}
+	attrs := v.Group()
+	g := make(GroupValue, len(attrs))
+	for i, a := range attrs {
+		g[i] = Field{a.Key, slogValue(a.Value.Resolve())}
+	}
+	return g
+}
+
+// Returns fields with groups expanded into dotted keys, and values
+// resolved, as they appear in the text prefix
+func flattenFields(fields []Field) []Field {
+	return appendFlattened(make([]Field, 0, len(fields)), "", fields)
+}
+
+func appendFlattened(out []Field, group string, fields []Field) []Field {
+	for _, f := range fields {
+		v := resolveValue(f.Value)
+		if g, ok := v.(GroupValue); ok {
+			out = appendFlattened(out, group+f.Key+".", g)
+			continue
+		}
+		out = append(out, Field{group + f.Key, v})
+	}
+	return out
+}
+
+// Appends k=v, expanding groups into dotted keys under group
+func appendField(b []byte, group, k string, v interface{}, delim string) []byte {
+	v = resolveValue(v)
+	if g, ok := v.(GroupValue); ok {
+		group += k + "."
+		for i, f := range g {
+			if i > 0 {
+				b = append(b, delim...)
+			}
+			b = appendField(b, group, f.Key, f.Value, delim)
+		}
+		return b
+	}
+
+	b = append(b, group...)
+	b = append(b, k...)
+	b = append(b, '=')
+	return appendValue(b, v)
+}
+
+// Appends v as %+v would, avoiding fmt for common types
+func appendValue(b []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case string:
+		return append(b, v...)
+	case int:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(b, v, 10)
+	case uint64:
+		return strconv.AppendUint(b, v, 10)
+	case bool:
+		return strconv.AppendBool(b, v)
+	case float64:
+		return strconv.AppendFloat(b, v, 'g', -1, 64)
+	case quotedError:
+		return fmt.Appendf(b, "'%s'", v.err)
+	default:
+		return fmt.Appendf(b, "%+v", v)
+	}
+}
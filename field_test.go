@@ -0,0 +1,123 @@
+package alog
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestGroup(c *check.C) {
+	g := Group("method", "GET", 7, 200, "dangling")
+	c.Assert(g, check.DeepEquals, GroupValue{
+		{"method", "GET"},
+		{"7", 200},
+		{"!BADKEY", "dangling"},
+	})
+
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("req", "abc")
+	log.Set("http", Group("method", "GET", "status", 200))
+	log.Print("done")
+	checkLast(c, t, "[req=abc http.method=GET http.status=200] done")
+
+	// Nested
+	log = New(t)
+	log.SetFlags(0)
+	log.Set("a", Group("b", Group("c", 1), "d", 2))
+	log.Print("done")
+	checkLast(c, t, "[a.b.c=1 a.d=2] done")
+}
+
+func (s *Suite) TestFieldProvider(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("foo", "bar")
+
+	depth := 1
+	c.Assert(log.AddFieldProvider(FieldProviderFunc(func() []Field {
+		return []Field{{"depth", depth}}
+	})), check.Equals, log)
+
+	log.Print("test")
+	checkLast(c, t, "[foo=bar depth=1] test")
+
+	// Evaluated per entry
+	depth = 2
+	log.Print("test")
+	checkLast(c, t, "[foo=bar depth=2] test")
+
+	// Copies keep providers, but adding to a copy doesn't affect the original
+	log2 := log.With("x", 1)
+	log2.AddFieldProvider(FieldProviderFunc(func() []Field {
+		return []Field{{"y", 2}}
+	}))
+	log2.Print("test")
+	checkLast(c, t, "[foo=bar x=1 depth=2 y=2] test")
+	log.Print("test")
+	checkLast(c, t, "[foo=bar depth=2] test")
+
+	var nilLog *Log
+	c.Assert(nilLog.AddFieldProvider(nil), check.IsNil)
+}
+
+func (s *Suite) TestAppendValue(c *check.C) {
+	type pt struct{ X, Y int }
+	for _, v := range []interface{}{
+		"str", 7, int64(-8), uint64(9), true, 1.5, 1e21, 0.000001,
+		errors.New("bad"), pt{1, 2}, nil, []int{1},
+	} {
+		c.Assert(string(appendValue(nil, v)), check.Equals, fmt.Sprintf("%+v", v))
+	}
+}
+
+type counter struct{ n int }
+
+func (c *counter) String() string {
+	return fmt.Sprintf("n%d", c.n)
+}
+
+type userValuer struct {
+	calls *int
+}
+
+func (u userValuer) LogValue() slog.Value {
+	*u.calls++
+	return slog.GroupValue(slog.String("name", "alice"), slog.Int("id", *u.calls))
+}
+
+func (s *Suite) TestDeferredValues(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+
+	// Stringers are rendered when written, not when set
+	n := &counter{}
+	log.Set("count", n)
+	n.n = 5
+	log.Print("test")
+	checkLast(c, t, "[count=n5] test")
+
+	// LogValuers are resolved per entry, including groups
+	calls := 0
+	log.Set("user", userValuer{&calls})
+	c.Assert(calls, check.Equals, 0)
+	log.Print("test")
+	checkLast(c, t, "[count=n5 user.name=alice user.id=1] test")
+	log.Print("test")
+	checkLast(c, t, "[count=n5 user.name=alice user.id=2] test")
+
+	b, err := log.ExportJSON()
+	c.Assert(err, check.IsNil)
+	c.Assert(string(b), check.Equals, `{"count":"n5","user":{"name":"alice","id":3}}`)
+
+	log = New(t)
+	log.SetFlags(0)
+	log.Set("v", slog.StringValue("plain"))
+	log.Print("test")
+	checkLast(c, t, "[v=plain] test")
+}
@@ -0,0 +1,181 @@
+package alog
+
+import (
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+)
+
+// File destination that can be reopened by path, for use with external
+// rotation such as logrotate: after the file is moved, Reopen starts a new
+// file at the original path.  It can also rotate the file itself by size;
+// see SetRotation.
+type FileWriter struct {
+	path    string
+	f       *os.File
+	health  sinkHealth
+	maxSize int64
+	backups int
+	size    int64
+	mutex   sync.RWMutex
+}
+
+// Opens path for appending, creating it if needed
+func OpenFile(path string) (*FileWriter, error) {
+	f, err := openLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &FileWriter{path: path, f: f}, nil
+}
+
+// Replaced in tests
+var openLogFile = func(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+}
+
+func (w *FileWriter) Write(p []byte) (int, error) {
+	w.mutex.RLock()
+	if w.maxSize <= 0 && w.f != nil {
+		defer w.mutex.RUnlock()
+		n, err := w.f.Write(p)
+		w.health.record(err)
+		return n, err
+	}
+	w.mutex.RUnlock()
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	var err error
+	if w.f == nil {
+		// A rotation couldn't open the new file
+		err = w.open()
+	} else if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		err = w.rotate()
+	}
+	if err != nil {
+		w.health.record(err)
+		return 0, err
+	}
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	w.health.record(err)
+	return n, err
+}
+
+// Rotates the file once a write would take it past maxSize bytes: the file
+// is renamed to path.1, older files move up to path.2 and so on, and those
+// past backups are removed.  With no backups, the file is truncated.  A
+// maxSize of zero turns rotation off.
+func (w *FileWriter) SetRotation(maxSize int64, backups int) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.maxSize = maxSize
+	w.backups = backups
+	w.size = fileSize(w.f)
+}
+
+// Moves the current file aside and starts a new one.  If the new file can't
+// be opened, there is no current file, and the next write tries again.  The
+// caller holds the write lock.
+func (w *FileWriter) rotate() error {
+	// Closed first, since an open file can't be renamed on Windows
+	err := w.f.Close()
+	w.f = nil
+	if err != nil {
+		return err
+	}
+	if w.backups > 0 {
+		os.Remove(w.backup(w.backups))
+		for i := w.backups - 1; i > 0; i-- {
+			os.Rename(w.backup(i), w.backup(i+1))
+		}
+		os.Rename(w.path, w.backup(1))
+	} else {
+		os.Remove(w.path)
+	}
+	return w.open()
+}
+
+// Opens the path as the current file.  The caller holds the write lock.
+func (w *FileWriter) open() error {
+	f, err := openLogFile(w.path)
+	if err != nil {
+		return err
+	}
+	w.f = f
+	w.size = fileSize(f)
+	return nil
+}
+
+func (w *FileWriter) backup(i int) string {
+	return w.path + "." + strconv.Itoa(i)
+}
+
+func fileSize(f *os.File) int64 {
+	fi, err := f.Stat()
+	if err != nil {
+		return 0
+	}
+	return fi.Size()
+}
+
+func (w *FileWriter) Status() []SinkStatus {
+	return []SinkStatus{w.health.status("file " + w.path)}
+}
+
+// Closes the current file and opens the path again.  If the path can't be
+// opened, writes continue to the current file.
+func (w *FileWriter) Reopen() error {
+	f, err := openLogFile(w.path)
+	if err != nil {
+		return err
+	}
+
+	w.mutex.Lock()
+	old := w.f
+	w.f = f
+	w.size = fileSize(f)
+	w.mutex.Unlock()
+	if old == nil {
+		return nil
+	}
+	return old.Close()
+}
+
+// Calls Reopen whenever one of sigs is received, typically syscall.SIGHUP.
+// A failed reopen keeps the current file.  Call stop to stop watching.
+func (w *FileWriter) ReopenOnSignal(sigs ...os.Signal) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		for {
+			select {
+			case <-ch:
+				w.Reopen()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+func (w *FileWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.f == nil {
+		return nil
+	}
+	return w.f.Close()
+}
@@ -0,0 +1,99 @@
+package alog
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestFileReopen(c *check.C) {
+	dir := c.MkDir()
+	path := filepath.Join(dir, "app.log")
+	rotated := filepath.Join(dir, "app.log.1")
+
+	w, err := OpenFile(path)
+	c.Assert(err, check.IsNil)
+	log := New(w)
+	log.SetFlags(0)
+	log.Print("one")
+
+	// Writes follow the moved file until reopened
+	c.Assert(os.Rename(path, rotated), check.IsNil)
+	log.Print("two")
+	c.Assert(w.Reopen(), check.IsNil)
+	log.Print("three")
+	c.Assert(w.Status()[0].Failing, check.Equals, false)
+	c.Assert(w.Close(), check.IsNil)
+
+	b, err := ioutil.ReadFile(rotated)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(b), check.Equals, "one\ntwo\n")
+	b, err = ioutil.ReadFile(path)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(b), check.Equals, "three\n")
+
+	_, err = OpenFile(filepath.Join(dir, "missing", "app.log"))
+	c.Assert(err, check.NotNil)
+}
+
+func (s *Suite) TestFileRotation(c *check.C) {
+	dir := c.MkDir()
+	path := filepath.Join(dir, "app.log")
+	w, err := OpenFile(path)
+	c.Assert(err, check.IsNil)
+	w.SetRotation(5, 2)
+	log := New(w)
+	log.SetFlags(0)
+	for _, m := range []string{"one", "two", "three", "four"} {
+		log.Print(m)
+	}
+	c.Assert(w.Close(), check.IsNil)
+
+	for name, want := range map[string]string{
+		"app.log":   "four\n",
+		"app.log.1": "three\n",
+		"app.log.2": "two\n",
+	} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		c.Assert(err, check.IsNil)
+		c.Assert(string(b), check.Equals, want, check.Commentf(name))
+	}
+	_, err = os.Stat(filepath.Join(dir, "app.log.3"))
+	c.Assert(os.IsNotExist(err), check.Equals, true)
+}
+
+func (s *Suite) TestFileRotationOpenFails(c *check.C) {
+	dir := c.MkDir()
+	path := filepath.Join(dir, "app.log")
+	w, err := OpenFile(path)
+	c.Assert(err, check.IsNil)
+	w.SetRotation(5, 1)
+	log := New(w)
+	log.SetFlags(0)
+	var errs []string
+	log.SetErrorHandler(func(err error) { errs = append(errs, err.Error()) })
+	log.Print("one")
+
+	open := openLogFile
+	defer func() { openLogFile = open }()
+	openLogFile = func(string) (*os.File, error) { return nil, errors.New("disk gone") }
+	log.Print("two")
+	c.Assert(errs, check.DeepEquals, []string{"disk gone"})
+	c.Assert(w.Status()[0].Failing, check.Equals, true)
+	c.Assert(w.Status()[0].LastError, check.ErrorMatches, "disk gone")
+
+	// The next write opens the file again
+	openLogFile = open
+	log.Print("three")
+	c.Assert(w.Status()[0].Failing, check.Equals, false)
+	c.Assert(w.Close(), check.IsNil)
+	b, err := ioutil.ReadFile(path)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(b), check.Equals, "three\n")
+	b, err = ioutil.ReadFile(path + ".1")
+	c.Assert(err, check.IsNil)
+	c.Assert(string(b), check.Equals, "one\n")
+}
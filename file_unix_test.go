@@ -0,0 +1,33 @@
+//go:build unix
+
+package alog
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestFileReopenOnSignal(c *check.C) {
+	dir := c.MkDir()
+	path := filepath.Join(dir, "app.log")
+
+	w, err := OpenFile(path)
+	c.Assert(err, check.IsNil)
+	defer w.Close()
+	stop := w.ReopenOnSignal(syscall.SIGUSR2)
+	defer stop()
+
+	c.Assert(os.Rename(path, path+".1"), check.IsNil)
+	c.Assert(syscall.Kill(os.Getpid(), syscall.SIGUSR2), check.IsNil)
+	for i := 0; i < 100; i++ {
+		if _, err = os.Stat(path); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Assert(err, check.IsNil)
+}
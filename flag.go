@@ -0,0 +1,56 @@
+package alog
+
+// Usage strings for LevelFlag and FormatFlag
+const (
+	LevelUsage  = "minimum log level: debug, info, warn, error, panic or fatal"
+	FormatUsage = "log format: text, json, pretty-json, pretty-json-compact, console, gcp, datadog, ecs, common or combined"
+)
+
+// flag.Value that sets a LevelVar, typically a logger's:
+//
+//	flag.Var(alog.LevelFlag{log.LevelVar()}, "log-level", alog.LevelUsage)
+type LevelFlag struct {
+	*LevelVar
+}
+
+func (f LevelFlag) String() string {
+	if f.LevelVar == nil {
+		return ""
+	}
+	return f.Level().String()
+}
+
+func (f LevelFlag) Set(s string) error {
+	l, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+	f.LevelVar.Set(l)
+	return nil
+}
+
+// flag.Value that sets a logger's formatter by name, as Config's Format
+// does:
+//
+//	flag.Var(&alog.FormatFlag{Log: log}, "log-format", alog.FormatUsage)
+type FormatFlag struct {
+	Log    *Log
+	format string
+}
+
+func (f *FormatFlag) String() string {
+	if f.format == "" {
+		return "text"
+	}
+	return f.format
+}
+
+func (f *FormatFlag) Set(s string) error {
+	formatter, err := buildFormatter(s, false)
+	if err != nil {
+		return err
+	}
+	f.Log.SetFormatter(formatter)
+	f.format = s
+	return nil
+}
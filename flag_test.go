@@ -0,0 +1,35 @@
+package alog
+
+import (
+	"bytes"
+	"flag"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestFlags(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+
+	var usage bytes.Buffer
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(&usage)
+	fs.Var(LevelFlag{log.LevelVar()}, "log-level", LevelUsage)
+	fs.Var(&FormatFlag{Log: log}, "log-format", FormatUsage)
+
+	c.Assert(fs.Parse([]string{"-log-level=DEBUG", "-log-format", "json"}), check.IsNil)
+	c.Assert(log.Level(), check.Equals, DebugLevel)
+	c.Assert(fs.Lookup("log-level").Value.String(), check.Equals, "debug")
+	c.Assert(fs.Lookup("log-format").Value.String(), check.Equals, "json")
+	log.Debug("a")
+	c.Assert(t.last(), check.Equals, `{"level":"debug","msg":"a"}`+"\n")
+
+	c.Assert(fs.Parse([]string{"-log-level=loud"}), check.ErrorMatches, `.*unknown level "loud"`)
+	c.Assert(fs.Parse([]string{"-log-format=xml"}), check.ErrorMatches, `.*unknown format "xml"`)
+	c.Assert(log.Level(), check.Equals, DebugLevel)
+
+	usage.Reset()
+	fs.PrintDefaults()
+	c.Assert(usage.String(), check.Matches, `(?s).*log format: text, json.*minimum log level: debug, info.*\(default info\)\n`)
+}
@@ -0,0 +1,160 @@
+package alog
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Returns a field for each leaf of a struct or map, keyed by its dotted
+// path, e.g. timeout and db.host for a config struct.  Struct fields are
+// named by their alog tag, or else their json tag, or else their name; a
+// tag of "-" skips the field, and omitempty skips it when it is zero.
+// Unexported fields are skipped and exported embedded structs' fields are
+// promoted, as encoding/json does, and map keys are sorted.  Slices, errors,
+// fmt.Stringers and encoding.TextMarshalers such as time.Time are leaves.
+// Nil embedded structs are skipped.  No field has an empty key, so values
+// that aren't structs or maps, and nil, give no fields.
+func Flatten(v interface{}) []Field {
+	f := flattener{visiting: map[uintptr]bool{}}
+	f.value("", reflect.ValueOf(v))
+	return f.fields
+}
+
+// Sets each of Flatten(v)'s fields as Set would, with keys under prefix:
+//
+//	log.SetStruct("cfg", cfg) // [cfg.timeout=5s cfg.retries=3]
+func (a *Log) SetStruct(prefix string, v interface{}) *Log {
+	if a == nil {
+		return nil
+	}
+	for _, f := range Flatten(v) {
+		a.setMeta(joinKey(prefix, f.Key), f.Value)
+	}
+	return a
+}
+
+func joinKey(prefix, k string) string {
+	switch {
+	case prefix == "":
+		return k
+	case k == "":
+		return prefix
+	}
+	return prefix + "." + k
+}
+
+type flattener struct {
+	fields   []Field
+	visiting map[uintptr]bool
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+func (f *flattener) value(key string, v reflect.Value) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			f.leaf(key, nil)
+			return
+		}
+		if v.Kind() == reflect.Ptr {
+			if isLeafType(v.Type()) {
+				break
+			}
+			if f.visiting[v.Pointer()] {
+				return
+			}
+			f.visiting[v.Pointer()] = true
+			defer delete(f.visiting, v.Pointer())
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		f.leaf(key, nil)
+		return
+	}
+
+	if isLeaf(v) {
+		f.leaf(key, v.Interface())
+		return
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		f.structFields(key, v)
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		for _, k := range keys {
+			f.value(joinKey(key, fmt.Sprint(k)), v.MapIndex(k))
+		}
+	}
+}
+
+func (f *flattener) leaf(key string, v interface{}) {
+	if key != "" {
+		f.fields = append(f.fields, Field{key, v})
+	}
+}
+
+func isLeaf(v reflect.Value) bool {
+	return (v.Kind() != reflect.Struct && v.Kind() != reflect.Map) || isLeafType(v.Type())
+}
+
+func isLeafType(t reflect.Type) bool {
+	for _, i := range []reflect.Type{errorType, stringerType, textMarshalerType} {
+		if t.Implements(i) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *flattener) structFields(key string, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, omitEmpty := fieldName(sf)
+		if name == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+		if sf.Anonymous && name == "" && indirectType(sf.Type).Kind() == reflect.Struct {
+			if fv.Kind() != reflect.Ptr || !fv.IsNil() {
+				f.value(key, fv)
+			}
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		f.value(joinKey(key, name), fv)
+	}
+}
+
+// Returns the name from the field's alog or json tag, and whether it has
+// the omitempty option
+func fieldName(sf reflect.StructField) (string, bool) {
+	tag, ok := sf.Tag.Lookup("alog")
+	if !ok {
+		tag = sf.Tag.Get("json")
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	return name, strings.Contains(","+opts+",", ",omitempty,")
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
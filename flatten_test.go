@@ -0,0 +1,93 @@
+package alog
+
+import (
+	"errors"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+type FlattenBase struct {
+	Env string `json:"env"`
+}
+
+type FlattenExtra struct {
+	Region string
+}
+
+type flattenDB struct {
+	Host     string
+	Password string `alog:"-"`
+	Port     int    `json:"port,omitempty"`
+}
+
+type flattenConfig struct {
+	FlattenBase
+	*FlattenExtra
+	Timeout time.Duration `alog:"timeout"`
+	Retries int           `json:"retries"`
+	DB      *flattenDB    `alog:"db"`
+	Started time.Time
+	Err     error
+	Tags    []string
+	Limits  map[string]int
+	Self    *flattenConfig
+	hidden  int
+}
+
+func (s *Suite) TestFlatten(c *check.C) {
+	started := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := errors.New("bad")
+	cfg := &flattenConfig{
+		FlattenBase: FlattenBase{"prod"},
+		Timeout:     5 * time.Second,
+		Retries:     3,
+		DB:          &flattenDB{Host: "db1", Password: "hunter2"},
+		Started:     started,
+		Err:         err,
+		Tags:        []string{"a"},
+		Limits:      map[string]int{"rps": 10, "burst": 20},
+		hidden:      1,
+	}
+	cfg.Self = cfg
+
+	c.Assert(Flatten(cfg), check.DeepEquals, []Field{
+		{"env", "prod"},
+		{"timeout", 5 * time.Second},
+		{"retries", 3},
+		{"db.Host", "db1"},
+		{"Started", started},
+		{"Err", err},
+		{"Tags", []string{"a"}},
+		{"Limits.burst", 20},
+		{"Limits.rps", 10},
+	})
+	c.Assert(Flatten(7), check.HasLen, 0)
+	c.Assert(Flatten((*flattenConfig)(nil)), check.HasLen, 0)
+	c.Assert(Flatten(map[string]interface{}{"a": nil}), check.DeepEquals, []Field{{"a", nil}})
+
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	c.Assert(log.SetStruct("cfg", struct {
+		Timeout time.Duration `alog:"timeout"`
+		Retries int           `alog:"retries"`
+	}{5 * time.Second, 3}), check.Equals, log)
+	log.SetStruct("", map[string]string{"region": "eu"})
+	log.SetStruct("", 7)
+	log.SetStruct("db", (*flattenDB)(nil))
+	log.Print("started")
+	c.Assert(t.last(), check.Equals, "[cfg.timeout=5s cfg.retries=3 region=eu] started\n")
+
+	var nilLog *Log
+	c.Assert(nilLog.SetStruct("x", cfg), check.IsNil)
+
+	t = &Thief{}
+	log = New(t)
+	log.SetFlags(0)
+	log.SetKeyPolicy(&KeyPolicy{SnakeCase: true}).SetDuplicatePolicy(KeepFirst)
+	log.Set("db.max_conns", 1)
+	log.SetStruct("db", map[string]int{"MaxConns": 2, "IdleConns": 3})
+	log.Print("started")
+	c.Assert(t.last(), check.Equals, "[db.max_conns=1 db.idle_conns=3] started\n")
+}
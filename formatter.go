@@ -0,0 +1,191 @@
+package alog
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// A single log entry, as given to a Formatter
+type Entry struct {
+	// Zero unless the logger's flags include a date or time
+	Time  time.Time
+	Level Level
+	// Without a trailing newline
+	Message string
+	// The logger's fields, then the entry's own
+	Fields []Field
+	// Empty unless the logger's flags include Lshortfile or Llongfile
+	File string
+	Line int
+}
+
+// Renders entries in place of the default text layout.  Format appends the
+// entry to b; the logger adds the final newline.
+type Formatter interface {
+	Format(b []byte, e *Entry) []byte
+}
+
+// Formatter setting, shared by a logger and its copies
+type formatterVar struct {
+	v atomic.Value // formatterBox
+}
+
+type formatterBox struct {
+	f Formatter
+}
+
+func (v *formatterVar) get() Formatter {
+	b, _ := v.v.Load().(formatterBox)
+	return b.f
+}
+
+func (v *formatterVar) set(f Formatter) {
+	v.v.Store(formatterBox{f})
+}
+
+// Renders entries with f instead of the default text layout.  A nil f
+// restores the default.  Like the level, the formatter is shared with the
+// logger's copies.
+func (a *Log) SetFormatter(f Formatter) *Log {
+	if a == nil {
+		return nil
+	}
+	a.formatter.set(f)
+	return a
+}
+
+// Builds the Entry for a Formatter.  The message is formatted here, and the
+// time and caller are set according to flags.  The fields are gathered into
+// fp, so the Entry must not be used after fp is returned to the pool.
+func (a *Log) entry(now time.Time, level Level, m message, flags int, file string, line int, fp *[]Field) Entry {
+	bp := getBuf()
+	defer putBuf(bp)
+	msg := m.appendTo(append(*bp, a.prefix...))
+	if n := len(msg); n > 0 && msg[n-1] == '\n' {
+		msg = msg[:n-1]
+	}
+	*bp = msg
+	*fp = append(a.appendEntryFields(*fp), m.fields...)
+	if a.schema != (Schema{}) {
+		*fp = append(*fp, Field{"schema", a.schema.String()})
+	}
+
+	e := Entry{
+		Level:   level,
+		Message: string(msg),
+		Fields:  a.formatValues(*fp),
+		Line:    line,
+	}
+	e.Message = a.scrubString(e.Message)
+	e.Fields = a.scrubFields(e.Fields)
+	if a.sanitize {
+		e.Message = sanitizeString(e.Message)
+		e.Fields = sanitizeFields(e.Fields)
+	}
+	if flags&(log.Ldate|log.Ltime|log.Lmicroseconds) != 0 {
+		e.Time = now
+		if flags&log.LUTC != 0 {
+			e.Time = now.UTC()
+		}
+	}
+	if flags&(log.Lshortfile|log.Llongfile) != 0 {
+		e.File = file
+		if flags&log.Lshortfile != 0 {
+			e.File = shortFile(file)
+		}
+	}
+	return e
+}
+
+func shortFile(file string) string {
+	for i := len(file) - 1; i > 0; i-- {
+		if file[i] == '/' {
+			return file[i+1:]
+		}
+	}
+	return file
+}
+
+// Formats entries as one JSON object per line:
+//
+//	{"time":"2006-01-02T15:04:05.000000Z","level":"info","msg":"done","caller":"main.go:12","user":"alice"}
+//
+// time and caller are included when the logger's flags ask for them.
+type JSONFormatter struct{}
+
+func (JSONFormatter) Format(b []byte, e *Entry) []byte {
+	b = append(b, '{')
+	if !e.Time.IsZero() {
+		b = append(b, `"time":"`...)
+		b = e.Time.AppendFormat(b, "2006-01-02T15:04:05.000000Z07:00")
+		b = append(b, `",`...)
+	}
+	b = append(b, `"level":`...)
+	b = appendJSONString(b, e.Level.String())
+	b = append(b, `,"msg":`...)
+	b = appendJSONString(b, e.Message)
+	if e.File != "" {
+		b = append(b, `,"caller":`...)
+		b = appendJSONString(b, e.File+":"+strconv.Itoa(e.Line))
+	}
+	for _, f := range e.Fields {
+		b = append(b, ',')
+		b = appendJSONField(b, f.Key, f.Value)
+	}
+	return append(b, '}')
+}
+
+// Formats entries for reading in a terminal, in the order a person scans
+// them: time, level, caller, message, then fields.
+//
+//	15:04:05.000 WARN  main.go:12 slow request user=alice took=2s
+//
+// With Color, the level is highlighted with ANSI escapes.
+type ConsoleFormatter struct {
+	Color bool
+}
+
+var levelColors = map[Level]string{
+	DebugLevel: "\x1b[90m",
+	InfoLevel:  "\x1b[34m",
+	WarnLevel:  "\x1b[33m",
+	ErrorLevel: "\x1b[31m",
+	PanicLevel: "\x1b[1;31m",
+	FatalLevel: "\x1b[1;31m",
+}
+
+func (f ConsoleFormatter) Format(b []byte, e *Entry) []byte {
+	if !e.Time.IsZero() {
+		b = e.Time.AppendFormat(b, "15:04:05.000 ")
+	}
+
+	color := ""
+	if f.Color {
+		color = levelColors[e.Level]
+	}
+	b = append(b, color...)
+	name := strings.ToUpper(e.Level.String())
+	b = append(b, name...)
+	if color != "" {
+		b = append(b, "\x1b[0m"...)
+	}
+	for i := len(name); i < 6; i++ {
+		b = append(b, ' ')
+	}
+
+	if e.File != "" {
+		b = append(b, e.File...)
+		b = append(b, ':')
+		b = strconv.AppendInt(b, int64(e.Line), 10)
+		b = append(b, ' ')
+	}
+	b = append(b, e.Message...)
+	if len(e.Fields) > 0 {
+		b = append(b, ' ')
+		b = appendFields(b, e.Fields, " ")
+	}
+	return b
+}
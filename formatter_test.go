@@ -0,0 +1,66 @@
+package alog
+
+import (
+	"errors"
+	stdlog "log"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestJSONFormatter(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.SetFormatter(JSONFormatter{})
+	log.Set("user", "alice").Set("http", Group("status", 200))
+
+	log.Warnln("slow \"request\"")
+	checkLast(c, t, `{"level":"warn","msg":"slow \"request\"","user":"alice","http":{"status":200}}`)
+	log.Info().Err(errors.New("oops")).Msg("done")
+	checkLast(c, t, `{"level":"info","msg":"done","user":"alice","http":{"status":200},"error":"oops"}`)
+
+	// Shared with copies, like the level
+	log2 := log.Copy()
+	log2.SetFlags(stdlog.Lshortfile)
+	log2.Print("here")
+	c.Assert(t.last(), check.Matches, `\{"level":"info","msg":"here","caller":"formatter_test\.go:\d+",.*\}\n`)
+
+	e := &Entry{Time: time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC), Level: ErrorLevel, Message: "m"}
+	c.Assert(string(JSONFormatter{}.Format(nil, e)), check.Equals,
+		`{"time":"2020-01-02T03:04:05.000006Z","level":"error","msg":"m"}`)
+
+	log.SetFormatter(nil)
+	log.Print("text")
+	checkLast(c, t, "[user=alice http.status=200] text")
+}
+
+func (s *Suite) TestConsoleFormatter(c *check.C) {
+	e := &Entry{
+		Time:    time.Date(2020, 1, 2, 3, 4, 5, 6000000, time.UTC),
+		Level:   WarnLevel,
+		Message: "slow",
+		Fields:  []Field{{"took", 2 * time.Second}},
+		File:    "main.go",
+		Line:    12,
+	}
+	c.Assert(string(ConsoleFormatter{}.Format(nil, e)), check.Equals,
+		"03:04:05.006 WARN  main.go:12 slow took=2s")
+	c.Assert(string(ConsoleFormatter{Color: true}.Format(nil, e)), check.Equals,
+		"03:04:05.006 \x1b[33mWARN\x1b[0m  main.go:12 slow took=2s")
+
+	e = &Entry{Level: DebugLevel, Message: "m"}
+	c.Assert(string(ConsoleFormatter{}.Format(nil, e)), check.Equals, "DEBUG m")
+}
+
+func (s *Suite) TestFormatterSanitize(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.SetFormatter(ConsoleFormatter{Color: true})
+	log.SetSanitize(true)
+	log.Set("user", "bob\nINFO fake")
+
+	log.Error(errors.New("a\nb"))
+	checkLast(c, t, "\x1b[31mERROR\x1b[0m a\\nb user=bob\\nINFO fake")
+}
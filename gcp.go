@@ -0,0 +1,62 @@
+package alog
+
+import (
+	"strconv"
+	"time"
+)
+
+// Formats entries as the structured JSON understood by Google Cloud
+// Logging's agents on Cloud Run, GKE and App Engine:
+//
+//	{"severity":"WARNING","time":"...","message":"slow","logging.googleapis.com/sourceLocation":{"file":"main.go","line":"12"},"logging.googleapis.com/labels":{"user":"alice"}}
+//
+// Fields become labels, which are strings; groups are flattened into dotted
+// keys.  time and sourceLocation are included when the logger's flags ask
+// for them.
+type GCPFormatter struct{}
+
+var gcpSeverities = map[Level]string{
+	DebugLevel: "DEBUG",
+	InfoLevel:  "INFO",
+	WarnLevel:  "WARNING",
+	ErrorLevel: "ERROR",
+	PanicLevel: "CRITICAL",
+	FatalLevel: "ALERT",
+}
+
+func (GCPFormatter) Format(b []byte, e *Entry) []byte {
+	severity, ok := gcpSeverities[e.Level]
+	if !ok {
+		severity = "DEFAULT"
+	}
+	b = append(b, `{"severity":"`...)
+	b = append(b, severity...)
+	b = append(b, '"')
+	if !e.Time.IsZero() {
+		b = append(b, `,"time":"`...)
+		b = e.Time.AppendFormat(b, time.RFC3339Nano)
+		b = append(b, '"')
+	}
+	b = append(b, `,"message":`...)
+	b = appendJSONString(b, e.Message)
+	if e.File != "" {
+		b = append(b, `,"logging.googleapis.com/sourceLocation":{"file":`...)
+		b = appendJSONString(b, e.File)
+		b = append(b, `,"line":"`...)
+		b = strconv.AppendInt(b, int64(e.Line), 10)
+		b = append(b, `"}`...)
+	}
+	if len(e.Fields) > 0 {
+		b = append(b, `,"logging.googleapis.com/labels":{`...)
+		for i, f := range flattenFields(e.Fields) {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendJSONString(b, f.Key)
+			b = append(b, ':')
+			b = appendJSONString(b, string(appendValue(nil, f.Value)))
+		}
+		b = append(b, '}')
+	}
+	return append(b, '}')
+}
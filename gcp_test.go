@@ -0,0 +1,31 @@
+package alog
+
+import (
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestGCPFormatter(c *check.C) {
+	e := &Entry{
+		Time:    time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC),
+		Level:   WarnLevel,
+		Message: "slow",
+		Fields:  []Field{{"user", "alice"}, {"http", Group("status", 200)}},
+		File:    "main.go",
+		Line:    12,
+	}
+	c.Assert(string(GCPFormatter{}.Format(nil, e)), check.Equals,
+		`{"severity":"WARNING","time":"2020-01-02T03:04:05.000006Z","message":"slow",`+
+			`"logging.googleapis.com/sourceLocation":{"file":"main.go","line":"12"},`+
+			`"logging.googleapis.com/labels":{"user":"alice","http.status":"200"}}`)
+
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.SetFormatter(GCPFormatter{})
+	log.Error("failed")
+	checkLast(c, t, `{"severity":"ERROR","message":"failed"}`)
+	log.Output(1, FatalLevel, "bye")
+	checkLast(c, t, `{"severity":"ALERT","message":"bye"}`)
+}
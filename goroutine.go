@@ -0,0 +1,20 @@
+package alog
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// Parses the current goroutine's id from the header of its stack trace,
+// "goroutine 18 [running]:"
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
@@ -0,0 +1,35 @@
+package alog
+
+import (
+	"fmt"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestGoroutineID(c *check.C) {
+	id := goroutineID()
+	c.Assert(id, check.Not(check.Equals), uint64(0))
+
+	done := make(chan uint64)
+	go func() { done <- goroutineID() }()
+	c.Assert(<-done, check.Not(check.Equals), id)
+
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("foo", "bar")
+	c.Assert(log.SetGoroutineID(true), check.Equals, log)
+	log.Print("test")
+	checkLast(c, t, fmt.Sprintf("[foo=bar goroutine=%d] test", id))
+
+	// Copies keep the setting
+	log.With("x", 1).Print("test")
+	checkLast(c, t, fmt.Sprintf("[foo=bar x=1 goroutine=%d] test", id))
+
+	log.SetGoroutineID(false)
+	log.Print("test")
+	checkLast(c, t, "[foo=bar] test")
+
+	var nilLog *Log
+	c.Assert(nilLog.SetGoroutineID(true), check.IsNil)
+}
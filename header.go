@@ -0,0 +1,226 @@
+package alog
+
+import (
+	"io"
+	"log"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// Implemented by writers that handle entries differently by level.  The
+// logger calls WriteLevel instead of Write for them.
+type LevelWriter interface {
+	io.Writer
+	WriteLevel(level Level, p []byte) (int, error)
+}
+
+// Implemented by writers that send entries somewhere structured, such as a
+// log service's API.  The logger calls WriteEntry instead of Write for
+// them, with the entry and its formatted line.  The entry's Time is always
+// set.  Writers wrapping an EntryWriter hide it, except TimeoutWriter,
+// RetryWriter and FallbackWriter, so it must otherwise be the logger's own
+// writer.
+type EntryWriter interface {
+	io.Writer
+	WriteEntry(e *Entry, p []byte) (int, error)
+}
+
+// Implemented by EntryWriters that only need entries when the writer they
+// wrap does
+type entryWrapper interface {
+	wantsEntries() bool
+}
+
+// Passed to write as the calldepth of entries with no caller
+const noCaller = -1
+
+// Writes the message as one entry, with the header selected by the embedded
+// Logger's flags and prefix.  The header matches the standard library's.
+// calldepth counts like log.Logger.Output's.  LevelWriters and EntryWriters
+// must not retain the buffer or entry they are given.  A calldepth of
+// noCaller leaves the caller out, for entries that don't have a meaningful one.
+func (a *Log) write(calldepth int, level Level, m message) error {
+	now := time.Now()
+	flags := a.Logger.Flags()
+	if a.location != nil {
+		now = now.In(a.location)
+		flags &^= log.LUTC
+	}
+	if calldepth == noCaller {
+		flags &^= log.Lshortfile | log.Llongfile
+		calldepth = 2
+	}
+
+	if len(a.skipPkgs) > 0 && (flags&(log.Lshortfile|log.Llongfile) != 0 || level >= a.stackLevel) {
+		calldepth = a.skipFrames(calldepth)
+	}
+
+	var file string
+	var line int
+	if flags&(log.Lshortfile|log.Llongfile) != 0 {
+		var ok bool
+		if _, file, line, ok = runtime.Caller(calldepth); !ok {
+			file = "???"
+			line = 0
+		} else if flags&log.Lshortfile == 0 {
+			file = a.paths.trim(file)
+		}
+	}
+
+	m = a.sequenced(m)
+	var stack string
+	if level >= a.stackLevel {
+		stack = a.callerStack(calldepth)
+	}
+	w := a.Logger.Writer()
+	ew, _ := w.(EntryWriter)
+	if ww, ok := w.(entryWrapper); ok && !ww.wantsEntries() {
+		ew = nil
+	}
+	f := a.formatter.get()
+	var e Entry
+	if f != nil || ew != nil {
+		fp := getFields()
+		defer putFields(fp)
+		em := m
+		if stack != "" {
+			n := len(m.fields)
+			em.fields = append(m.fields[:n:n], Field{"stack", stack})
+		}
+		e = a.entry(now, level, em, flags, file, line, fp)
+	}
+
+	bp := getBuf()
+	defer putBuf(bp)
+	var buf []byte
+	if f != nil {
+		buf = f.Format(*bp, &e)
+		if !a.sanitize {
+			buf = a.multiline.apply(buf, 0)
+		}
+	} else {
+		buf = appendHeader(*bp, now, a.Logger.Prefix(), flags, file, line)
+		start := len(buf)
+		buf = append(buf, level.tag()...)
+		buf = a.appendMessage(buf, m)
+		if len(buf) > start && buf[len(buf)-1] == '\n' {
+			buf = buf[:len(buf)-1]
+		}
+		if stack != "" {
+			buf = append(buf, '\n')
+			buf = append(buf, stack...)
+		}
+		buf = a.scrub(buf, start)
+		if a.sanitize {
+			buf = sanitize(buf, start)
+		} else {
+			buf = a.multiline.apply(buf, start)
+		}
+	}
+	buf = append(buf, '\n')
+	*bp = buf
+
+	if e.Time.IsZero() {
+		e.Time = now
+	}
+	if a.trace != nil && level < a.level.Level() {
+		a.trace.add(level, &e, buf)
+		return nil
+	}
+	a.logTraceEvent(level, m)
+
+	a.writeMutex.Lock()
+	var err error
+	if a.trace != nil && level >= ErrorLevel {
+		for _, t := range a.trace.take() {
+			if terr := dispatch(w, ew, t.level, &t.e, t.p); err == nil {
+				err = terr
+			}
+		}
+	}
+	if derr := dispatch(w, ew, level, &e, buf); derr != nil {
+		err = derr
+	}
+	a.writeMutex.Unlock()
+
+	if err != nil && a.onError != nil {
+		a.onError(err)
+	}
+	return err
+}
+
+// Passes an entry to the writer's WriteEntry, WriteLevel or Write
+func dispatch(w io.Writer, ew EntryWriter, level Level, e *Entry, buf []byte) error {
+	var err error
+	if ew != nil {
+		_, err = ew.WriteEntry(e, buf)
+	} else if lw, ok := w.(LevelWriter); ok {
+		_, err = lw.WriteLevel(level, buf)
+	} else {
+		_, err = w.Write(buf)
+	}
+	return err
+}
+
+// Calls h with the error whenever writing an entry fails, which the logging
+// methods otherwise discard.  h is called after the write, so it may log
+// to the same logger, though failures of its own entries call it again.
+// A nil h removes the handler.  Copies keep the setting.  If the writer is
+// an ErrorReporter, h is also given its background failures.
+func (a *Log) SetErrorHandler(h func(error)) *Log {
+	if a == nil {
+		return nil
+	}
+	a.onError = h
+	if r, ok := a.Logger.Writer().(ErrorReporter); ok {
+		r.SetErrorHandler(h)
+	}
+	return a
+}
+
+// Renders timestamps in loc rather than the local time zone.  It takes
+// precedence over the LUTC flag.  A nil loc restores the default.  Copies
+// keep the setting.
+func (a *Log) SetLocation(loc *time.Location) *Log {
+	if a == nil {
+		return nil
+	}
+	a.location = loc
+	return a
+}
+
+// Same layout as the standard library's log header
+func appendHeader(buf []byte, t time.Time, prefix string, flags int, file string, line int) []byte {
+	if flags&log.Lmsgprefix == 0 {
+		buf = append(buf, prefix...)
+	}
+	if flags&(log.Ldate|log.Ltime|log.Lmicroseconds) != 0 {
+		if flags&log.LUTC != 0 {
+			t = t.UTC()
+		}
+		if flags&log.Ldate != 0 {
+			buf = t.AppendFormat(buf, "2006/01/02 ")
+		}
+		if flags&(log.Ltime|log.Lmicroseconds) != 0 {
+			if flags&log.Lmicroseconds != 0 {
+				buf = t.AppendFormat(buf, "15:04:05.000000 ")
+			} else {
+				buf = t.AppendFormat(buf, "15:04:05 ")
+			}
+		}
+	}
+	if flags&(log.Lshortfile|log.Llongfile) != 0 {
+		if flags&log.Lshortfile != 0 {
+			file = shortFile(file)
+		}
+		buf = append(buf, file...)
+		buf = append(buf, ':')
+		buf = strconv.AppendInt(buf, int64(line), 10)
+		buf = append(buf, ": "...)
+	}
+	if flags&log.Lmsgprefix != 0 {
+		buf = append(buf, prefix...)
+	}
+	return buf
+}
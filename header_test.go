@@ -0,0 +1,183 @@
+package alog
+
+import (
+	stdlog "log"
+	"runtime"
+	"strconv"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestAppendHeader(c *check.C) {
+	t := time.Date(2009, 11, 10, 23, 4, 5, 123456789, time.FixedZone("X", 3600))
+	cases := []struct {
+		flags    int
+		prefix   string
+		expected string
+	}{
+		{0, "", ""},
+		{0, "p: ", "p: "},
+		{stdlog.LstdFlags, "", "2009/11/10 23:04:05 "},
+		{stdlog.LstdFlags | stdlog.LUTC, "", "2009/11/10 22:04:05 "},
+		{stdlog.Ltime | stdlog.Lmicroseconds, "", "23:04:05.123456 "},
+		{stdlog.Lshortfile, "", "b.go:7: "},
+		{stdlog.Llongfile, "", "/a/b.go:7: "},
+		{stdlog.Ldate | stdlog.Lmsgprefix, "p: ", "2009/11/10 p: "},
+	}
+	for _, x := range cases {
+		h := string(appendHeader(nil, t, x.prefix, x.flags, "/a/b.go", 7))
+		c.Assert(h, check.Equals, x.expected, check.Commentf("flags %d", x.flags))
+	}
+}
+
+func (s *Suite) TestHeaderCaller(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(stdlog.Lshortfile)
+
+	_, _, line, _ := runtime.Caller(0)
+	log.Print("test")
+	checkLast(c, t, "header_test.go:"+strconv.Itoa(line+1)+": test")
+
+	log.With("foo", "bar").Warn("test")
+	checkLast(c, t, "header_test.go:"+strconv.Itoa(line+4)+": WARN [foo=bar] test")
+
+	// Empty messages still end the line
+	log.SetFlags(0)
+	log.Print()
+	checkLast(c, t, "")
+
+	// SetOutput and SetPrefix on the embedded Logger are honoured
+	t2 := &Thief{}
+	log.SetOutput(t2)
+	log.SetFlags(stdlog.Lmsgprefix)
+	log.SetPrefix("app: ")
+	log.Print("test")
+	checkLast(c, t2, "app: test")
+}
+
+func (s *Suite) TestMessagePrefix(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(stdlog.Ldate)
+	log.SetMessagePrefix("db: ").Set("table", "users")
+	c.Assert(log.MessagePrefix(), check.Equals, "db: ")
+
+	log.Warn("slow")
+	c.Assert(t.last(), check.Matches, `\d{4}/\d\d/\d\d WARN db: \[table=users\] slow\n`)
+
+	// Copies keep it
+	log.SetFlags(0)
+	log.With("id", 3).Print("found")
+	checkLast(c, t, "db: [table=users id=3] found")
+	c.Assert(log.Sprint("x"), check.Equals, "db: [table=users] x")
+
+	log.SetFormatter(JSONFormatter{})
+	log.Print("found")
+	checkLast(c, t, `{"level":"info","msg":"db: found","table":"users"}`)
+}
+
+func (s *Suite) TestFieldPosition(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.SetFieldPosition(FieldsAfterMessage).Set("user", "alice")
+
+	log.Warn("login failed")
+	checkLast(c, t, "WARN login failed [user=alice]")
+	log.With("n", 2).Println("retrying")
+	checkLast(c, t, "retrying [user=alice n=2]")
+	log.Print()
+	checkLast(c, t, "[user=alice]")
+	c.Assert(log.Sprintln("x"), check.Equals, "x [user=alice]\n")
+
+	log.SetMessagePrefix("auth: ")
+	log.Print("ok")
+	checkLast(c, t, "auth: ok [user=alice]")
+
+	log.SetFieldPosition(FieldsBeforeMessage)
+	log.Print("ok")
+	checkLast(c, t, "auth: [user=alice] ok")
+}
+
+type levelThief struct {
+	Thief
+	levels []Level
+}
+
+func (t *levelThief) WriteLevel(l Level, p []byte) (int, error) {
+	t.levels = append(t.levels, l)
+	return t.Write(p)
+}
+
+func (s *Suite) TestLevelWriter(c *check.C) {
+	t := &levelThief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Print("a")
+	log.Error("b")
+	c.Assert(t.levels, check.DeepEquals, []Level{InfoLevel, ErrorLevel})
+	c.Assert(t.msgs, check.DeepEquals, []string{"a\n", "ERROR b\n"})
+}
+
+func (s *Suite) TestLocation(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(stdlog.LstdFlags | stdlog.LUTC)
+	log.SetFormatter(JSONFormatter{})
+	log.SetLocation(time.FixedZone("X", 14*3600))
+
+	// Takes precedence over LUTC
+	log.Print("a")
+	c.Assert(t.last(), check.Matches, `\{"time":"[-0-9]+T[:.0-9]+\+14:00",.*\n`)
+
+	// Copies keep it
+	log.Copy().Print("b")
+	c.Assert(t.last(), check.Matches, `\{"time":"[-0-9]+T[:.0-9]+\+14:00",.*\n`)
+
+	log.SetLocation(nil)
+	log.Print("c")
+	c.Assert(t.last(), check.Matches, `\{"time":"[-0-9]+T[:.0-9]+Z",.*\n`)
+
+	// Text header
+	log.SetFormatter(nil)
+	log.SetFlags(stdlog.Ltime)
+	loc := time.FixedZone("Y", -11*3600)
+	log.SetLocation(loc)
+	before := time.Now().In(loc).Format("15:04")
+	log.Print("d")
+	after := time.Now().In(loc).Format("15:04")
+	c.Assert(t.last()[:5] == before || t.last()[:5] == after, check.Equals, true)
+}
+
+func (s *Suite) TestErrorHandler(c *check.C) {
+	f := &flakyWriter{failures: 1}
+	log := New(f)
+	log.SetFlags(0)
+
+	var errs []error
+	c.Assert(log.SetErrorHandler(func(err error) {
+		errs = append(errs, err)
+		// The handler may log
+		log.Print("handled")
+	}), check.Equals, log)
+
+	log.Print("a")
+	c.Assert(errs, check.HasLen, 1)
+	c.Assert(errs[0], check.ErrorMatches, "flaky")
+	c.Assert(f.last(), check.Equals, "handled\n")
+
+	log.With("k", "v").Print("b")
+	c.Assert(errs, check.HasLen, 1)
+
+	// Output reports the error too
+	f.failures = 1
+	c.Assert(log.Output(1, InfoLevel, "c"), check.ErrorMatches, "flaky")
+	c.Assert(errs, check.HasLen, 2)
+
+	log.SetErrorHandler(nil)
+	f.failures = 1
+	log.Print("d")
+	c.Assert(errs, check.HasLen, 2)
+}
@@ -0,0 +1,135 @@
+package alog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Settings for a HECWriter
+type HECConfig struct {
+	// Base URL of the collector, e.g. https://splunk.example.com:8088
+	URL   string
+	Token string
+	// Optional event metadata; empty values use the token's defaults
+	Index      string
+	Source     string
+	SourceType string
+	Host       string
+	// Events per request, 100 by default
+	BatchSize int
+	// How often a partial batch is sent, 1s by default.  Negative for only
+	// when full and on Flush.
+	FlushInterval time.Duration
+	// http.DefaultClient by default
+	Client *http.Client
+}
+
+// Writer that posts entries to the Splunk HTTP Event Collector.  As the
+// logger's writer, each entry's message becomes the event and its fields,
+// with the level, become indexed fields.  Other lines written to it are
+// sent as events as they are.  Close must be called to send the last batch.
+type HECWriter struct {
+	cfg   HECConfig
+	batch *batcher
+}
+
+func NewHECWriter(cfg HECConfig) *HECWriter {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval == 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	w := &HECWriter{cfg: cfg}
+	w.batch = newBatcher(w.send, cfg.BatchSize, 0, cfg.FlushInterval)
+	return w
+}
+
+func (w *HECWriter) Write(p []byte) (int, error) {
+	return len(p), w.batch.add(w.event(time.Now(), string(bytes.TrimRight(p, "\n")), nil))
+}
+
+func (w *HECWriter) WriteEntry(e *Entry, p []byte) (int, error) {
+	fields := append([]Field{{"level", e.Level.String()}}, flattenFields(e.Fields)...)
+	return len(p), w.batch.add(w.event(e.Time, e.Message, fields))
+}
+
+func (w *HECWriter) event(t time.Time, msg string, fields []Field) []byte {
+	b := []byte(`{"time":`)
+	b = strconv.AppendFloat(b, float64(t.UnixNano()/int64(time.Millisecond))/1000, 'f', 3, 64)
+	for _, f := range []Field{
+		{"host", w.cfg.Host},
+		{"source", w.cfg.Source},
+		{"sourcetype", w.cfg.SourceType},
+		{"index", w.cfg.Index},
+	} {
+		if f.Value != "" {
+			b = append(b, ',')
+			b = appendJSONField(b, f.Key, f.Value)
+		}
+	}
+	b = append(b, `,"event":`...)
+	b = appendJSONString(b, msg)
+	if len(fields) > 0 {
+		b = append(b, `,"fields":{`...)
+		for i, f := range fields {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendJSONField(b, f.Key, string(appendValue(nil, f.Value)))
+		}
+		b = append(b, '}')
+	}
+	return append(b, '}')
+}
+
+func (w *HECWriter) send(events [][]byte) error {
+	req, err := http.NewRequest("POST", w.cfg.URL+"/services/collector/event", bytes.NewReader(bytes.Join(events, []byte("\n"))))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Splunk "+w.cfg.Token)
+	req.Header.Set("Content-Type", "application/json")
+	return doRequest(w.cfg.Client, req, "splunk HEC")
+}
+
+// Passes failed background sends to h
+func (w *HECWriter) SetErrorHandler(h func(error)) {
+	w.batch.errs.set(h)
+}
+
+func (w *HECWriter) Status() []SinkStatus {
+	return []SinkStatus{w.batch.status("hec " + w.cfg.URL)}
+}
+
+// Sends the current batch
+func (w *HECWriter) Flush() error {
+	return w.batch.flush()
+}
+
+// Sends the last batch and stops the flush timer
+func (w *HECWriter) Close() error {
+	return w.batch.close()
+}
+
+// Sends req, returning an error naming service for any non-2xx response
+func doRequest(client *http.Client, req *http.Request, service string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("alog: %s: %s: %s", service, resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
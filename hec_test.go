@@ -0,0 +1,74 @@
+package alog
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"gopkg.in/check.v1"
+)
+
+// Records request bodies, responding with status
+type collector struct {
+	status int
+	reqs   []*http.Request
+	bodies []string
+	mutex  sync.Mutex
+}
+
+func (s *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, _ := ioutil.ReadAll(r.Body)
+	s.mutex.Lock()
+	s.reqs = append(s.reqs, r)
+	s.bodies = append(s.bodies, string(b))
+	s.mutex.Unlock()
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		w.Write([]byte("invalid token"))
+	}
+}
+
+func (s *Suite) TestHECWriter(c *check.C) {
+	col := &collector{}
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+
+	w := NewHECWriter(HECConfig{
+		URL:           srv.URL,
+		Token:         "secret",
+		Index:         "main",
+		SourceType:    "_json",
+		BatchSize:     2,
+		FlushInterval: -1,
+	})
+	log := New(w)
+	log.Set("user", "alice")
+	log.Warn("slow")
+	c.Assert(col.bodies, check.HasLen, 0)
+	log.Info().Err(errors.New("x")).Msg("done")
+
+	c.Assert(w.Flush(), check.IsNil)
+	c.Assert(col.bodies, check.HasLen, 1)
+	c.Assert(col.reqs[0].URL.Path, check.Equals, "/services/collector/event")
+	c.Assert(col.reqs[0].Header.Get("Authorization"), check.Equals, "Splunk secret")
+	events := strings.Split(col.bodies[0], "\n")
+	c.Assert(events, check.HasLen, 2)
+	c.Assert(events[0], check.Matches,
+		`\{"time":\d+\.\d{3},"sourcetype":"_json","index":"main","event":"slow","fields":\{"level":"warn","user":"alice"\}\}`)
+	c.Assert(events[1], check.Matches, `.*"event":"done","fields":\{"level":"info","user":"alice","error":"x"\}\}`)
+
+	// Plain writes, and the last batch on Close
+	w.Write([]byte("raw line\n"))
+	c.Assert(w.Close(), check.IsNil)
+	c.Assert(col.bodies, check.HasLen, 2)
+	c.Assert(col.bodies[1], check.Matches, `\{"time":\d+\.\d{3},"sourcetype":"_json","index":"main","event":"raw line"\}`)
+
+	// Errors
+	col.status = http.StatusForbidden
+	w = NewHECWriter(HECConfig{URL: srv.URL, FlushInterval: -1})
+	w.Write([]byte("x\n"))
+	c.Assert(w.Flush(), check.ErrorMatches, "alog: splunk HEC: 403 Forbidden: invalid token")
+}
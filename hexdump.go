@@ -0,0 +1,55 @@
+package alog
+
+import (
+	"encoding/hex"
+	"strconv"
+)
+
+// Bytes shown by Hexdump by default
+const defaultHexdumpMax = 4096
+
+// Writes b as a hex and ASCII dump, in the layout of hex.Dump, as one debug
+// entry headed by label and the length:
+//
+//	DEBUG handshake: 5 bytes
+//	00000000  16 03 01 02 00                                    |.....|
+//
+// The dump is only built if debug entries are enabled.  See SetHexdumpMax.
+func (a *Log) Hexdump(label string, b []byte) {
+	limit := defaultHexdumpMax
+	if a != nil {
+		if !a.enabled(DebugLevel) {
+			return
+		}
+		limit = a.hexdumpMax
+	}
+	a.output(DebugLevel, message{fmtString, hexdump(label, b, limit), nil, nil})
+}
+
+// Limits Hexdump to the first n bytes of each slice, 4096 by default.  The
+// rest of a longer slice is left out.  A negative n shows every byte.
+// Copies keep the setting.
+func (a *Log) SetHexdumpMax(n int) *Log {
+	if a == nil {
+		return nil
+	}
+	a.hexdumpMax = n
+	return a
+}
+
+func hexdump(label string, b []byte, limit int) string {
+	buf := append([]byte(label), ": "...)
+	buf = strconv.AppendInt(buf, int64(len(b)), 10)
+	buf = append(buf, " bytes"...)
+	if limit >= 0 && len(b) > limit {
+		buf = append(buf, ", first "...)
+		buf = strconv.AppendInt(buf, int64(limit), 10)
+		buf = append(buf, " shown"...)
+		b = b[:limit]
+	}
+	if len(b) > 0 {
+		buf = append(buf, '\n')
+		buf = append(buf, hex.Dump(b)...)
+	}
+	return string(buf)
+}
@@ -0,0 +1,43 @@
+package alog
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestHexdump(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.SetLevel(DebugLevel)
+
+	log.Hexdump("handshake", []byte("\x16\x03hello"))
+	c.Assert(t.msgs, check.HasLen, 1)
+	c.Assert(t.last(), check.Equals, "DEBUG handshake: 7 bytes\n"+
+		"00000000  16 03 68 65 6c 6c 6f                              |..hello|\n")
+
+	log.Hexdump("empty", nil)
+	c.Assert(t.last(), check.Equals, "DEBUG empty: 0 bytes\n")
+
+	c.Assert(log.SetHexdumpMax(2), check.Equals, log)
+	log.Hexdump("big", []byte("abcdef"))
+	c.Assert(t.last(), check.Equals, "DEBUG big: 6 bytes, first 2 shown\n"+
+		"00000000  61 62                                             |ab|\n")
+
+	// Copies keep the setting, and negative limits show everything
+	log.Copy().Hexdump("copy", []byte("abc"))
+	c.Assert(t.last(), check.Matches, "DEBUG copy: 3 bytes, first 2 shown\n.*\n")
+	log.SetHexdumpMax(-1)
+	log.Hexdump("all", []byte("abcdef"))
+	c.Assert(t.last(), check.Equals, "DEBUG all: 6 bytes\n"+
+		"00000000  61 62 63 64 65 66                                 |abcdef|\n")
+
+	// Escaped onto one line if configured
+	log.SetMultiline(MultilineEscape)
+	log.Hexdump("x", []byte("a"))
+	c.Assert(t.last(), check.Matches, `DEBUG x: 1 bytes\\n00000000  61 .*\|a\|`+"\n")
+
+	n := len(t.msgs)
+	log.SetLevel(InfoLevel)
+	log.Hexdump("skipped", []byte("a"))
+	c.Assert(t.msgs, check.HasLen, n)
+}
@@ -0,0 +1,81 @@
+package alog
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+)
+
+// Encodes the entries as a JSON object, in insertion order.  Groups become
+// nested objects.
+func (m *Meta) MarshalJSON() ([]byte, error) {
+	return appendJSONObject(nil, m.fields()), nil
+}
+
+// Returns the logger's fields as a JSON object, as they would be for an
+// entry written now
+func (a *Log) ExportJSON() ([]byte, error) {
+	return appendJSONObject(nil, a.formatValues(a.entryFields())), nil
+}
+
+// Same as ExportJSON.  Without it, Meta's MarshalJSON would be promoted and
+// leave out provided fields.
+func (a *Log) MarshalJSON() ([]byte, error) {
+	return a.ExportJSON()
+}
+
+func appendJSONObject(b []byte, fields []Field) []byte {
+	b = append(b, '{')
+	for i, f := range fields {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendJSONField(b, f.Key, f.Value)
+	}
+	return append(b, '}')
+}
+
+func appendJSONField(b []byte, k string, v interface{}) []byte {
+	b = appendJSONKey(b, k)
+	return appendJSONValue(b, v)
+}
+
+func appendJSONKey(b []byte, k string) []byte {
+	return jsonKeys.append(b, k)
+}
+
+// Appends v as JSON.  Errors and fmt.Stringers without their own JSON or
+// text encoding are encoded as their string, and values that can't be
+// encoded as their %+v string.
+func appendJSONValue(b []byte, v interface{}) []byte {
+	v = resolveValue(v)
+	switch x := v.(type) {
+	case json.Marshaler, encoding.TextMarshaler:
+	case GroupValue:
+		return appendJSONObject(b, x)
+	case error:
+		return appendJSONString(b, x.Error())
+	case fmt.Stringer:
+		return appendJSONString(b, x.String())
+	}
+
+	enc, err := json.Marshal(v)
+	if err != nil {
+		return appendJSONString(b, fmt.Sprintf("%+v", v))
+	}
+	return append(b, enc...)
+}
+
+// Strings of printable ASCII that encoding/json leaves alone are quoted
+// directly, without its allocations
+func appendJSONString(b []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c >= 0x7f || c == '"' || c == '\\' || c == '<' || c == '>' || c == '&' {
+			enc, _ := json.Marshal(s)
+			return append(b, enc...)
+		}
+	}
+	b = append(b, '"')
+	b = append(b, s...)
+	return append(b, '"')
+}
@@ -0,0 +1,88 @@
+package alog
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"testing"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestMetaJSON(c *check.C) {
+	m := &Meta{}
+	b, err := json.Marshal(m)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(b), check.Equals, "{}")
+
+	m.set("z", 1)
+	m.set("a", "two")
+	m.set("err", errors.New("bad"))
+	m.set("http", Group("method", "GET", "inner", Group("x", true)))
+	m.set("ch", make(chan int))
+	m.set("list", []int{1, 2})
+	m.set("html", "<b>")
+
+	b, err = json.Marshal(m)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(b), check.Matches, `\{"z":1,"a":"two","err":"bad",`+
+		`"http":\{"method":"GET","inner":\{"x":true\}\},"ch":"0x[0-9a-f]+",`+
+		`"list":\[1,2\],"html":"\\u003cb\\u003e"\}`)
+
+	// Embedded in other structures
+	b, err = json.Marshal(map[string]interface{}{"meta": &Meta{}})
+	c.Assert(err, check.IsNil)
+	c.Assert(string(b), check.Equals, `{"meta":{}}`)
+}
+
+func (s *Suite) TestLogExportJSON(c *check.C) {
+	log := New(&Thief{})
+	log.Set("foo", "bar")
+	log.AddFieldProvider(FieldProviderFunc(func() []Field {
+		return []Field{{"depth", 3}}
+	}))
+
+	b, err := log.ExportJSON()
+	c.Assert(err, check.IsNil)
+	c.Assert(string(b), check.Equals, `{"foo":"bar","depth":3}`)
+	b, err = json.Marshal(log)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(b), check.Equals, `{"foo":"bar","depth":3}`)
+
+	var nilLog *Log
+	b, err = nilLog.ExportJSON()
+	c.Assert(err, check.IsNil)
+	c.Assert(string(b), check.Equals, `{}`)
+}
+
+func (s *Suite) TestAppendJSONString(c *check.C) {
+	for _, v := range []string{"", "plain key", `q"uote`, `back\slash`, "<b>&", "tab\t", "\x7f", "üñí", "\u2028"} {
+		enc, _ := json.Marshal(v)
+		c.Assert(string(appendJSONString(nil, v)), check.Equals, string(enc))
+		c.Assert(string(appendJSONKey(nil, v)), check.Equals, string(enc)+":")
+		// Cached
+		c.Assert(string(appendJSONKey([]byte("x"), v)), check.Equals, "x"+string(enc)+":")
+	}
+}
+
+func (s *Suite) TestKeyCacheCap(c *check.C) {
+	for i := 0; i < maxCachedKeys+10; i++ {
+		k := "cap" + strconv.Itoa(i)
+		c.Assert(string(appendJSONKey(nil, k)), check.Equals, `"`+k+`":`)
+	}
+	jsonKeys.mutex.RLock()
+	c.Assert(len(jsonKeys.m) <= maxCachedKeys, check.Equals, true)
+	jsonKeys.mutex.RUnlock()
+}
+
+func BenchmarkJSONFormatter(b *testing.B) {
+	log := New(struct{ io.Writer }{ioutil.Discard})
+	log.SetFormatter(JSONFormatter{})
+	log.Set("app", "api").Set("request_id", "abc123")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		log.Info().Str("user", "alice").Int("count", 3).Msg("done")
+	}
+}
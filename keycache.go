@@ -0,0 +1,43 @@
+package alog
+
+import "sync"
+
+// Keys are encoded once, e.g. with JSON's quotes and colon, and reused.  A
+// cache is capped so that keys built from data can't grow it without bound;
+// keys beyond it are encoded each time, without taking the write lock.
+type keyCache struct {
+	encode func(b []byte, k string) []byte
+	m      map[string]string
+	mutex  sync.RWMutex
+}
+
+const maxCachedKeys = 4096
+
+var jsonKeys = &keyCache{encode: func(b []byte, k string) []byte {
+	return append(appendJSONString(b, k), ':')
+}}
+
+func (c *keyCache) append(b []byte, k string) []byte {
+	c.mutex.RLock()
+	enc, ok := c.m[k]
+	full := len(c.m) >= maxCachedKeys
+	c.mutex.RUnlock()
+	if ok {
+		return append(b, enc...)
+	}
+
+	start := len(b)
+	b = c.encode(b, k)
+	if full {
+		return b
+	}
+	c.mutex.Lock()
+	if c.m == nil {
+		c.m = map[string]string{}
+	}
+	if len(c.m) < maxCachedKeys {
+		c.m[k] = string(b[start:])
+	}
+	c.mutex.Unlock()
+	return b
+}
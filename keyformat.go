@@ -0,0 +1,93 @@
+package alog
+
+import (
+	"strconv"
+	"time"
+)
+
+// Renders a field's value for output, e.g. to mask or humanize it.  Set
+// with Log.SetKeyFormatter.
+type ValueFormatter func(v interface{}) interface{}
+
+// Renders values as ***
+func Masked(v interface{}) interface{} {
+	return "***"
+}
+
+// Renders integer byte counts with IEC units, e.g. 1536 as 1.5KiB.  Other
+// values are left alone.
+func HumanBytes(v interface{}) interface{} {
+	var n float64
+	switch x := v.(type) {
+	case int:
+		n = float64(x)
+	case int64:
+		n = float64(x)
+	case uint64:
+		n = float64(x)
+	default:
+		return v
+	}
+
+	const units = "KMGTPE"
+	if n < 1024 && n > -1024 {
+		return strconv.FormatFloat(n, 'f', -1, 64) + "B"
+	}
+	i := -1
+	for ; (n >= 1024 || n <= -1024) && i < len(units)-1; i++ {
+		n /= 1024
+	}
+	return strconv.FormatFloat(n, 'f', 1, 64) + units[i:i+1] + "iB"
+}
+
+// Renders time.Durations as a number of milliseconds.  Other values are
+// left alone.
+func Milliseconds(v interface{}) interface{} {
+	if d, ok := v.(time.Duration); ok {
+		return float64(d) / float64(time.Millisecond)
+	}
+	return v
+}
+
+// Renders the value of every field with key k through f, in the prefix and
+// with Formatters.  Keys in groups are matched by their dotted path, e.g.
+// http.duration.  A nil f removes the key's formatter.  Copies keep the
+// setting.
+func (a *Log) SetKeyFormatter(k string, f ValueFormatter) *Log {
+	if a == nil {
+		return nil
+	}
+	// Never modify a map shared with a copy
+	formats := make(map[string]ValueFormatter, len(a.keyFormats)+1)
+	for key, kf := range a.keyFormats {
+		formats[key] = kf
+	}
+	if f == nil {
+		delete(formats, k)
+	} else {
+		formats[k] = f
+	}
+	a.keyFormats = formats
+	return a
+}
+
+// Returns fields with the key formatters applied
+func (a *Log) formatValues(fields []Field) []Field {
+	if a == nil || len(a.keyFormats) == 0 {
+		return fields
+	}
+	return a.formatGroup("", fields)
+}
+
+func (a *Log) formatGroup(group string, fields []Field) []Field {
+	out := make([]Field, len(fields))
+	for i, f := range fields {
+		out[i] = f
+		if kf, ok := a.keyFormats[group+f.Key]; ok {
+			out[i].Value = kf(resolveValue(f.Value))
+		} else if g, ok := resolveValue(f.Value).(GroupValue); ok {
+			out[i].Value = GroupValue(a.formatGroup(group+f.Key+".", g))
+		}
+	}
+	return out
+}
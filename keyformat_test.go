@@ -0,0 +1,45 @@
+package alog
+
+import (
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestKeyFormatter(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	c.Assert(log.SetKeyFormatter("password", Masked), check.Equals, log)
+	log.SetKeyFormatter("size", HumanBytes)
+	log.SetKeyFormatter("http.took", Milliseconds)
+
+	log.Set("password", "hunter2").Set("size", 1536)
+	log.With("http", Group("took", 1500*time.Microsecond, "size", 10)).Print("done")
+	c.Assert(t.last(), check.Equals, "[password=*** size=1.5KiB http.took=1.5 http.size=10] done\n")
+
+	// Copies made before a change keep their formatters
+	cp := log.Copy()
+	log.SetKeyFormatter("password", nil)
+	log.Print("a")
+	c.Assert(t.last(), check.Equals, "[password=hunter2 size=1.5KiB] a\n")
+	cp.Print("b")
+	c.Assert(t.last(), check.Equals, "[password=*** size=1.5KiB] b\n")
+
+	// Formatters and exports
+	cp.SetFormatter(JSONFormatter{})
+	cp.Print("c")
+	c.Assert(t.last(), check.Equals, `{"level":"info","msg":"c","password":"***","size":"1.5KiB"}`+"\n")
+	b, err := cp.ExportJSON()
+	c.Assert(err, check.IsNil)
+	c.Assert(string(b), check.Equals, `{"password":"***","size":"1.5KiB"}`)
+}
+
+func (s *Suite) TestHumanBytes(c *check.C) {
+	c.Assert(HumanBytes(0), check.Equals, "0B")
+	c.Assert(HumanBytes(1023), check.Equals, "1023B")
+	c.Assert(HumanBytes(int64(5)<<30), check.Equals, "5.0GiB")
+	c.Assert(HumanBytes(uint64(1)<<63), check.Equals, "8.0EiB")
+	c.Assert(HumanBytes("x"), check.Equals, "x")
+	c.Assert(Milliseconds(time.Second), check.Equals, 1000.0)
+}
@@ -0,0 +1,103 @@
+package alog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Rules for the keys given to Set and SetFields.  See Log.SetKeyPolicy.
+type KeyPolicy struct {
+	// Rewrites keys as lower snake_case, e.g. UserID and user-id as user_id
+	SnakeCase bool
+	// Lowercases keys, for when SnakeCase is too much
+	Lowercase bool
+	// Characters allowed in keys besides ASCII letters and digits, e.g.
+	// "_.".  Checked after rewriting.  Empty allows any.
+	Allowed string
+}
+
+// Checks and rewrites the keys passed to Set and SetFields, so the same
+// field isn't indexed as both UserID and user_id.  Empty keys, and keys
+// with characters outside the policy's Allowed, are dropped and reported
+// to the error handler.  A nil p removes the policy.  Copies keep it.
+func (a *Log) SetKeyPolicy(p *KeyPolicy) *Log {
+	if a == nil {
+		return nil
+	}
+	if p != nil {
+		cp := *p
+		p = &cp
+	}
+	a.keyPolicy = p
+	return a
+}
+
+var errEmptyKey = errors.New("alog: empty key")
+
+// Sets k to v in the Meta, if the key policy admits k, following the
+// duplicate policy
+func (a *Log) setMeta(k string, v interface{}) {
+	var err error
+	if a.keyPolicy != nil {
+		k, err = a.keyPolicy.apply(k)
+	}
+	if err == nil {
+		if a.duplicates == OverwriteDuplicate {
+			a.Meta.set(k, v)
+		} else {
+			err = a.Meta.setUnique(k, v, a.duplicates)
+		}
+	}
+	if err != nil && a.onError != nil {
+		a.onError(err)
+	}
+}
+
+// Returns k rewritten, or an error if it isn't allowed
+func (p *KeyPolicy) apply(k string) (string, error) {
+	orig := k
+	if p.SnakeCase {
+		k = snakeCase(k)
+	} else if p.Lowercase {
+		k = strings.ToLower(k)
+	}
+	if k == "" {
+		return "", errEmptyKey
+	}
+	if p.Allowed != "" {
+		for _, r := range k {
+			if !(r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))) && !strings.ContainsRune(p.Allowed, r) {
+				return "", fmt.Errorf("alog: key %q has disallowed character %q", orig, r)
+			}
+		}
+	}
+	return k, nil
+}
+
+// Converts camel case, dashes and spaces to lower snake_case.  Runs of
+// capitals are kept together as an initialism: HTTPStatus becomes
+// http_status.
+func snakeCase(s string) string {
+	rs := []rune(s)
+	var b strings.Builder
+	for i, r := range rs {
+		switch {
+		case r == '-' || r == ' ':
+			b.WriteByte('_')
+		case unicode.IsUpper(r):
+			if i > 0 && rs[i-1] != '_' && rs[i-1] != '-' && rs[i-1] != ' ' && rs[i-1] != '.' {
+				prevLower := unicode.IsLower(rs[i-1]) || unicode.IsDigit(rs[i-1])
+				nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+				if prevLower || (nextLower && unicode.IsUpper(rs[i-1])) {
+					b.WriteByte('_')
+				}
+			}
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
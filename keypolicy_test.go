@@ -0,0 +1,35 @@
+package alog
+
+import "gopkg.in/check.v1"
+
+func (s *Suite) TestKeyPolicy(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	var errs []string
+	log.SetErrorHandler(func(err error) { errs = append(errs, err.Error()) })
+	log.SetKeyPolicy(&KeyPolicy{SnakeCase: true, Allowed: "_."})
+
+	log.Set("UserID", 1).Set("requestId", "a").Set("HTTPStatus", 200).Set("cache-hit", true)
+	log.SetFields(Field{"http.Method", "GET"}, Field{"", 1}, Field{"bad key!", 2})
+	log.With("user_id", 2).Print("x")
+	checkLast(c, t, "[user_id=2 request_id=a http_status=200 cache_hit=true http.method=GET] x")
+	c.Assert(errs, check.DeepEquals, []string{
+		"alog: empty key",
+		`alog: key "bad key!" has disallowed character '!'`,
+	})
+
+	log = New(t)
+	log.SetFlags(0)
+	log.SetKeyPolicy(&KeyPolicy{Lowercase: true})
+	log.Set("UserID", 1).Set("", 2).Print("y")
+	checkLast(c, t, "[userid=1] y")
+
+	log.SetKeyPolicy(nil)
+	log.Set("UserID", 3).Print("z")
+	checkLast(c, t, "[userid=1 UserID=3] z")
+
+	c.Assert(snakeCase("ID"), check.Equals, "id")
+	c.Assert(snakeCase("parseHTTPRequest2x"), check.Equals, "parse_http_request2x")
+	c.Assert(snakeCase("already_snake"), check.Equals, "already_snake")
+}
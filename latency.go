@@ -0,0 +1,140 @@
+package alog
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Upper bounds of the latency histogram's buckets.  Writes slower than the
+// last are only counted in the total.
+var latencyBounds = []time.Duration{
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// Snapshot of a LatencyWriter's write durations, in the shape of a
+// Prometheus histogram
+type LatencyStats struct {
+	Count uint64
+	Sum   time.Duration
+	Max   time.Duration
+	// Writes slower than the threshold
+	Slow uint64
+	// Cumulative: each bucket counts the writes that took at most its
+	// bound
+	Buckets []LatencyBucket
+}
+
+type LatencyBucket struct {
+	UpperBound time.Duration
+	Count      uint64
+}
+
+// Writer that measures how long writes to w take, to show whether logging
+// is stalling its callers.  Writes taking longer than threshold are logged
+// as warnings to warn, which should write somewhere else, e.g. stderr, so
+// that a slow sink doesn't report on itself.  warn must not be the logger
+// writing to the LatencyWriter, or a copy of it, since the warning is
+// written before that logger's write returns.  A nil warn only counts
+// them.
+type LatencyWriter struct {
+	w         io.Writer
+	threshold time.Duration
+	warn      *Log
+	warning   int32
+	now       func() time.Time
+
+	stats  LatencyStats
+	counts []uint64
+	mutex  sync.Mutex
+}
+
+func NewLatencyWriter(w io.Writer, threshold time.Duration, warn *Log) *LatencyWriter {
+	return &LatencyWriter{
+		w:         w,
+		threshold: threshold,
+		warn:      warn,
+		now:       time.Now,
+		counts:    make([]uint64, len(latencyBounds)),
+	}
+}
+
+func (l *LatencyWriter) Write(p []byte) (int, error) {
+	start := l.now()
+	n, err := l.w.Write(p)
+	d := l.now().Sub(start)
+
+	l.mutex.Lock()
+	l.stats.Count++
+	l.stats.Sum += d
+	if d > l.stats.Max {
+		l.stats.Max = d
+	}
+	for i, b := range latencyBounds {
+		if d <= b {
+			l.counts[i]++
+			break
+		}
+	}
+	slow := l.threshold > 0 && d > l.threshold
+	if slow {
+		l.stats.Slow++
+	}
+	l.mutex.Unlock()
+
+	// The warning may end up written here too; don't warn about it
+	if slow && l.warn != nil && atomic.CompareAndSwapInt32(&l.warning, 0, 1) {
+		l.warn.output(WarnLevel, message{fmtString, "slow log write", nil, []Field{
+			{"sink", writerName(l.w)},
+			{"elapsed", d},
+			{"threshold", l.threshold},
+			{"bytes", len(p)},
+		}})
+		atomic.StoreInt32(&l.warning, 0)
+	}
+	return n, err
+}
+
+// Returns the write durations so far
+func (l *LatencyWriter) Latency() LatencyStats {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	s := l.stats
+	s.Buckets = make([]LatencyBucket, len(latencyBounds))
+	var total uint64
+	for i, b := range latencyBounds {
+		total += l.counts[i]
+		s.Buckets[i] = LatencyBucket{b, total}
+	}
+	return s
+}
+
+func (l *LatencyWriter) Status() []SinkStatus {
+	return writerStatus(l.w)
+}
+
+func (l *LatencyWriter) Flush() error {
+	return flushWriter(l.w)
+}
+
+func (l *LatencyWriter) Close() error {
+	return closeWriter(l.w)
+}
+
+// Names w as its status does
+func writerName(w io.Writer) string {
+	if s := writerStatus(w); len(s) > 0 {
+		return s[0].Name
+	}
+	return ""
+}
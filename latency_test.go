@@ -0,0 +1,64 @@
+package alog
+
+import (
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestLatencyWriter(c *check.C) {
+	t := &Thief{}
+	warnings := &Thief{}
+	warn := New(warnings)
+	warn.SetFlags(0)
+
+	l := NewLatencyWriter(t, 50*time.Millisecond, warn)
+	var clock time.Time
+	delays := []time.Duration{0, 200 * time.Microsecond, 0, 80 * time.Millisecond, 0, 10 * time.Second}
+	l.now = func() time.Time {
+		clock = clock.Add(delays[0])
+		delays = delays[1:]
+		return clock
+	}
+
+	log := New(l)
+	log.SetFlags(0)
+	log.Print("fast")
+	log.Print("slow")
+	log.Print("very slow")
+	c.Assert(t.msgs, check.DeepEquals, []string{"fast\n", "slow\n", "very slow\n"})
+
+	st := l.Latency()
+	c.Assert(st.Count, check.Equals, uint64(3))
+	c.Assert(st.Sum, check.Equals, 10*time.Second+80200*time.Microsecond)
+	c.Assert(st.Max, check.Equals, 10*time.Second)
+	c.Assert(st.Slow, check.Equals, uint64(2))
+	c.Assert(st.Buckets, check.HasLen, len(latencyBounds))
+	c.Assert(st.Buckets[0], check.Equals, LatencyBucket{100 * time.Microsecond, 0})
+	c.Assert(st.Buckets[1], check.Equals, LatencyBucket{500 * time.Microsecond, 1})
+	c.Assert(st.Buckets[6], check.Equals, LatencyBucket{100 * time.Millisecond, 2})
+	c.Assert(st.Buckets[len(st.Buckets)-1].Count, check.Equals, uint64(2))
+
+	c.Assert(warnings.msgs, check.DeepEquals, []string{
+		"WARN [sink=*alog.Thief elapsed=80ms threshold=50ms bytes=5] slow log write\n",
+		"WARN [sink=*alog.Thief elapsed=10s threshold=50ms bytes=10] slow log write\n",
+	})
+}
+
+func (s *Suite) TestLatencyWriterWarnsItself(c *check.C) {
+	t := &Thief{}
+	l := NewLatencyWriter(t, time.Nanosecond, nil)
+	var clock time.Time
+	l.now = func() time.Time {
+		clock = clock.Add(time.Millisecond)
+		return clock
+	}
+	l.warn = New(l)
+	l.warn.SetFlags(0)
+
+	// The warning's own slow write isn't warned about
+	l.Write([]byte("x\n"))
+	c.Assert(t.msgs, check.HasLen, 2)
+	c.Assert(t.msgs[1], check.Equals, "WARN [sink=*alog.Thief elapsed=1ms threshold=1ns bytes=2] slow log write\n")
+	c.Assert(l.Latency().Slow, check.Equals, uint64(2))
+}
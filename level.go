@@ -0,0 +1,195 @@
+package alog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// Severity of an entry.  Entries below a logger's level are not written.
+type Level int32
+
+const (
+	DebugLevel Level = iota - 1
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+	PanicLevel
+	FatalLevel
+)
+
+var levelNames = map[Level]string{
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warn",
+	ErrorLevel: "error",
+	PanicLevel: "panic",
+	FatalLevel: "fatal",
+}
+
+func (l Level) String() string {
+	if s, ok := levelNames[l]; ok {
+		return s
+	}
+	return fmt.Sprintf("level(%d)", int32(l))
+}
+
+// Parses a level name, as returned by String.  Case insensitive.
+func ParseLevel(s string) (Level, error) {
+	var l Level
+	err := l.UnmarshalText([]byte(s))
+	return l, err
+}
+
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func (l *Level) UnmarshalText(b []byte) error {
+	s := strings.ToLower(string(b))
+	for k, v := range levelNames {
+		if v == s {
+			*l = k
+			return nil
+		}
+	}
+	return fmt.Errorf("alog: unknown level %q", string(b))
+}
+
+// Text output marks every level but info, which is the default
+func (l Level) tag() string {
+	if l == InfoLevel {
+		return ""
+	}
+	return strings.ToUpper(l.String()) + " "
+}
+
+// Level that can be changed while in use.  A logger and its copies share
+// one LevelVar, so changing it affects them all.  The zero value is
+// InfoLevel.
+//
+// LevelVar is an http.Handler: GET reports the level as {"level":"info"}
+// and PUT changes it, from a JSON body of the same form or a level form
+// value.
+type LevelVar struct {
+	v int32
+}
+
+func (v *LevelVar) Level() Level {
+	if v == nil {
+		return InfoLevel
+	}
+	return Level(atomic.LoadInt32(&v.v))
+}
+
+func (v *LevelVar) Set(l Level) {
+	atomic.StoreInt32(&v.v, int32(l))
+}
+
+func (v *LevelVar) String() string {
+	return v.Level().String()
+}
+
+type levelPayload struct {
+	Level *Level `json:"level,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+func (v *LevelVar) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+
+	switch r.Method {
+	case "GET":
+	case "PUT":
+		l, err := decodeLevel(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(levelPayload{Error: err.Error()})
+			return
+		}
+		v.Set(l)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		enc.Encode(levelPayload{Error: "only GET and PUT are supported"})
+		return
+	}
+
+	l := v.Level()
+	enc.Encode(levelPayload{Level: &l})
+}
+
+func decodeLevel(r *http.Request) (Level, error) {
+	if r.Header.Get("Content-Type") == "application/x-www-form-urlencoded" {
+		return ParseLevel(r.FormValue("level"))
+	}
+
+	var p levelPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		return 0, fmt.Errorf("alog: malformed level request: %v", err)
+	}
+	if p.Level == nil {
+		return 0, fmt.Errorf("alog: level request has no level")
+	}
+	return *p.Level, nil
+}
+
+// Sets the minimum level written.  This changes the LevelVar shared with the
+// logger's copies; use SetLevelVar to give a component its own.
+func (a *Log) SetLevel(l Level) *Log {
+	if a == nil {
+		return nil
+	}
+	a.level.Set(l)
+	return a
+}
+
+// Makes the logger use v for its level, e.g. so that one component's level
+// can be changed independently of the logger it was copied from
+func (a *Log) SetLevelVar(v *LevelVar) *Log {
+	if a == nil {
+		return nil
+	}
+	a.level = v
+	return a
+}
+
+// Returns the logger's LevelVar, for changing the level at runtime
+func (a *Log) LevelVar() *LevelVar {
+	if a == nil {
+		return nil
+	}
+	return a.level
+}
+
+// Returns the minimum level written
+func (a *Log) Level() Level {
+	if a == nil {
+		return InfoLevel
+	}
+	return a.level.Level()
+}
+
+// Reports whether an entry at level would be written, so callers can skip
+// building expensive arguments:
+//
+//	if log.DebugEnabled() {
+//		log.Debugf("state: %s", dumpState())
+//	}
+//
+// It accounts for the level, sampling, trace buffers and an io.Discard
+// writer.
+func (a *Log) Enabled(level Level) bool {
+	if a == nil {
+		return true
+	}
+	return a.enabled(level)
+}
+
+// Shorthand for Enabled(DebugLevel)
+func (a *Log) DebugEnabled() bool {
+	return a.Enabled(DebugLevel)
+}
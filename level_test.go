@@ -0,0 +1,138 @@
+package alog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestParseLevel(c *check.C) {
+	for l, name := range levelNames {
+		p, err := ParseLevel(name)
+		c.Assert(err, check.IsNil)
+		c.Assert(p, check.Equals, l)
+		c.Assert(l.String(), check.Equals, name)
+	}
+
+	p, err := ParseLevel("WARN")
+	c.Assert(err, check.IsNil)
+	c.Assert(p, check.Equals, WarnLevel)
+
+	_, err = ParseLevel("loud")
+	c.Assert(err, check.ErrorMatches, `alog: unknown level "loud"`)
+
+	c.Assert(Level(42).String(), check.Equals, "level(42)")
+}
+
+func (s *Suite) TestLevels(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("foo", "bar")
+	c.Assert(log.Level(), check.Equals, InfoLevel)
+
+	// Debug is disabled by default
+	log.Debug("x")
+	c.Assert(t.msgs, check.HasLen, 0)
+
+	log.Print("info")
+	checkLast(c, t, "[foo=bar] info")
+	log.Warnf("%d", 7)
+	checkLast(c, t, "WARN [foo=bar] 7")
+	log.Errorln("a", "b")
+	checkLast(c, t, "ERROR [foo=bar] a b")
+	c.Assert(func() { log.Panic("p") }, check.Panics, "p")
+	checkLast(c, t, "PANIC [foo=bar] p")
+
+	// Copies share the level
+	log2 := log.With("x", 1)
+	c.Assert(log.SetLevel(DebugLevel), check.Equals, log)
+	log2.Debug("debug")
+	checkLast(c, t, "DEBUG [foo=bar x=1] debug")
+
+	// Unless given their own
+	log2.SetLevelVar(&LevelVar{})
+	n := len(t.msgs)
+	log2.Debug("debug")
+	c.Assert(t.msgs, check.HasLen, n)
+	log.Debug("debug")
+	c.Assert(t.msgs, check.HasLen, n+1)
+
+	// Panic is written at any level
+	log.SetLevel(FatalLevel)
+	log.Error("x")
+	c.Assert(t.msgs, check.HasLen, n+1)
+	c.Assert(func() { log.Panicf("p") }, check.Panics, "p")
+	checkLast(c, t, "PANIC [foo=bar] p")
+
+	var nilLog *Log
+	c.Assert(nilLog.SetLevel(DebugLevel), check.IsNil)
+	c.Assert(nilLog.SetLevelVar(nil), check.IsNil)
+	c.Assert(nilLog.LevelVar(), check.IsNil)
+	c.Assert(nilLog.Level(), check.Equals, InfoLevel)
+	nilLog.Debug("x")
+	nilLog.Warn("x")
+	nilLog.Error("x")
+}
+
+func (s *Suite) TestLevelHandler(c *check.C) {
+	v := &LevelVar{}
+
+	serve := func(method, ctype, body string) (int, string) {
+		req := httptest.NewRequest(method, "/", strings.NewReader(body))
+		if ctype != "" {
+			req.Header.Set("Content-Type", ctype)
+		}
+		w := httptest.NewRecorder()
+		v.ServeHTTP(w, req)
+		return w.Code, w.Body.String()
+	}
+
+	code, body := serve("GET", "", "")
+	c.Assert(code, check.Equals, http.StatusOK)
+	c.Assert(body, check.Equals, `{"level":"info"}`+"\n")
+
+	code, body = serve("PUT", "application/json", `{"level":"debug"}`)
+	c.Assert(code, check.Equals, http.StatusOK)
+	c.Assert(body, check.Equals, `{"level":"debug"}`+"\n")
+	c.Assert(v.Level(), check.Equals, DebugLevel)
+
+	code, body = serve("PUT", "application/x-www-form-urlencoded", "level=error")
+	c.Assert(code, check.Equals, http.StatusOK)
+	c.Assert(body, check.Equals, `{"level":"error"}`+"\n")
+	c.Assert(v.Level(), check.Equals, ErrorLevel)
+
+	code, body = serve("PUT", "", `{"level":"loud"}`)
+	c.Assert(code, check.Equals, http.StatusBadRequest)
+	c.Assert(body, check.Matches, `\{"error":".*unknown level.*"\}`+"\n")
+	c.Assert(v.Level(), check.Equals, ErrorLevel)
+
+	code, _ = serve("PUT", "", `{}`)
+	c.Assert(code, check.Equals, http.StatusBadRequest)
+
+	code, _ = serve("POST", "", "")
+	c.Assert(code, check.Equals, http.StatusMethodNotAllowed)
+}
+
+func (s *Suite) TestEnabled(c *check.C) {
+	log := New(&Thief{})
+	log.SetLevel(WarnLevel)
+	c.Assert(log.Enabled(InfoLevel), check.Equals, false)
+	c.Assert(log.Enabled(WarnLevel), check.Equals, true)
+	c.Assert(log.Enabled(FatalLevel), check.Equals, true)
+	c.Assert(log.DebugEnabled(), check.Equals, false)
+	log.SetLevel(DebugLevel)
+	c.Assert(log.DebugEnabled(), check.Equals, true)
+
+	// Sampled out
+	log.SetSampling("trace", 0)
+	c.Assert(log.With("trace", "t1").Enabled(ErrorLevel), check.Equals, false)
+	c.Assert(log.With("trace", "t1").Enabled(PanicLevel), check.Equals, true)
+
+	c.Assert(Nop().Enabled(FatalLevel), check.Equals, false)
+
+	var nilLog *Log
+	c.Assert(nilLog.DebugEnabled(), check.Equals, true)
+}
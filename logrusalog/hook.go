@@ -0,0 +1,88 @@
+// Package logrusalog routes logrus entries into an alog logger, so code
+// still on logrus shares alog's output pipeline.
+package logrusalog
+
+import (
+	"io/ioutil"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/xsleonard/alog"
+)
+
+// logrus.Hook that writes each entry to an alog logger.  The entry's Fields
+// are added to the logger's Meta, sorted by key.  Under Lshortfile or
+// Llongfile, the caller is the one logrus reports with ReportCaller, or
+// else the first caller outside logrus.  Logrus still writes to its own
+// Out; use Install to discard that.
+type Hook struct {
+	log *alog.Log
+}
+
+func NewHook(log *alog.Log) *Hook {
+	return &Hook{log}
+}
+
+// Adds a Hook for log to l and discards l's own output, so entries are only
+// written by alog.  The logrus level should allow everything alog's does,
+// e.g. l.SetLevel(logrus.TraceLevel).
+func Install(l *logrus.Logger, log *alog.Log) {
+	l.AddHook(NewHook(log))
+	l.SetOutput(ioutil.Discard)
+}
+
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *Hook) Fire(e *logrus.Entry) error {
+	log := h.log
+	if len(e.Data) > 0 {
+		log = log.Copy()
+		keys := make([]string, 0, len(e.Data))
+		for k := range e.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			log.Set(k, e.Data[k])
+		}
+	}
+	// Logrus panics or exits after firing the hooks for Panic and Fatal
+	// entries itself
+	return log.Output(callerDepth(e.Caller), levels[e.Level], e.Message)
+}
+
+// Returns the calldepth at which Output, called from Fire, finds caller,
+// or the first frame outside logrus if caller is nil
+func callerDepth(caller *runtime.Frame) int {
+	var pcs [64]uintptr
+	// Skip runtime.Callers, this function and Fire
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for depth := 2; ; depth++ {
+		f, more := frames.Next()
+		if caller != nil {
+			if f.File == caller.File && f.Line == caller.Line {
+				return depth
+			}
+		} else if !strings.HasPrefix(f.Function, "github.com/sirupsen/logrus.") {
+			return depth
+		}
+		if !more {
+			return 1
+		}
+	}
+}
+
+var levels = map[logrus.Level]alog.Level{
+	logrus.TraceLevel: alog.DebugLevel,
+	logrus.DebugLevel: alog.DebugLevel,
+	logrus.InfoLevel:  alog.InfoLevel,
+	logrus.WarnLevel:  alog.WarnLevel,
+	logrus.ErrorLevel: alog.ErrorLevel,
+	logrus.FatalLevel: alog.FatalLevel,
+	logrus.PanicLevel: alog.PanicLevel,
+}
@@ -0,0 +1,81 @@
+package logrusalog
+
+import (
+	"errors"
+	"fmt"
+	stdlog "log"
+	"runtime"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/xsleonard/alog"
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type Suite struct{}
+
+var _ = check.Suite(&Suite{})
+
+type Thief struct {
+	msgs []string
+}
+
+func (t *Thief) Write(s []byte) (int, error) {
+	t.msgs = append(t.msgs, string(s))
+	return len(s), nil
+}
+
+func (s *Suite) TestHook(c *check.C) {
+	t := &Thief{}
+	log := alog.New(t)
+	log.SetFlags(0)
+	log.Set("app", "api")
+
+	l := logrus.New()
+	l.SetLevel(logrus.TraceLevel)
+	Install(l, log)
+
+	l.WithFields(logrus.Fields{"user": "alice", "n": 3}).Info("hello")
+	l.Trace("hidden")
+	l.WithError(errors.New("timeout")).Warn("slow")
+	l.Error("failed")
+
+	c.Assert(t.msgs, check.DeepEquals, []string{
+		"[app=api n=3 user=alice] hello\n",
+		"WARN [app=api error='timeout'] slow\n",
+		"ERROR [app=api] failed\n",
+	})
+
+	log.SetLevel(alog.DebugLevel)
+	l.Trace("shown")
+	c.Assert(t.msgs[len(t.msgs)-1], check.Equals, "DEBUG [app=api] shown\n")
+
+	// Logrus does the panicking
+	func() {
+		defer func() { c.Assert(recover(), check.NotNil) }()
+		l.Panic("boom")
+	}()
+	c.Assert(t.msgs[len(t.msgs)-1], check.Equals, "PANIC [app=api] boom\n")
+}
+
+func (s *Suite) TestHookCaller(c *check.C) {
+	t := &Thief{}
+	log := alog.New(t)
+	log.SetFlags(stdlog.Lshortfile)
+
+	l := logrus.New()
+	Install(l, log)
+	_, _, line, _ := runtime.Caller(0)
+	l.Info("plain")
+	l.WithField("k", "v").Info("with fields")
+	l.SetReportCaller(true)
+	l.Info("reported")
+
+	c.Assert(t.msgs, check.DeepEquals, []string{
+		fmt.Sprintf("hook_test.go:%d: plain\n", line+1),
+		fmt.Sprintf("hook_test.go:%d: [k=v] with fields\n", line+2),
+		fmt.Sprintf("hook_test.go:%d: reported\n", line+4),
+	})
+}
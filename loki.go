@@ -0,0 +1,144 @@
+package alog
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Settings for a LokiWriter
+type LokiConfig struct {
+	// Base URL, e.g. http://loki:3100
+	URL string
+	// Meta keys whose values become stream labels instead of staying in the
+	// line.  "level" labels the entry's level.
+	Labels []string
+	// Labels added to every stream, e.g. {"app": "api"}
+	StaticLabels map[string]string
+	// Sent as X-Scope-OrgID, for multi-tenant Loki
+	TenantID string
+	// Entries per request, 100 by default
+	BatchSize int
+	// How often a partial batch is sent, 1s by default.  Negative for only
+	// when full and on Flush.
+	FlushInterval time.Duration
+	// http.DefaultClient by default
+	Client *http.Client
+}
+
+// Writer that pushes entries to Grafana Loki's push API.  As the logger's
+// writer, the fields named in Labels become the entry's stream labels, and
+// the line keeps the level, the other fields and the message.  Other lines
+// written to it are sent as they are, with the static labels.  Close must be
+// called to send the last batch.
+type LokiWriter struct {
+	cfg    LokiConfig
+	labels map[string]bool
+	batch  *batcher
+}
+
+func NewLokiWriter(cfg LokiConfig) *LokiWriter {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval == 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	w := &LokiWriter{cfg: cfg, labels: make(map[string]bool, len(cfg.Labels))}
+	for _, k := range cfg.Labels {
+		w.labels[k] = true
+	}
+	w.batch = newBatcher(w.send, cfg.BatchSize, 0, cfg.FlushInterval)
+	return w
+}
+
+func (w *LokiWriter) Write(p []byte) (int, error) {
+	return len(p), w.batch.add(w.stream(time.Now(), nil, bytes.TrimRight(p, "\n")))
+}
+
+func (w *LokiWriter) WriteEntry(e *Entry, p []byte) (int, error) {
+	var labels, rest []Field
+	if w.labels["level"] {
+		labels = append(labels, Field{"level", e.Level.String()})
+	}
+	for _, f := range flattenFields(e.Fields) {
+		if w.labels[f.Key] && f.Key != "level" {
+			labels = append(labels, f)
+		} else {
+			rest = append(rest, f)
+		}
+	}
+
+	line := []byte(e.Level.tag())
+	if len(rest) > 0 {
+		line = append(line, '[')
+		line = appendFields(line, rest, " ")
+		line = append(line, "] "...)
+	}
+	line = append(line, e.Message...)
+	return len(p), w.batch.add(w.stream(e.Time, labels, line))
+}
+
+// Encodes a stream with a single value
+func (w *LokiWriter) stream(t time.Time, labels []Field, line []byte) []byte {
+	all := make([]Field, 0, len(w.cfg.StaticLabels)+len(labels))
+	for k, v := range w.cfg.StaticLabels {
+		all = append(all, Field{k, v})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Key < all[j].Key })
+	all = append(all, labels...)
+
+	b := []byte(`{"stream":{`)
+	for i, f := range all {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendJSONField(b, f.Key, string(appendValue(nil, f.Value)))
+	}
+	b = append(b, `},"values":[["`...)
+	b = strconv.AppendInt(b, t.UnixNano(), 10)
+	b = append(b, `",`...)
+	b = appendJSONString(b, string(line))
+	return append(b, "]]}"...)
+}
+
+func (w *LokiWriter) send(streams [][]byte) error {
+	var body bytes.Buffer
+	body.WriteString(`{"streams":[`)
+	body.Write(bytes.Join(streams, []byte(",")))
+	body.WriteString("]}")
+
+	req, err := http.NewRequest("POST", w.cfg.URL+"/loki/api/v1/push", &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.cfg.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", w.cfg.TenantID)
+	}
+	return doRequest(w.cfg.Client, req, "loki")
+}
+
+// Passes failed background sends to h
+func (w *LokiWriter) SetErrorHandler(h func(error)) {
+	w.batch.errs.set(h)
+}
+
+func (w *LokiWriter) Status() []SinkStatus {
+	return []SinkStatus{w.batch.status("loki " + w.cfg.URL)}
+}
+
+// Sends the current batch
+func (w *LokiWriter) Flush() error {
+	return w.batch.flush()
+}
+
+// Sends the last batch and stops the flush timer
+func (w *LokiWriter) Close() error {
+	return w.batch.close()
+}
@@ -0,0 +1,39 @@
+package alog
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestLokiWriter(c *check.C) {
+	col := &collector{}
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+
+	w := NewLokiWriter(LokiConfig{
+		URL:           srv.URL,
+		Labels:        []string{"level", "app"},
+		StaticLabels:  map[string]string{"host": "h1", "env": "prod"},
+		TenantID:      "team-a",
+		FlushInterval: -1,
+	})
+	log := New(w)
+	log.Set("app", "api").Set("user", "alice")
+	log.Warn("slow")
+	w.Write([]byte("raw\n"))
+	c.Assert(w.Close(), check.IsNil)
+
+	c.Assert(col.bodies, check.HasLen, 1)
+	c.Assert(col.reqs[0].URL.Path, check.Equals, "/loki/api/v1/push")
+	c.Assert(col.reqs[0].Header.Get("X-Scope-OrgID"), check.Equals, "team-a")
+	c.Assert(col.bodies[0], check.Matches, `\{"streams":\[`+
+		`\{"stream":\{"env":"prod","host":"h1","level":"warn","app":"api"\},"values":\[\["\d+","WARN \[user=alice\] slow"\]\]\},`+
+		`\{"stream":\{"env":"prod","host":"h1"\},"values":\[\["\d+","raw"\]\]\}\]\}`)
+
+	col.status = http.StatusBadRequest
+	w = NewLokiWriter(LokiConfig{URL: srv.URL, FlushInterval: -1})
+	w.Write([]byte("x\n"))
+	c.Assert(w.Flush(), check.ErrorMatches, "alog: loki: 400 Bad Request: invalid token")
+}
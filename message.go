@@ -0,0 +1,83 @@
+package alog
+
+import (
+	"fmt"
+	"sync"
+)
+
+// How a message's arguments are formatted
+const (
+	fmtPrint   = iota // fmt.Sprint
+	fmtPrintf         // fmt.Sprintf
+	fmtPrintln        // fmt.Sprintln
+	fmtString         // Already formatted, in f
+)
+
+// Message arguments, formatted only once the entry is known to be written,
+// and fields belonging to this entry alone
+type message struct {
+	kind   int
+	f      string
+	v      []interface{}
+	fields []Field
+}
+
+func (m message) appendTo(b []byte) []byte {
+	switch m.kind {
+	case fmtPrintf:
+		return fmt.Appendf(b, m.f, m.v...)
+	case fmtPrintln:
+		return fmt.Appendln(b, m.v...)
+	case fmtString:
+		return append(b, m.f...)
+	default:
+		return fmt.Append(b, m.v...)
+	}
+}
+
+// Entries are formatted into pooled buffers.  Buffers grown beyond
+// maxPooledBuf by an unusually large entry are left for the GC.
+const maxPooledBuf = 64 << 10
+
+var bufPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 512)
+		return &b
+	},
+}
+
+func getBuf() *[]byte {
+	return bufPool.Get().(*[]byte)
+}
+
+func putBuf(b *[]byte) {
+	if cap(*b) > maxPooledBuf {
+		return
+	}
+	*b = (*b)[:0]
+	bufPool.Put(b)
+}
+
+// Entries' fields are gathered into pooled slices too
+const maxPooledFields = 256
+
+var fieldPool = sync.Pool{
+	New: func() interface{} {
+		f := make([]Field, 0, 16)
+		return &f
+	},
+}
+
+func getFields() *[]Field {
+	return fieldPool.Get().(*[]Field)
+}
+
+// Clears the slice, so the pool doesn't keep values alive
+func putFields(f *[]Field) {
+	if cap(*f) > maxPooledFields {
+		return
+	}
+	clear(*f)
+	*f = (*f)[:0]
+	fieldPool.Put(f)
+}
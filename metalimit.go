@@ -0,0 +1,120 @@
+package alog
+
+// What happens when a Meta limit would be exceeded.  See Log.SetMetaLimit.
+type MetaPolicy int
+
+const (
+	// Removes the oldest entries to make room
+	EvictOldest MetaPolicy = iota
+	// Keeps the current entries and drops the new one
+	RejectNew
+)
+
+type metaLimit struct {
+	maxEntries int
+	maxBytes   int
+	policy     MetaPolicy
+}
+
+// Caps the logger's Meta at maxEntries keys and maxBytes of k=v text in the
+// prefix, so long-lived loggers can't accrete fields without bound.  Zero
+// limits are unlimited.  When a Set would go over a limit, policy decides
+// whether older keys are evicted or the new one is dropped, and entries
+// then carry a meta_trimmed=N field counting the keys removed or refused.
+// Sizes are measured when values are set.  Existing entries over the new
+// limits are trimmed immediately: the oldest for EvictOldest, or the newest
+// for RejectNew.  Copies keep the limits.
+func (a *Log) SetMetaLimit(maxEntries, maxBytes int, policy MetaPolicy) *Log {
+	if a == nil {
+		return nil
+	}
+	a.Meta.setLimit(metaLimit{maxEntries, maxBytes, policy})
+	return a
+}
+
+func (m *Meta) setLimit(l metaLimit) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.limit = l
+	m.applyLimit()
+}
+
+// Measures the entries for the limit and trims them to fit.  Called with
+// the lock held.
+func (m *Meta) applyLimit() {
+	m.own()
+	m.size = 0
+	for k, e := range m.entries {
+		e.size = m.entrySize(k, e.value)
+		m.entries[k] = e
+		m.size += e.size
+	}
+	for len(m.keys) > 0 && m.over(0, 0) {
+		victim := m.keys[0]
+		if m.limit.policy == RejectNew {
+			victim = m.keys[len(m.keys)-1]
+		}
+		m.remove(victim)
+		m.trimmed++
+	}
+}
+
+// Makes room for setting k to v, evicting entries if the policy allows.
+// Returns the entry's size, and false if it must be dropped.  Called with
+// the lock held.
+func (m *Meta) admit(k string, v interface{}) (int, bool) {
+	size := m.entrySize(k, v)
+	if m.limit == (metaLimit{}) {
+		return size, true
+	}
+
+	old, exists := m.entries[k]
+	added := 1
+	if exists {
+		added = 0
+	}
+	if m.limit.maxBytes > 0 && size > m.limit.maxBytes {
+		m.trimmed++
+		return 0, false
+	}
+	for m.over(added, size-old.size) {
+		if m.limit.policy == RejectNew {
+			m.trimmed++
+			return 0, false
+		}
+		m.evictOldest(k)
+	}
+	return size, true
+}
+
+// Reports whether adding n entries and delta bytes would exceed the limit
+func (m *Meta) over(n, delta int) bool {
+	return (m.limit.maxEntries > 0 && len(m.keys)+n > m.limit.maxEntries) ||
+		(m.limit.maxBytes > 0 && m.size+delta > m.limit.maxBytes)
+}
+
+// Removes the oldest entry other than keep
+func (m *Meta) evictOldest(keep string) {
+	for _, k := range m.keys {
+		if k != keep {
+			m.remove(k)
+			m.trimmed++
+			return
+		}
+	}
+}
+
+// Length of k=v and its separator in the prefix, if there is a byte limit
+func (m *Meta) entrySize(k string, v interface{}) int {
+	if m.limit.maxBytes <= 0 {
+		return 0
+	}
+	return len(appendField(nil, "", k, v, " ")) + 1
+}
+
+func (m *Meta) trimmedCount() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.trimmed
+}
@@ -0,0 +1,66 @@
+package alog
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestMetaLimitEntries(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	c.Assert(log.SetMetaLimit(2, 0, EvictOldest), check.Equals, log)
+
+	log.Set("a", 1).Set("b", 2).Set("b", 3)
+	log.Print("x")
+	c.Assert(t.last(), check.Equals, "[a=1 b=3] x\n")
+	log.Set("c", 4)
+	log.Print("x")
+	c.Assert(t.last(), check.Equals, "[b=3 c=4 meta_trimmed=1] x\n")
+
+	// Copies keep the limit
+	log.With("d", 5).Print("x")
+	c.Assert(t.last(), check.Equals, "[c=4 d=5 meta_trimmed=2] x\n")
+
+	log = New(t)
+	log.SetFlags(0)
+	log.SetMetaLimit(2, 0, RejectNew)
+	log.Set("a", 1).Set("b", 2).Set("c", 3).Set("a", 9)
+	log.Print("x")
+	c.Assert(t.last(), check.Equals, "[a=9 b=2 meta_trimmed=1] x\n")
+}
+
+func (s *Suite) TestMetaLimitBytes(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	// Each k=v below takes 4 bytes with its separator
+	log.Set("a", 1).Set("b", 2).Set("c", 3)
+
+	// Applied to existing entries
+	log.SetMetaLimit(0, 9, EvictOldest)
+	log.Print("x")
+	c.Assert(t.last(), check.Equals, "[b=2 c=3 meta_trimmed=1] x\n")
+
+	log.Set("c", "long")
+	log.Print("x")
+	c.Assert(t.last(), check.Equals, "[c=long meta_trimmed=2] x\n")
+
+	// Too large on its own
+	log.Set("d", "0123456789")
+	log.Print("x")
+	c.Assert(t.last(), check.Equals, "[c=long meta_trimmed=3] x\n")
+
+	log = New(t)
+	log.SetFlags(0)
+	log.Set("a", 1).Set("b", 2).Set("c", 3)
+	log.SetMetaLimit(0, 9, RejectNew)
+	log.Set("a", "grown")
+	log.Print("x")
+	c.Assert(t.last(), check.Equals, "[a=1 b=2 meta_trimmed=2] x\n")
+
+	// Deleting makes room
+	log.Meta.del("a")
+	log.Set("d", 4)
+	log.Print("x")
+	c.Assert(t.last(), check.Equals, "[b=2 d=4 meta_trimmed=2] x\n")
+}
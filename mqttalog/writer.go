@@ -0,0 +1,128 @@
+// Package mqttalog publishes alog entries over MQTT, for devices whose only
+// path to the backend is a broker.
+package mqttalog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/xsleonard/alog"
+)
+
+// The client method used; mqtt.Client implements it
+type Publisher interface {
+	Publish(topic string, qos byte, retained bool, payload interface{}) mqtt.Token
+}
+
+// Settings for a Writer
+type Config struct {
+	// Topic to publish to.  {key} is replaced by the entry's value for the
+	// field key, and {level} by its level, e.g. "devices/{device_id}/logs".
+	// Missing values become "unknown", and characters not allowed in a
+	// topic level become "_".
+	Topic    string
+	QoS      byte
+	Retained bool
+	// How long to wait for each publish to complete, 5s by default.
+	// Negative to not wait, which also drops publish errors.
+	Timeout time.Duration
+}
+
+// Writer publishing each entry to a topic.  As the logger's writer, the
+// topic can be templated from the entry's fields.
+type Writer struct {
+	client Publisher
+	cfg    Config
+}
+
+func New(client Publisher, cfg Config) *Writer {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	return &Writer{client, cfg}
+}
+
+// Connects to the brokers in opts in the background, retrying and
+// reconnecting automatically, and returns a Writer using the client.
+// Entries written while disconnected are queued by the client until it
+// connects, or fail once Timeout passes.
+func Dial(opts *mqtt.ClientOptions, cfg Config) *Writer {
+	opts.SetAutoReconnect(true)
+	opts.SetConnectRetry(true)
+	client := mqtt.NewClient(opts)
+	client.Connect()
+	return New(client, cfg)
+}
+
+var errTimeout = errors.New("alog: mqtt publish timed out")
+
+func (w *Writer) Write(p []byte) (int, error) {
+	return len(p), w.publish(w.topic(alog.InfoLevel, nil), p)
+}
+
+func (w *Writer) WriteEntry(e *alog.Entry, p []byte) (int, error) {
+	return len(p), w.publish(w.topic(e.Level, e.Fields), p)
+}
+
+func (w *Writer) publish(topic string, p []byte) error {
+	p = bytes.TrimRight(p, "\n")
+	t := w.client.Publish(topic, w.cfg.QoS, w.cfg.Retained, append([]byte(nil), p...))
+	if w.cfg.Timeout < 0 {
+		return nil
+	}
+	if !t.WaitTimeout(w.cfg.Timeout) {
+		return errTimeout
+	}
+	return t.Error()
+}
+
+func (w *Writer) topic(level alog.Level, fields []alog.Field) string {
+	if !strings.Contains(w.cfg.Topic, "{") {
+		return w.cfg.Topic
+	}
+
+	var b strings.Builder
+	s := w.cfg.Topic
+	for {
+		i := strings.IndexByte(s, '{')
+		j := -1
+		if i >= 0 {
+			j = strings.IndexByte(s[i+1:], '}')
+		}
+		if j < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		b.WriteString(s[:i])
+		b.WriteString(topicLevel(lookup(s[i+1:i+1+j], level, fields)))
+		s = s[i+j+2:]
+	}
+}
+
+func lookup(key string, level alog.Level, fields []alog.Field) string {
+	if key == "level" {
+		return level.String()
+	}
+	v := "unknown"
+	for _, f := range fields {
+		if f.Key == key {
+			v = fmt.Sprintf("%+v", f.Value)
+		}
+	}
+	return v
+}
+
+// Replaces the characters that would change the topic's structure
+func topicLevel(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '+', '#', 0:
+			return '_'
+		}
+		return r
+	}, s)
+}
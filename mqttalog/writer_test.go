@@ -0,0 +1,74 @@
+package mqttalog
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/xsleonard/alog"
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type Suite struct{}
+
+var _ = check.Suite(&Suite{})
+
+type message struct {
+	topic    string
+	qos      byte
+	retained bool
+	payload  string
+}
+
+type fakeToken struct {
+	mqtt.Token
+	err  error
+	done bool
+}
+
+func (t *fakeToken) WaitTimeout(time.Duration) bool { return t.done }
+func (t *fakeToken) Error() error                   { return t.err }
+
+type fakeClient struct {
+	msgs  []message
+	token *fakeToken
+}
+
+func (c *fakeClient) Publish(topic string, qos byte, retained bool, payload interface{}) mqtt.Token {
+	c.msgs = append(c.msgs, message{topic, qos, retained, string(payload.([]byte))})
+	return c.token
+}
+
+func (s *Suite) TestWriter(c *check.C) {
+	client := &fakeClient{token: &fakeToken{done: true}}
+	w := New(client, Config{Topic: "devices/{device_id}/logs/{level}", QoS: 1})
+	log := alog.New(w)
+	log.SetFlags(0)
+
+	log.With("device_id", "pump/7").Warn("pressure high")
+	log.Print("boot")
+	w.Write([]byte("raw\n"))
+	c.Assert(client.msgs, check.DeepEquals, []message{
+		{"devices/pump_7/logs/warn", 1, false, "WARN [device_id=pump/7] pressure high"},
+		{"devices/unknown/logs/info", 1, false, "boot"},
+		{"devices/unknown/logs/info", 1, false, "raw"},
+	})
+
+	// Static topics, errors and timeouts
+	w = New(client, Config{Topic: "logs", Retained: true})
+	client.token.err = errors.New("not connected")
+	_, err := w.Write([]byte("x\n"))
+	c.Assert(err, check.ErrorMatches, "not connected")
+	c.Assert(client.msgs[3], check.DeepEquals, message{"logs", 0, true, "x"})
+	client.token.done = false
+	_, err = w.Write([]byte("x\n"))
+	c.Assert(err, check.Equals, errTimeout)
+
+	w = New(client, Config{Topic: "a/{unterminated", Timeout: -1})
+	_, err = w.Write([]byte("x\n"))
+	c.Assert(err, check.IsNil)
+	c.Assert(client.msgs[5].topic, check.Equals, "a/{unterminated")
+}
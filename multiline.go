@@ -0,0 +1,67 @@
+package alog
+
+import "bytes"
+
+// How newlines inside an entry are written.  Set with Log.SetMultiline.
+type Multiline struct {
+	mode   int
+	marker string
+}
+
+const (
+	multilineKeep = iota
+	multilineEscape
+	multilineContinue
+)
+
+var (
+	// Writes newlines as they are, the default
+	MultilineKeep = Multiline{mode: multilineKeep}
+	// Writes newlines and carriage returns as \n and \r, keeping each entry
+	// on one line
+	MultilineEscape = Multiline{mode: multilineEscape}
+)
+
+// Starts each line after the first with marker, such as "\t" or " | ", so
+// that shippers can join continuation lines back onto their entry
+func MultilineContinue(marker string) Multiline {
+	return Multiline{mode: multilineContinue, marker: marker}
+}
+
+// Sets how newlines in messages and field values are written, for
+// shippers that treat each line as an entry.  It applies to formatted
+// entries too, though most formatters already escape newlines.
+// SetSanitize takes precedence.  Copies keep the setting.
+func (a *Log) SetMultiline(m Multiline) *Log {
+	if a == nil {
+		return nil
+	}
+	a.multiline = m
+	return a
+}
+
+// Applies m to buf[start:], a formatted entry without its final newline
+func (m Multiline) apply(buf []byte, start int) []byte {
+	if m.mode == multilineKeep || bytes.IndexAny(buf[start:], "\r\n") < 0 {
+		return buf
+	}
+
+	tail := append([]byte(nil), buf[start:]...)
+	buf = buf[:start]
+	for i, c := range tail {
+		switch {
+		case m.mode == multilineEscape && c == '\n':
+			buf = append(buf, `\n`...)
+		case m.mode == multilineEscape && c == '\r':
+			buf = append(buf, `\r`...)
+		case m.mode == multilineContinue && c == '\r' && i+1 < len(tail) && tail[i+1] == '\n':
+			// Dropped, so CRLF line endings don't leave a stray \r
+		case m.mode == multilineContinue && c == '\n':
+			buf = append(buf, '\n')
+			buf = append(buf, m.marker...)
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
@@ -0,0 +1,38 @@
+package alog
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestMultiline(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("k", "a\nb")
+
+	log.Print("one\ntwo\r\nthree")
+	c.Assert(t.last(), check.Equals, "[k=a\nb] one\ntwo\r\nthree\n")
+
+	log.SetMultiline(MultilineEscape)
+	log.Print("one\ntwo\r\nthree")
+	c.Assert(t.last(), check.Equals, `[k=a\nb] one\ntwo\r\nthree`+"\n")
+
+	log.SetMultiline(MultilineContinue("\t| "))
+	log.Print("one\ntwo\r\nthree\n")
+	c.Assert(t.last(), check.Equals, "[k=a\n\t| b] one\n\t| two\n\t| three\n")
+
+	// Copies keep the setting
+	log.With("x", 1).Print("y\nz")
+	c.Assert(t.last(), check.Equals, "[k=a\n\t| b x=1] y\n\t| z\n")
+
+	// Formatted entries
+	log.SetFormatter(ConsoleFormatter{})
+	log.Print("p\nq")
+	c.Assert(t.last(), check.Equals, "INFO  p\n\t| q k=a\n\t| b\n")
+	log.SetFormatter(nil)
+
+	// Sanitizing takes precedence
+	log.SetSanitize(true)
+	log.Print("one\ntwo")
+	c.Assert(t.last(), check.Equals, `[k=a\nb] one\ntwo`+"\n")
+}
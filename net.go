@@ -0,0 +1,171 @@
+package alog
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// How entries are delimited on a stream connection
+type Framing int
+
+const (
+	// Entries end with a newline, as written
+	FrameNewline Framing = iota
+	// Each entry, without its newline, follows its length as a 4 byte big
+	// endian integer
+	FrameLength
+)
+
+// Settings for a NetWriter.  Zero fields take defaults.
+type NetOptions struct {
+	Framing Framing
+	// 5s by default
+	DialTimeout time.Duration
+	// Per entry, 5s by default
+	WriteTimeout time.Duration
+	// How long to wait after a failed connection attempt before trying
+	// again, 1s by default, so a down collector isn't redialed for every
+	// entry.  Entries written meanwhile fail.
+	ReconnectDelay time.Duration
+}
+
+func (o NetOptions) withDefaults() NetOptions {
+	if o.DialTimeout <= 0 {
+		o.DialTimeout = 5 * time.Second
+	}
+	if o.WriteTimeout <= 0 {
+		o.WriteTimeout = 5 * time.Second
+	}
+	if o.ReconnectDelay <= 0 {
+		o.ReconnectDelay = time.Second
+	}
+	return o
+}
+
+// Writer sending entries over a network or Unix domain socket connection,
+// which is dialed on the first write and redialed after a failure, e.g.
+// when a local agent restarts.  An entry whose write fails is
+// retried once on a new connection; wrap in a RetryWriter or
+// FallbackWriter for more.
+type NetWriter struct {
+	network string
+	address string
+	opts    NetOptions
+
+	conn       net.Conn
+	dialFailed time.Time
+	health     sinkHealth
+	now        func() time.Time
+	mutex      sync.Mutex
+}
+
+func NewNetWriter(network, address string, opts NetOptions) *NetWriter {
+	return &NetWriter{network: network, address: address, opts: opts.withDefaults(), now: time.Now}
+}
+
+// Returns a NetWriter for a URL such as tcp://host:port, udp://host:port,
+// or for Unix domain sockets unix:///path (stream) or unixgram:///path
+// (datagram)
+func OpenNet(rawurl string, opts NetOptions) (*NetWriter, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6":
+		if u.Host == "" {
+			return nil, fmt.Errorf("alog: %s has no address", rawurl)
+		}
+		return NewNetWriter(u.Scheme, u.Host, opts), nil
+	case "unix", "unixgram":
+		if u.Path == "" {
+			return nil, fmt.Errorf("alog: %s has no path", rawurl)
+		}
+		return NewNetWriter(u.Scheme, u.Path, opts), nil
+	default:
+		return nil, fmt.Errorf("alog: unsupported network %q", u.Scheme)
+	}
+}
+
+func (w *NetWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	frame := w.frame(p)
+	hadConn := w.conn != nil
+	err := w.send(frame)
+	if err != nil && hadConn {
+		// The connection failed; retry once on a fresh one
+		err = w.send(frame)
+	}
+	if w.health.record(err) != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *NetWriter) frame(p []byte) []byte {
+	if w.opts.Framing != FrameLength {
+		return p
+	}
+	if n := len(p); n > 0 && p[n-1] == '\n' {
+		p = p[:n-1]
+	}
+	b := make([]byte, 4, 4+len(p))
+	binary.BigEndian.PutUint32(b, uint32(len(p)))
+	return append(b, p...)
+}
+
+// Writes frame, dialing first if needed.  Closes the connection on failure.
+func (w *NetWriter) send(frame []byte) error {
+	if w.conn == nil {
+		if err := w.dial(); err != nil {
+			return err
+		}
+	}
+	w.conn.SetWriteDeadline(w.now().Add(w.opts.WriteTimeout))
+	if _, err := w.conn.Write(frame); err != nil {
+		w.conn.Close()
+		w.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (w *NetWriter) dial() error {
+	now := w.now()
+	if !w.dialFailed.IsZero() && now.Sub(w.dialFailed) < w.opts.ReconnectDelay {
+		return fmt.Errorf("alog: %s %s: waiting to reconnect", w.network, w.address)
+	}
+	conn, err := net.DialTimeout(w.network, w.address, w.opts.DialTimeout)
+	if err != nil {
+		w.dialFailed = now
+		return err
+	}
+	w.dialFailed = time.Time{}
+	w.conn = conn
+	return nil
+}
+
+func (w *NetWriter) Status() []SinkStatus {
+	s := w.health.status(w.network + " " + w.address)
+	w.mutex.Lock()
+	s.Connected = w.dialFailed.IsZero()
+	w.mutex.Unlock()
+	return []SinkStatus{s}
+}
+
+func (w *NetWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
@@ -0,0 +1,113 @@
+package alog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestNetWriterTCP(c *check.C) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	defer ln.Close()
+	conns := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns <- conn
+		}
+	}()
+
+	w, err := OpenNet("tcp://"+ln.Addr().String(), NetOptions{})
+	c.Assert(err, check.IsNil)
+	defer w.Close()
+	log := New(w)
+	log.SetFlags(0)
+	log.Print("one")
+
+	conn := <-conns
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	c.Assert(err, check.IsNil)
+	c.Assert(line, check.Equals, "one\n")
+
+	// Reconnects after the connection breaks
+	conn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	var conn2 net.Conn
+	for conn2 == nil && time.Now().Before(deadline) {
+		log.Print("two")
+		select {
+		case conn2 = <-conns:
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	c.Assert(conn2, check.NotNil)
+	line, err = bufio.NewReader(conn2).ReadString('\n')
+	c.Assert(err, check.IsNil)
+	c.Assert(line, check.Equals, "two\n")
+}
+
+func (s *Suite) TestNetWriterFraming(c *check.C) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	defer ln.Close()
+
+	w := NewNetWriter("tcp", ln.Addr().String(), NetOptions{Framing: FrameLength})
+	defer w.Close()
+	_, err = w.Write([]byte("hello\n"))
+	c.Assert(err, check.IsNil)
+
+	conn, err := ln.Accept()
+	c.Assert(err, check.IsNil)
+	defer conn.Close()
+	b := make([]byte, 9)
+	_, err = io.ReadFull(conn, b)
+	c.Assert(err, check.IsNil)
+	c.Assert(binary.BigEndian.Uint32(b), check.Equals, uint32(5))
+	c.Assert(string(b[4:]), check.Equals, "hello")
+}
+
+func (s *Suite) TestNetWriterUDP(c *check.C) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	defer pc.Close()
+
+	w, err := OpenNet("udp://"+pc.LocalAddr().String(), NetOptions{})
+	c.Assert(err, check.IsNil)
+	defer w.Close()
+	w.Write([]byte("dgram\n"))
+
+	b := make([]byte, 100)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(b)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(b[:n]), check.Equals, "dgram\n")
+}
+
+func (s *Suite) TestNetWriterReconnectDelay(c *check.C) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	now := time.Now()
+	w := NewNetWriter("tcp", addr, NetOptions{ReconnectDelay: time.Minute})
+	w.now = func() time.Time { return now }
+	_, err = w.Write([]byte("x\n"))
+	c.Assert(err, check.NotNil)
+	_, err = w.Write([]byte("x\n"))
+	c.Assert(err, check.ErrorMatches, "alog: tcp .*: waiting to reconnect")
+
+	_, err = OpenNet("http://x", NetOptions{})
+	c.Assert(err, check.ErrorMatches, `alog: unsupported network "http"`)
+	_, err = OpenNet("tcp://", NetOptions{})
+	c.Assert(err, check.ErrorMatches, "alog: tcp:// has no address")
+}
@@ -0,0 +1,80 @@
+//go:build unix
+
+package alog
+
+import (
+	"bufio"
+	"net"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestNetWriterUnix(c *check.C) {
+	path := filepath.Join(c.MkDir(), "agent.sock")
+	ln, err := net.Listen("unix", path)
+	c.Assert(err, check.IsNil)
+
+	w, err := OpenNet("unix://"+path, NetOptions{})
+	c.Assert(err, check.IsNil)
+	defer w.Close()
+	log := New(w)
+	log.SetFlags(0)
+	log.Print("one")
+
+	conn, err := ln.Accept()
+	c.Assert(err, check.IsNil)
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	c.Assert(err, check.IsNil)
+	c.Assert(line, check.Equals, "one\n")
+
+	// The agent restarts
+	conn.Close()
+	ln.Close()
+	ln, err = net.Listen("unix", path)
+	c.Assert(err, check.IsNil)
+	defer ln.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for conn = nil; conn == nil && time.Now().Before(deadline); {
+		log.Print("two")
+		select {
+		case conn = <-accepted:
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	c.Assert(conn, check.NotNil)
+	line, err = bufio.NewReader(conn).ReadString('\n')
+	c.Assert(err, check.IsNil)
+	c.Assert(line, check.Equals, "two\n")
+}
+
+func (s *Suite) TestNetWriterUnixgram(c *check.C) {
+	path := filepath.Join(c.MkDir(), "agent.sock")
+	pc, err := net.ListenPacket("unixgram", path)
+	c.Assert(err, check.IsNil)
+	defer pc.Close()
+
+	w, err := OpenNet("unixgram://"+path, NetOptions{})
+	c.Assert(err, check.IsNil)
+	defer w.Close()
+	_, err = w.Write([]byte("dgram\n"))
+	c.Assert(err, check.IsNil)
+
+	b := make([]byte, 100)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(b)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(b[:n]), check.Equals, "dgram\n")
+
+	_, err = OpenNet("unix://", NetOptions{})
+	c.Assert(err, check.ErrorMatches, "alog: unix:// has no path")
+}
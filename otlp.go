@@ -0,0 +1,278 @@
+package alog
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Transports an OTLPWriter can export with
+type OTLPProtocol int
+
+const (
+	// OTLP/HTTP with JSON encoding, the default
+	OTLPHTTPJSON OTLPProtocol = iota
+	// OTLP/gRPC, with protobuf encoding
+	OTLPGRPC
+)
+
+// Settings for an OTLPWriter
+type OTLPConfig struct {
+	// Base URL of the collector's receiver, e.g. http://otel-collector:4318
+	// for OTLP/HTTP, where records are posted to /v1/logs, or
+	// http://otel-collector:4317 for OTLP/gRPC.
+	URL      string
+	Protocol OTLPProtocol
+	// Sent with each request, e.g. an Authorization header
+	Headers map[string]string
+	// Resource attributes.  ServiceName sets service.name.
+	ServiceName        string
+	ResourceAttributes map[string]string
+	// Records per request, 100 by default
+	BatchSize int
+	// How often a partial batch is sent, 1s by default.  Negative for only
+	// when full and on Flush.
+	FlushInterval time.Duration
+	// http.DefaultClient by default, or for OTLP/gRPC a client that speaks
+	// HTTP/2 to both http and https URLs.  A gRPC client must use HTTP/2.
+	Client *http.Client
+}
+
+// Writer that exports entries as OpenTelemetry LogRecords to a collector,
+// over OTLP/HTTP with JSON encoding or over OTLP/gRPC.  As the logger's
+// writer, the level becomes the severity and the message the body.  Fields
+// become attributes, except:
+//
+//	error (an error)   exception.message, exception.type
+//	stack              exception.stacktrace
+//	trace_id, span_id  the record's traceId and spanId, if valid hex ids
+//
+// Other lines written to it are sent as bodies without a severity.  Close
+// must be called to send the last batch.
+type OTLPWriter struct {
+	cfg      OTLPConfig
+	resource []byte
+	encode   func(b []byte, r *otlpRecord) []byte
+	batch    *batcher
+}
+
+// A LogRecord before encoding.  Records without a level have no severity.
+type otlpRecord struct {
+	time    int64
+	level   Level
+	leveled bool
+	body    string
+	attrs   []Field
+	traceID string
+	spanID  string
+}
+
+// OpenTelemetry severity numbers
+var otlpSeverities = map[Level]int{
+	DebugLevel: 5,
+	InfoLevel:  9,
+	WarnLevel:  13,
+	ErrorLevel: 17,
+	PanicLevel: 21,
+	FatalLevel: 21,
+}
+
+func NewOTLPWriter(cfg OTLPConfig) *OTLPWriter {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval == 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.Client == nil {
+		if cfg.Protocol == OTLPGRPC {
+			cfg.Client = newGRPCClient()
+		} else {
+			cfg.Client = http.DefaultClient
+		}
+	}
+
+	attrs := make([]Field, 0, len(cfg.ResourceAttributes)+1)
+	for k, v := range cfg.ResourceAttributes {
+		attrs = append(attrs, Field{k, v})
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+	if cfg.ServiceName != "" {
+		attrs = append([]Field{{"service.name", cfg.ServiceName}}, attrs...)
+	}
+	w := &OTLPWriter{cfg: cfg}
+	if cfg.Protocol == OTLPGRPC {
+		w.resource = appendOTLPProtoAttributes(nil, 1, attrs)
+		w.encode = appendOTLPProtoRecord
+		w.batch = newBatcher(w.sendGRPC, cfg.BatchSize, 0, cfg.FlushInterval)
+	} else {
+		w.resource = appendOTLPAttributes([]byte(`{"attributes":`), attrs)
+		w.resource = append(w.resource, '}')
+		w.encode = appendOTLPRecord
+		w.batch = newBatcher(w.send, cfg.BatchSize, 0, cfg.FlushInterval)
+	}
+	return w
+}
+
+func (w *OTLPWriter) Write(p []byte) (int, error) {
+	r := otlpRecord{time: time.Now().UnixNano(), body: string(bytes.TrimRight(p, "\n"))}
+	return len(p), w.batch.add(w.encode(nil, &r))
+}
+
+func (w *OTLPWriter) WriteEntry(e *Entry, p []byte) (int, error) {
+	r := otlpRecord{
+		time:    e.Time.UnixNano(),
+		level:   e.Level,
+		leveled: true,
+		body:    e.Message,
+		attrs:   make([]Field, 0, len(e.Fields)+2),
+	}
+	for _, f := range flattenFields(e.Fields) {
+		switch f.Key {
+		case "trace_id":
+			if s := fmt.Sprint(f.Value); validHexID(s, 16) {
+				r.traceID = strings.ToLower(s)
+				continue
+			}
+		case "span_id":
+			if s := fmt.Sprint(f.Value); validHexID(s, 8) {
+				r.spanID = strings.ToLower(s)
+				continue
+			}
+		case "stack":
+			f.Key = "exception.stacktrace"
+		case "error":
+			if err, ok := f.Value.(error); ok {
+				r.attrs = append(r.attrs, Field{"exception.message", err.Error()}, Field{"exception.type", errorTypeName(err)})
+				continue
+			}
+		}
+		r.attrs = append(r.attrs, f)
+	}
+	if e.File != "" {
+		r.attrs = append(r.attrs, Field{"code.filepath", e.File}, Field{"code.lineno", e.Line})
+	}
+	return len(p), w.batch.add(w.encode(nil, &r))
+}
+
+// Appends r in OTLP's JSON encoding
+func appendOTLPRecord(b []byte, r *otlpRecord) []byte {
+	b = append(b, `{"timeUnixNano":"`...)
+	b = strconv.AppendInt(b, r.time, 10)
+	b = append(b, '"')
+	if r.leveled {
+		b = append(b, `,"severityNumber":`...)
+		b = strconv.AppendInt(b, int64(otlpSeverities[r.level]), 10)
+		b = append(b, `,"severityText":`...)
+		b = appendJSONString(b, strings.ToUpper(r.level.String()))
+	}
+	b = append(b, `,"body":{"stringValue":`...)
+	b = appendJSONString(b, r.body)
+	b = append(b, '}')
+	if r.leveled {
+		b = append(b, `,"attributes":`...)
+		b = appendOTLPAttributes(b, r.attrs)
+	}
+	if r.traceID != "" {
+		b = append(b, `,"traceId":"`...)
+		b = append(b, r.traceID...)
+		b = append(b, '"')
+	}
+	if r.spanID != "" {
+		b = append(b, `,"spanId":"`...)
+		b = append(b, r.spanID...)
+		b = append(b, '"')
+	}
+	return append(b, '}')
+}
+
+// Reports whether s is n bytes, hex encoded, and not all zero, as OTLP
+// requires of trace and span ids
+func validHexID(s string, n int) bool {
+	id, err := hex.DecodeString(s)
+	return err == nil && len(id) == n && strings.Trim(s, "0") != ""
+}
+
+// Appends fields as a list of OTLP KeyValues
+func appendOTLPAttributes(b []byte, fields []Field) []byte {
+	b = append(b, '[')
+	for i, f := range fields {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, `{"key":`...)
+		b = appendJSONString(b, f.Key)
+		b = append(b, `,"value":`...)
+		b = appendOTLPValue(b, f.Value)
+		b = append(b, '}')
+	}
+	return append(b, ']')
+}
+
+// Appends an OTLP AnyValue.  64 bit integers are strings in OTLP's JSON
+// encoding.
+func appendOTLPValue(b []byte, v interface{}) []byte {
+	switch x := v.(type) {
+	case bool:
+		b = append(b, `{"boolValue":`...)
+		b = strconv.AppendBool(b, x)
+	case int:
+		b = append(b, `{"intValue":"`...)
+		b = strconv.AppendInt(b, int64(x), 10)
+		b = append(b, '"')
+	case int64:
+		b = append(b, `{"intValue":"`...)
+		b = strconv.AppendInt(b, x, 10)
+		b = append(b, '"')
+	case float64:
+		b = append(b, `{"doubleValue":`...)
+		b = appendJSONValue(b, x)
+	default:
+		b = append(b, `{"stringValue":`...)
+		b = appendJSONString(b, string(appendValue(nil, v)))
+	}
+	return append(b, '}')
+}
+
+func (w *OTLPWriter) send(records [][]byte) error {
+	var body bytes.Buffer
+	body.WriteString(`{"resourceLogs":[{"resource":`)
+	body.Write(w.resource)
+	body.WriteString(`,"scopeLogs":[{"scope":{"name":"alog"},"logRecords":[`)
+	body.Write(bytes.Join(records, []byte(",")))
+	body.WriteString("]}]}]}")
+
+	req, err := http.NewRequest("POST", w.cfg.URL+"/v1/logs", &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	return doRequest(w.cfg.Client, req, "otlp")
+}
+
+// Passes failed background sends to h
+func (w *OTLPWriter) SetErrorHandler(h func(error)) {
+	w.batch.errs.set(h)
+}
+
+func (w *OTLPWriter) Status() []SinkStatus {
+	return []SinkStatus{w.batch.status("otlp " + w.cfg.URL)}
+}
+
+// Sends the current batch
+func (w *OTLPWriter) Flush() error {
+	return w.batch.flush()
+}
+
+// Sends the last batch and stops the flush timer
+func (w *OTLPWriter) Close() error {
+	return w.batch.close()
+}
@@ -0,0 +1,155 @@
+package alog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Path of the OTLP/gRPC logs export method
+const otlpGRPCExport = "/opentelemetry.proto.collector.logs.v1.LogsService/Export"
+
+// Protobuf wire types
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+)
+
+func appendProtoVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func appendProtoTag(b []byte, field, wire int) []byte {
+	return appendProtoVarint(b, uint64(field<<3|wire))
+}
+
+func appendProtoBytes(b []byte, field int, p []byte) []byte {
+	b = appendProtoTag(b, field, protoBytes)
+	b = appendProtoVarint(b, uint64(len(p)))
+	return append(b, p...)
+}
+
+func appendProtoString(b []byte, field int, s string) []byte {
+	b = appendProtoTag(b, field, protoBytes)
+	b = appendProtoVarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func appendProtoFixed64(b []byte, field int, v uint64) []byte {
+	b = appendProtoTag(b, field, protoFixed64)
+	var p [8]byte
+	binary.LittleEndian.PutUint64(p[:], v)
+	return append(b, p[:]...)
+}
+
+// Appends r as a protobuf LogRecord
+func appendOTLPProtoRecord(b []byte, r *otlpRecord) []byte {
+	b = appendProtoFixed64(b, 1, uint64(r.time))
+	if r.leveled {
+		b = appendProtoTag(b, 2, protoVarint)
+		b = appendProtoVarint(b, uint64(otlpSeverities[r.level]))
+		b = appendProtoString(b, 3, strings.ToUpper(r.level.String()))
+	}
+	b = appendProtoBytes(b, 5, appendProtoString(nil, 1, r.body))
+	b = appendOTLPProtoAttributes(b, 6, r.attrs)
+	if id, err := hex.DecodeString(r.traceID); err == nil && len(id) > 0 {
+		b = appendProtoBytes(b, 9, id)
+	}
+	if id, err := hex.DecodeString(r.spanID); err == nil && len(id) > 0 {
+		b = appendProtoBytes(b, 10, id)
+	}
+	return b
+}
+
+// Appends fields as repeated KeyValues with the given field number
+func appendOTLPProtoAttributes(b []byte, field int, fields []Field) []byte {
+	var kv []byte
+	for _, f := range fields {
+		kv = appendProtoString(kv[:0], 1, f.Key)
+		kv = appendProtoBytes(kv, 2, appendOTLPProtoValue(nil, f.Value))
+		b = appendProtoBytes(b, field, kv)
+	}
+	return b
+}
+
+// Appends the fields of an AnyValue
+func appendOTLPProtoValue(b []byte, v interface{}) []byte {
+	switch x := v.(type) {
+	case bool:
+		b = appendProtoTag(b, 2, protoVarint)
+		if x {
+			return append(b, 1)
+		}
+		return append(b, 0)
+	case int:
+		b = appendProtoTag(b, 3, protoVarint)
+		return appendProtoVarint(b, uint64(x))
+	case int64:
+		b = appendProtoTag(b, 3, protoVarint)
+		return appendProtoVarint(b, uint64(x))
+	case float64:
+		return appendProtoFixed64(b, 4, math.Float64bits(x))
+	default:
+		return appendProtoString(b, 1, string(appendValue(nil, v)))
+	}
+}
+
+// Sends records, which are encoded LogRecords, as one gRPC Export call
+func (w *OTLPWriter) sendGRPC(records [][]byte) error {
+	scope := appendProtoBytes(nil, 1, appendProtoString(nil, 1, "alog"))
+	for _, r := range records {
+		scope = appendProtoBytes(scope, 2, r)
+	}
+	logs := appendProtoBytes(nil, 1, w.resource)
+	logs = appendProtoBytes(logs, 2, scope)
+	msg := appendProtoBytes(nil, 1, logs)
+
+	// Uncompressed gRPC message prefix: a zero flag and the length
+	body := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(body[1:], uint32(len(msg)))
+	body = append(body, msg...)
+
+	req, err := http.NewRequest("POST", w.cfg.URL+otlpGRPCExport, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	for k, v := range w.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := w.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Trailers are only read once the body is
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("alog: otlp: %s", resp.Status)
+	}
+	// A response without a message carries its status in the headers
+	status, text := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status, text = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	if status != "0" {
+		if s, err := url.PathUnescape(text); err == nil {
+			text = s
+		}
+		return fmt.Errorf("alog: otlp: grpc status %q: %s", status, text)
+	}
+	return nil
+}
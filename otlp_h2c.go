@@ -0,0 +1,15 @@
+//go:build go1.24
+
+package alog
+
+import "net/http"
+
+// Client for OTLP/gRPC: HTTP/2 over TLS for https URLs, and unencrypted
+// HTTP/2 with prior knowledge for http ones
+func newGRPCClient() *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Protocols = new(http.Protocols)
+	t.Protocols.SetHTTP2(true)
+	t.Protocols.SetUnencryptedHTTP2(true)
+	return &http.Client{Transport: t}
+}
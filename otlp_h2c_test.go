@@ -0,0 +1,25 @@
+//go:build go1.24
+
+package alog
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestOTLPWriterGRPCUnencrypted(c *check.C) {
+	col := &grpcCollector{}
+	srv := httptest.NewUnstartedServer(col)
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	defer srv.Close()
+
+	w := NewOTLPWriter(OTLPConfig{URL: srv.URL, Protocol: OTLPGRPC, FlushInterval: -1})
+	w.Write([]byte("x\n"))
+	c.Assert(w.Close(), check.IsNil)
+	c.Assert(col.msgs, check.HasLen, 1)
+	c.Assert(col.reqs[0].ProtoMajor, check.Equals, 2)
+}
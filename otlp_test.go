@@ -0,0 +1,197 @@
+package alog
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestOTLPWriter(c *check.C) {
+	col := &collector{}
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+
+	w := NewOTLPWriter(OTLPConfig{
+		URL:                srv.URL,
+		Headers:            map[string]string{"Authorization": "Bearer t"},
+		ServiceName:        "api",
+		ResourceAttributes: map[string]string{"env": "prod"},
+		FlushInterval:      -1,
+	})
+	log := New(w)
+	log.Set("user", "alice").Set("n", 3).Set("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736")
+	log.WithError(errors.New("timeout")).Warn("slow")
+	log.With("span_id", "bad").Print("ok")
+	w.Write([]byte("raw\n"))
+	c.Assert(w.Close(), check.IsNil)
+
+	c.Assert(col.bodies, check.HasLen, 1)
+	c.Assert(col.reqs[0].URL.Path, check.Equals, "/v1/logs")
+	c.Assert(col.reqs[0].Header.Get("Authorization"), check.Equals, "Bearer t")
+	c.Assert(col.bodies[0], check.Matches, `\{"resourceLogs":\[\{"resource":\{"attributes":\[`+
+		`\{"key":"service.name","value":\{"stringValue":"api"\}\},\{"key":"env","value":\{"stringValue":"prod"\}\}\]\},`+
+		`"scopeLogs":\[\{"scope":\{"name":"alog"\},"logRecords":\[`+
+		`\{"timeUnixNano":"\d+","severityNumber":13,"severityText":"WARN","body":\{"stringValue":"slow"\},"attributes":\[`+
+		`\{"key":"user","value":\{"stringValue":"alice"\}\},\{"key":"n","value":\{"intValue":"3"\}\},`+
+		`\{"key":"exception.message","value":\{"stringValue":"timeout"\}\},\{"key":"exception.type","value":\{"stringValue":"\*errors.errorString"\}\}\],`+
+		`"traceId":"4bf92f3577b34da6a3ce929d0e0e4736"\},`+
+		`\{"timeUnixNano":"\d+","severityNumber":9,"severityText":"INFO","body":\{"stringValue":"ok"\},"attributes":\[`+
+		`\{"key":"user","value":\{"stringValue":"alice"\}\},\{"key":"n","value":\{"intValue":"3"\}\},\{"key":"span_id","value":\{"stringValue":"bad"\}\}\],`+
+		`"traceId":"4bf92f3577b34da6a3ce929d0e0e4736"\},`+
+		`\{"timeUnixNano":"\d+","body":\{"stringValue":"raw"\}\}\]\}\]\}\]\}`)
+
+	col.status = http.StatusBadRequest
+	w = NewOTLPWriter(OTLPConfig{URL: srv.URL, FlushInterval: -1})
+	w.Write([]byte("x\n"))
+	c.Assert(w.Flush(), check.ErrorMatches, "alog: otlp: 400 Bad Request: invalid token")
+}
+
+// Stands in for an OTLP/gRPC collector
+type grpcCollector struct {
+	status string
+	reqs   []*http.Request
+	msgs   [][]byte
+	mutex  sync.Mutex
+}
+
+func (s *grpcCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, _ := ioutil.ReadAll(r.Body)
+	s.mutex.Lock()
+	s.reqs = append(s.reqs, r)
+	if len(b) >= 5 && int(binary.BigEndian.Uint32(b[1:5])) == len(b)-5 {
+		s.msgs = append(s.msgs, b[5:])
+	}
+	s.mutex.Unlock()
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Write([]byte{0, 0, 0, 0, 0})
+	if s.status == "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Status", "0")
+	} else {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Status", s.status)
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", "bad%20token")
+	}
+}
+
+type protoField struct {
+	num   int
+	value uint64
+	bytes []byte
+}
+
+// Decodes the top level fields of a protobuf message
+func decodeProto(c *check.C, b []byte) []protoField {
+	var fields []protoField
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		c.Assert(n > 0, check.Equals, true)
+		b = b[n:]
+		f := protoField{num: int(tag >> 3)}
+		switch tag & 7 {
+		case protoVarint:
+			f.value, n = binary.Uvarint(b)
+			c.Assert(n > 0, check.Equals, true)
+			b = b[n:]
+		case protoFixed64:
+			f.value = binary.LittleEndian.Uint64(b)
+			b = b[8:]
+		case protoBytes:
+			l, n := binary.Uvarint(b)
+			c.Assert(n > 0 && int(l) <= len(b)-n, check.Equals, true)
+			f.bytes = b[n : n+int(l)]
+			b = b[n+int(l):]
+		default:
+			c.Fatalf("wire type %d", tag&7)
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// Returns the decoded KeyValues in fields with number num, as key=value
+// with the AnyValue's field number
+func protoAttributes(c *check.C, fields []protoField, num int) []string {
+	var attrs []string
+	for _, f := range fields {
+		if f.num != num {
+			continue
+		}
+		kv := decodeProto(c, f.bytes)
+		c.Assert(kv, check.HasLen, 2)
+		v := decodeProto(c, kv[1].bytes)
+		c.Assert(v, check.HasLen, 1)
+		value := string(v[0].bytes)
+		if v[0].bytes == nil {
+			value = strconv.FormatUint(v[0].value, 10)
+		}
+		attrs = append(attrs, fmt.Sprintf("%s=%d:%s", kv[0].bytes, v[0].num, value))
+	}
+	return attrs
+}
+
+func (s *Suite) TestOTLPWriterGRPC(c *check.C) {
+	col := &grpcCollector{}
+	srv := httptest.NewUnstartedServer(col)
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	w := NewOTLPWriter(OTLPConfig{
+		URL:           srv.URL,
+		Protocol:      OTLPGRPC,
+		Headers:       map[string]string{"Authorization": "Bearer t"},
+		ServiceName:   "api",
+		FlushInterval: -1,
+		Client:        srv.Client(),
+	})
+	log := New(w)
+	log.Set("n", 3).Set("ok", true).Set("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736")
+	log.WithError(errors.New("timeout")).Warn("slow")
+	w.Write([]byte("raw\n"))
+	c.Assert(w.Close(), check.IsNil)
+
+	c.Assert(col.msgs, check.HasLen, 1)
+	req := col.reqs[0]
+	c.Assert(req.ProtoMajor, check.Equals, 2)
+	c.Assert(req.URL.Path, check.Equals, "/opentelemetry.proto.collector.logs.v1.LogsService/Export")
+	c.Assert(req.Header.Get("Content-Type"), check.Equals, "application/grpc")
+	c.Assert(req.Header.Get("Authorization"), check.Equals, "Bearer t")
+
+	export := decodeProto(c, col.msgs[0])
+	c.Assert(export, check.HasLen, 1)
+	logs := decodeProto(c, export[0].bytes)
+	c.Assert(logs, check.HasLen, 2)
+	c.Assert(protoAttributes(c, decodeProto(c, logs[0].bytes), 1), check.DeepEquals, []string{"service.name=1:api"})
+	scope := decodeProto(c, logs[1].bytes)
+	c.Assert(scope, check.HasLen, 3)
+	c.Assert(decodeProto(c, scope[0].bytes), check.DeepEquals, []protoField{{num: 1, bytes: []byte("alog")}})
+
+	rec := decodeProto(c, scope[1].bytes)
+	c.Assert(rec[0].num, check.Equals, 1)
+	c.Assert(rec[0].value > 0, check.Equals, true)
+	c.Assert(rec[1], check.DeepEquals, protoField{num: 2, value: 13})
+	c.Assert(rec[2], check.DeepEquals, protoField{num: 3, bytes: []byte("WARN")})
+	c.Assert(decodeProto(c, rec[3].bytes), check.DeepEquals, []protoField{{num: 1, bytes: []byte("slow")}})
+	c.Assert(protoAttributes(c, rec, 6), check.DeepEquals, []string{
+		"n=3:3", "ok=2:1", "exception.message=1:timeout", "exception.type=1:*errors.errorString",
+	})
+	last := rec[len(rec)-1]
+	c.Assert(last.num, check.Equals, 9)
+	c.Assert(hex.EncodeToString(last.bytes), check.Equals, "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	rec = decodeProto(c, scope[2].bytes)
+	c.Assert(rec, check.HasLen, 2)
+	c.Assert(decodeProto(c, rec[1].bytes), check.DeepEquals, []protoField{{num: 1, bytes: []byte("raw")}})
+
+	col.status = "16"
+	w = NewOTLPWriter(OTLPConfig{URL: srv.URL, Protocol: OTLPGRPC, FlushInterval: -1, Client: srv.Client()})
+	w.Write([]byte("x\n"))
+	c.Assert(w.Flush(), check.ErrorMatches, `alog: otlp: grpc status "16": bad token`)
+}
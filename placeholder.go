@@ -0,0 +1,77 @@
+package alog
+
+import "strings"
+
+// Logs at InfoLevel a message filled in from named placeholders, keeping
+// both the rendered text and the fields:
+//
+//	log.PrintT("user {user} failed after {attempts} tries", alog.Field{"attempts", 3})
+//
+// Each {key} is replaced by the value of the field with that key, taken from
+// fields, then the logger's fields, and rendered as in the prefix,
+// including key formatters.  Keys in groups are named by their dotted path.
+// Unknown placeholders are left as they are, and {{ writes a {.  Use
+// At(level).MsgT for other levels.
+func (a *Log) PrintT(t string, fields ...Field) {
+	if a != nil && !a.enabled(InfoLevel) {
+		return
+	}
+	a.output(InfoLevel, message{fmtString, a.expand(t, fields), nil, fields})
+}
+
+// Writes the entry with its message filled in from t's placeholders, as
+// Log.PrintT does, using the Event's fields before the logger's
+func (e *Event) MsgT(t string) {
+	if e == nil {
+		return
+	}
+	m := message{fmtString, e.log.expand(t, e.fields), nil, e.fields}
+	e.log.output(e.level, m)
+	e.done(m.f)
+}
+
+// Replaces the placeholders in t with the values of extra, then of the
+// logger's fields
+func (a *Log) expand(t string, extra []Field) string {
+	if strings.IndexByte(t, '{') < 0 {
+		return t
+	}
+	fields := flattenFields(a.formatValues(append(a.entryFields(), extra...)))
+
+	var b []byte
+	for {
+		i := strings.IndexByte(t, '{')
+		if i < 0 {
+			break
+		}
+		b = append(b, t[:i]...)
+		t = t[i:]
+		if strings.HasPrefix(t, "{{") {
+			b = append(b, '{')
+			t = t[2:]
+			continue
+		}
+		j := strings.IndexByte(t, '}')
+		if j < 0 {
+			break
+		}
+		if v, ok := lookupField(fields, t[1:j]); ok {
+			b = appendValue(b, v)
+		} else {
+			b = append(b, t[:j+1]...)
+		}
+		t = t[j+1:]
+	}
+	return string(append(b, t...))
+}
+
+// Returns the value of the last field with key k, so that an entry's own
+// fields take precedence over the logger's
+func lookupField(fields []Field, k string) (interface{}, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Key == k {
+			return fields[i].Value, true
+		}
+	}
+	return nil, false
+}
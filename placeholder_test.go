@@ -0,0 +1,44 @@
+package alog
+
+import (
+	stdlog "log"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestPrintT(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("user", "alice").Set("http", Group("method", "GET"))
+
+	log.PrintT("user {user} failed after {attempts} tries", Field{"attempts", 3})
+	checkLast(c, t, "[user=alice http.method=GET attempts=3] user alice failed after 3 tries")
+
+	// Entry fields win, groups are dotted, unknown keys and {{ are kept
+	log.PrintT("{user} {http.method} {missing} {{user}", Field{"user", "bob"})
+	checkLast(c, t, "[user=alice http.method=GET user=bob] bob GET {missing} {user}")
+
+	// Key formatters apply
+	log.SetKeyFormatter("user", Masked)
+	log.PrintT("hello {user}")
+	checkLast(c, t, "[user=*** http.method=GET] hello ***")
+
+	log.At(WarnLevel).Int("n", 2).MsgT("{n} retries for {http.method}")
+	checkLast(c, t, "WARN [user=*** http.method=GET n=2] 2 retries for GET")
+
+	// Disabled levels aren't rendered
+	log.SetLevel(WarnLevel)
+	log.PrintT("{user}")
+	c.Assert(t.msgs, check.HasLen, 4)
+
+	// Unterminated placeholder
+	log.SetLevel(InfoLevel)
+	log.PrintT("a {b")
+	checkLast(c, t, "[user=*** http.method=GET] a {b")
+
+	// The caller is the MsgT call, not placeholder.go
+	log.SetFlags(stdlog.Lshortfile)
+	log.At(InfoLevel).MsgT("{user}")
+	c.Assert(t.last(), check.Matches, `placeholder_test\.go:\d+: \[user=\*\*\* http.method=GET\] \*\*\*\n`)
+}
@@ -0,0 +1,22 @@
+package alog
+
+// Where the fields are written in a text entry, relative to the message.
+// Set with Log.SetFieldPosition.
+type FieldPosition int
+
+const (
+	// "[k=v ...] message", the default
+	FieldsBeforeMessage FieldPosition = iota
+	// "message [k=v ...]", for lines meant to be scanned by people
+	FieldsAfterMessage
+)
+
+// Sets where the fields are written in text entries.  Formatters are
+// unaffected.  Copies keep the setting.
+func (a *Log) SetFieldPosition(p FieldPosition) *Log {
+	if a == nil {
+		return nil
+	}
+	a.fieldPosition = p
+	return a
+}
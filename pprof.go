@@ -0,0 +1,28 @@
+package alog
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// Calls f with pprof labels set from the logger's fields with keys, such
+// as request_id and component, so that CPU profiles can be matched to the
+// logs.  Values are rendered as in the prefix; keys the logger doesn't have
+// are left out.  As with pprof.Do, the labels apply to goroutines f starts
+// and are removed when it returns.
+func (a *Log) DoLabeled(ctx context.Context, f func(context.Context), keys ...string) {
+	pprof.Do(ctx, a.ProfileLabels(keys...), f)
+}
+
+// Returns the logger's fields with keys as a pprof.LabelSet, for use with
+// pprof.Do or pprof.WithLabels
+func (a *Log) ProfileLabels(keys ...string) pprof.LabelSet {
+	fields := flattenFields(a.formatValues(a.entryFields()))
+	var kv []string
+	for _, k := range keys {
+		if v, ok := lookupField(fields, k); ok {
+			kv = append(kv, k, string(appendValue(nil, v)))
+		}
+	}
+	return pprof.Labels(kv...)
+}
@@ -0,0 +1,29 @@
+package alog
+
+import (
+	"context"
+	"runtime/pprof"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestDoLabeled(c *check.C) {
+	log := New(&Thief{})
+	log.Set("request_id", "abc").Set("component", "db").Set("n", 7)
+
+	var labels map[string]string
+	log.DoLabeled(context.Background(), func(ctx context.Context) {
+		labels = map[string]string{}
+		pprof.ForLabels(ctx, func(k, v string) bool {
+			labels[k] = v
+			return true
+		})
+	}, "request_id", "n", "missing")
+	c.Assert(labels, check.DeepEquals, map[string]string{"request_id": "abc", "n": "7"})
+
+	var nilLog *Log
+	nilLog.DoLabeled(context.Background(), func(ctx context.Context) {
+		_, ok := pprof.Label(ctx, "request_id")
+		c.Assert(ok, check.Equals, false)
+	}, "request_id")
+}
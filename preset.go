@@ -0,0 +1,47 @@
+package alog
+
+import (
+	"io"
+	"log"
+	"os"
+)
+
+// Environment variable read by NewFromEnv
+const EnvVar = "ALOG_ENV"
+
+// Returns a logger for local development: colored console output with the
+// time, level and caller first, at DebugLevel.  Colors are left out where
+// EnableColor reports that out can't show them.
+func NewDevelopment(out io.Writer) *Log {
+	a := New(out)
+	a.SetFlags(log.Ltime | log.Lmicroseconds | log.Lshortfile)
+	a.SetFormatter(ConsoleFormatter{Color: EnableColor(out)})
+	a.SetLevel(DebugLevel)
+	return a
+}
+
+// Returns a logger for production: one compact JSON object per entry, with
+// UTC timestamps, at InfoLevel.  Requests are sampled by their request_id,
+// as set by RequestIDHandler, keeping the debug and info entries of about
+// one in ten; warnings, errors and entries outside a request are all kept.
+// Change the key or rate with SetSampling.
+func NewProduction(out io.Writer) *Log {
+	a := New(out)
+	a.SetFlags(log.LstdFlags | log.Lmicroseconds | log.LUTC)
+	a.SetFormatter(JSONFormatter{})
+	a.SetLevel(InfoLevel)
+	a.SetSampling("request_id", 0.1)
+	a.SetSamplingExempt(WarnLevel)
+	return a
+}
+
+// Returns NewDevelopment(out) if $ALOG_ENV is "development" or "dev", and
+// NewProduction(out) otherwise.
+func NewFromEnv(out io.Writer) *Log {
+	switch os.Getenv(EnvVar) {
+	case "development", "dev":
+		return NewDevelopment(out)
+	default:
+		return NewProduction(out)
+	}
+}
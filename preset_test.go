@@ -0,0 +1,42 @@
+package alog
+
+import (
+	"os"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestPresets(c *check.C) {
+	t := &Thief{}
+	log := NewDevelopment(t)
+	log.Set("k", "v").Debug("hello")
+	// Not a terminal, so without colors
+	c.Assert(t.last(), check.Matches, `\d\d:\d\d:\d\d\.\d{3} DEBUG preset_test\.go:\d+ hello k=v\n`)
+
+	log = NewProduction(t)
+	log.Debug("hidden")
+	log.Set("k", "v").Print("hello")
+	c.Assert(t.last(), check.Matches, `\{"time":"[-0-9]+T[:.0-9]+Z","level":"info","msg":"hello","k":"v"\}\n`)
+
+	// Requests are sampled
+	n := len(t.msgs)
+	for i := 0; i < 1000; i++ {
+		log.With("request_id", i).Print("request")
+	}
+	kept := len(t.msgs) - n
+	c.Assert(kept > 50 && kept < 150, check.Equals, true, check.Commentf("kept %d", kept))
+
+	// but not their warnings and errors
+	n = len(t.msgs)
+	for i := 0; i < 100; i++ {
+		log.With("request_id", i).Warn("slow")
+		log.With("request_id", i).Error("failed")
+	}
+	c.Assert(len(t.msgs)-n, check.Equals, 200)
+
+	defer os.Unsetenv(EnvVar)
+	os.Setenv(EnvVar, "dev")
+	c.Assert(NewFromEnv(t).Level(), check.Equals, DebugLevel)
+	os.Setenv(EnvVar, "production")
+	c.Assert(NewFromEnv(t).formatter.get(), check.Equals, JSONFormatter{})
+}
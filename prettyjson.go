@@ -0,0 +1,168 @@
+package alog
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Formats entries as JSONFormatter does, but indented over several lines
+// with the values of each object aligned, for reading JSON entries in a
+// development terminal without piping them through jq:
+//
+//	{
+//	  "level": "info",
+//	  "msg":   "done",
+//	  "user":  "alice"
+//	}
+//
+// With Compact, each entry stays on one line, spaced after colons and
+// commas as JSONFormatter's isn't.  With Color, keys and values are
+// highlighted with ANSI escapes by type.
+type PrettyJSONFormatter struct {
+	Color   bool
+	Compact bool
+}
+
+const (
+	jsonKeyColor     = "\x1b[34m"
+	jsonStringColor  = "\x1b[32m"
+	jsonNumberColor  = "\x1b[33m"
+	jsonLiteralColor = "\x1b[35m"
+	colorReset       = "\x1b[0m"
+)
+
+func (f PrettyJSONFormatter) Format(b []byte, e *Entry) []byte {
+	bp := getBuf()
+	defer putBuf(bp)
+	*bp = JSONFormatter{}.Format((*bp)[:0], e)
+
+	d := json.NewDecoder(bytes.NewReader(*bp))
+	d.UseNumber()
+	v, err := decodeJSONNode(d)
+	if err != nil {
+		return append(b, *bp...)
+	}
+	return f.appendNode(b, v, 0)
+}
+
+// JSON value with its object keys in their original order
+type jsonNode struct {
+	// A scalar's encoding, or "{" or "["
+	token    string
+	keys     []string
+	children []jsonNode
+}
+
+func decodeJSONNode(d *json.Decoder) (jsonNode, error) {
+	t, err := d.Token()
+	if err != nil {
+		return jsonNode{}, err
+	}
+	switch x := t.(type) {
+	case json.Delim:
+		n := jsonNode{token: x.String()}
+		for d.More() {
+			if x == '{' {
+				k, err := d.Token()
+				if err != nil {
+					return n, err
+				}
+				n.keys = append(n.keys, k.(string))
+			}
+			c, err := decodeJSONNode(d)
+			if err != nil {
+				return n, err
+			}
+			n.children = append(n.children, c)
+		}
+		// The closing delimiter
+		_, err := d.Token()
+		return n, err
+	case string:
+		return jsonNode{token: string(appendJSONString(nil, x))}, nil
+	case json.Number:
+		return jsonNode{token: x.String()}, nil
+	case bool:
+		if x {
+			return jsonNode{token: "true"}, nil
+		}
+		return jsonNode{token: "false"}, nil
+	default:
+		return jsonNode{token: "null"}, nil
+	}
+}
+
+func (f PrettyJSONFormatter) appendNode(b []byte, n jsonNode, depth int) []byte {
+	switch n.token {
+	case "{", "[":
+	default:
+		color := jsonLiteralColor
+		switch c := n.token[0]; {
+		case c == '"':
+			color = jsonStringColor
+		case c == '-' || c >= '0' && c <= '9':
+			color = jsonNumberColor
+		}
+		return f.appendColored(b, n.token, color)
+	}
+
+	obj := n.token == "{"
+	end := byte(']')
+	if obj {
+		end = '}'
+	}
+	b = append(b, n.token...)
+	if len(n.children) == 0 {
+		return append(b, end)
+	}
+
+	width := 0
+	for _, k := range n.keys {
+		if w := len(appendJSONString(nil, k)); w > width {
+			width = w
+		}
+	}
+	for i, c := range n.children {
+		if i > 0 {
+			b = append(b, ',')
+			if f.Compact {
+				b = append(b, ' ')
+			}
+		}
+		if !f.Compact {
+			b = f.appendIndent(b, depth+1)
+		}
+		if obj {
+			k := appendJSONString(nil, n.keys[i])
+			b = f.appendColored(b, string(k), jsonKeyColor)
+			b = append(b, ": "...)
+			if !f.Compact {
+				for j := len(k); j < width; j++ {
+					b = append(b, ' ')
+				}
+			}
+		}
+		b = f.appendNode(b, c, depth+1)
+	}
+	if !f.Compact {
+		b = f.appendIndent(b, depth)
+	}
+	return append(b, end)
+}
+
+func (f PrettyJSONFormatter) appendIndent(b []byte, depth int) []byte {
+	b = append(b, '\n')
+	for i := 0; i < depth; i++ {
+		b = append(b, "  "...)
+	}
+	return b
+}
+
+func (f PrettyJSONFormatter) appendColored(b []byte, s, color string) []byte {
+	if !f.Color {
+		return append(b, s...)
+	}
+	b = append(b, color...)
+	b = append(b, s...)
+	return append(b, colorReset...)
+}
@@ -0,0 +1,47 @@
+package alog
+
+import "gopkg.in/check.v1"
+
+func (s *Suite) TestPrettyJSONFormatter(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.SetFormatter(PrettyJSONFormatter{})
+	log.Set("user", "alice").Set("http", Group("status", 200, "ok", true)).Set("tags", []string{"a", "b"}).Set("none", nil)
+
+	log.Print("done")
+	checkLast(c, t, `{
+  "level": "info",
+  "msg":   "done",
+  "user":  "alice",
+  "http":  {
+    "status": 200,
+    "ok":     true
+  },
+  "tags":  [
+    "a",
+    "b"
+  ],
+  "none":  null
+}`)
+
+	log.SetFormatter(PrettyJSONFormatter{Compact: true})
+	log.Print("done")
+	checkLast(c, t, `{"level": "info", "msg": "done", "user": "alice", "http": {"status": 200, "ok": true}, "tags": ["a", "b"], "none": null}`)
+
+	log = New(t)
+	log.SetFlags(0)
+	log.SetFormatter(PrettyJSONFormatter{Color: true, Compact: true})
+	log.With("n", -1.5).Print("x")
+	checkLast(c, t, "{\x1b[34m\"level\"\x1b[0m: \x1b[32m\"info\"\x1b[0m, \x1b[34m\"msg\"\x1b[0m: \x1b[32m\"x\"\x1b[0m, \x1b[34m\"n\"\x1b[0m: \x1b[33m-1.5\x1b[0m}")
+
+	f, err := buildFormatter("pretty-json", false)
+	c.Assert(err, check.IsNil)
+	c.Assert(f, check.Equals, PrettyJSONFormatter{})
+	log, err = Build(Config{Format: "pretty-json-compact", Color: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(log.formatter.get(), check.Equals, PrettyJSONFormatter{Color: true, Compact: true})
+	log, err = Build(Config{Format: "console", Color: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(log.formatter.get(), check.Equals, ConsoleFormatter{Color: true})
+}
@@ -0,0 +1,46 @@
+package alog
+
+import (
+	"net/http"
+	"runtime/debug"
+)
+
+// Recovers a panic and logs its value and stack with the logger's prefix,
+// at PanicLevel so that it is always written.  Must be deferred directly:
+//
+//	defer log.Recover()
+func (a *Log) Recover() {
+	if v := recover(); v != nil {
+		a.logPanic(v)
+	}
+}
+
+// Wraps an http.Handler, recovering panics in h.  The panic is logged and
+// the client receives a 500.  The request's logger is used if its context
+// has one, e.g. from RequestIDHandler.  http.ErrAbortHandler is re-panicked
+// so that net/http can abort the response as usual.
+func (a *Log) RecoverHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			log := FromContext(r.Context())
+			if log == nil {
+				log = a
+			}
+			log.logPanic(v)
+			http.Error(w, http.StatusText(http.StatusInternalServerError),
+				http.StatusInternalServerError)
+		}()
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (a *Log) logPanic(v interface{}) {
+	a.output(PanicLevel, message{fmtPrintf, "panic: %v\n%s", []interface{}{v, debug.Stack()}, nil})
+}
@@ -0,0 +1,72 @@
+package alog
+
+import (
+	"io/ioutil"
+	stdlog "log"
+	"net/http"
+	"net/http/httptest"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestRecover(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("foo", "bar")
+
+	func() {
+		defer log.Recover()
+		panic("boom")
+	}()
+	c.Assert(t.last(), check.Matches, `(?s)PANIC \[foo=bar\] panic: boom\n.*recover_test\.go.*`)
+
+	// Nothing is logged without a panic
+	n := len(t.msgs)
+	func() {
+		defer log.Recover()
+	}()
+	c.Assert(t.msgs, check.HasLen, n)
+
+	// Written even when errors are filtered out
+	log.SetLevel(FatalLevel)
+	func() {
+		defer log.Recover()
+		panic("filtered")
+	}()
+	c.Assert(t.last(), check.Matches, `(?s)PANIC \[foo=bar\] panic: filtered\n.*`)
+	log.SetLevel(InfoLevel)
+
+	// Nil safe
+	defer func(f *stdlog.Logger) { fallback = f }(fallback)
+	fallback = stdlog.New(ioutil.Discard, "", 0)
+	var nilLog *Log
+	func() {
+		defer nilLog.Recover()
+		panic("boom")
+	}()
+}
+
+func (s *Suite) TestRecoverHandler(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("foo", "bar")
+
+	h := log.RecoverHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	c.Assert(w.Code, check.Equals, http.StatusInternalServerError)
+	c.Assert(t.last(), check.Matches, `(?s)PANIC \[foo=bar\] panic: boom\n.*`)
+
+	// ErrAbortHandler passes through
+	h = log.RecoverHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	c.Assert(func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}, check.PanicMatches, ".*abort Handler.*")
+}
@@ -0,0 +1,70 @@
+package alog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// Longest incoming request id accepted.  Longer or malformed ids are
+// replaced, so clients can't inject arbitrary text into the log.
+const maxRequestIDLen = 128
+
+// Returns a random 128 bit id, hex encoded
+func NewRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Returns a copy of ctx carrying the request id
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// Returns the request id carried by ctx, or ""
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// Sets the X-Request-ID header of an outgoing request from its context, so
+// the id follows the request to other services
+func PropagateRequestID(r *http.Request) {
+	if id := RequestIDFromContext(r.Context()); id != "" {
+		r.Header.Set(RequestIDHeader, id)
+	}
+}
+
+// Wraps an http.Handler, giving each request an id and a logger.  The id is
+// taken from the X-Request-ID header, or generated, and is echoed in the
+// response.  The request's context carries the id and a copy of the logger
+// with a request_id field; retrieve it with FromContext.
+func (a *Log) RequestIDHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = NewRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		ctx := ContextWithRequestID(r.Context(), id)
+		ctx = NewContext(ctx, a.With("request_id", id))
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
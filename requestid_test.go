@@ -0,0 +1,78 @@
+package alog
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestContext(c *check.C) {
+	c.Assert(FromContext(context.Background()), check.IsNil)
+
+	log := New(&Thief{})
+	ctx := NewContext(context.Background(), log)
+	c.Assert(FromContext(ctx), check.Equals, log)
+}
+
+func (s *Suite) TestRequestIDHandler(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("app", "api")
+
+	var gotID string
+	h := log.RequestIDHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = RequestIDFromContext(r.Context())
+		FromContext(r.Context()).Print("handled")
+	}))
+
+	// Incoming id is used
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	h.ServeHTTP(w, req)
+	c.Assert(gotID, check.Equals, "abc-123")
+	c.Assert(w.Header().Get(RequestIDHeader), check.Equals, "abc-123")
+	checkLast(c, t, "[app=api request_id=abc-123] handled")
+
+	// The base logger is unchanged
+	log.Print("test")
+	checkLast(c, t, "[app=api] test")
+
+	// Missing or malformed ids are generated
+	for _, id := range []string{"", "a b", "x\n[fake] line", strings.Repeat("a", 129)} {
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(RequestIDHeader, id)
+		h.ServeHTTP(w, req)
+		c.Assert(gotID, check.Matches, "[0-9a-f]{32}")
+		c.Assert(w.Header().Get(RequestIDHeader), check.Equals, gotID)
+	}
+
+	// Propagation to outgoing requests
+	out := httptest.NewRequest("GET", "/", nil)
+	PropagateRequestID(out)
+	c.Assert(out.Header.Get(RequestIDHeader), check.Equals, "")
+	out = out.WithContext(ContextWithRequestID(out.Context(), "abc"))
+	PropagateRequestID(out)
+	c.Assert(out.Header.Get(RequestIDHeader), check.Equals, "abc")
+}
+
+func (s *Suite) TestRecoverHandlerRequestLogger(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+
+	h := log.RequestIDHandler(log.RecoverHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})))
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "abc")
+	h.ServeHTTP(w, req)
+	c.Assert(w.Code, check.Equals, http.StatusInternalServerError)
+	c.Assert(t.last(), check.Matches, `(?s)PANIC \[request_id=abc\] panic: boom\n.*`)
+}
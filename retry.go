@@ -0,0 +1,125 @@
+package alog
+
+import (
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Controls how RetryWriter retries failed writes.  Zero fields take
+// defaults.
+type RetryPolicy struct {
+	Attempts int           // Total attempts per write, 3 by default
+	Initial  time.Duration // Delay before the first retry, 100ms by default
+	Max      time.Duration // Maximum delay, 5s by default
+	Jitter   float64       // Fraction of each delay that is randomized, 0 to 1
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.Attempts < 1 {
+		p.Attempts = 3
+	}
+	if p.Initial <= 0 {
+		p.Initial = 100 * time.Millisecond
+	}
+	if p.Max <= 0 {
+		p.Max = 5 * time.Second
+	}
+	return p
+}
+
+// Returns the delay before retry n, counting from 0
+func (p RetryPolicy) delay(n int) time.Duration {
+	d := p.Initial
+	for i := 0; i < n && d < p.Max; i++ {
+		d *= 2
+	}
+	if d > p.Max {
+		d = p.Max
+	}
+	if p.Jitter > 0 {
+		j := float64(d) * p.Jitter
+		d = time.Duration(float64(d) - j + rand.Float64()*j)
+	}
+	return d
+}
+
+// Writer that retries failed writes to a network destination with
+// exponential backoff, so transient failures don't drop entries.  Only the
+// unwritten part of a partial write is retried.  The error from the last
+// attempt is returned once the attempts are used up.  Entries reach a
+// LevelWriter or EntryWriter destination as they would without the
+// RetryWriter, and an EntryWriter's entries are retried whole.
+type RetryWriter struct {
+	w      io.Writer
+	policy RetryPolicy
+	sleep  func(time.Duration)
+	mutex  sync.Mutex
+}
+
+func NewRetryWriter(w io.Writer, p RetryPolicy) *RetryWriter {
+	return &RetryWriter{w: w, policy: p.withDefaults(), sleep: time.Sleep}
+}
+
+func (r *RetryWriter) Write(p []byte) (int, error) {
+	return r.write(InfoLevel, nil, p)
+}
+
+func (r *RetryWriter) WriteLevel(level Level, p []byte) (int, error) {
+	return r.write(level, nil, p)
+}
+
+func (r *RetryWriter) WriteEntry(e *Entry, p []byte) (int, error) {
+	return r.write(e.Level, e, p)
+}
+
+// The logger only builds entries for an EntryWriter destination
+func (r *RetryWriter) wantsEntries() bool {
+	_, ok := r.w.(EntryWriter)
+	return ok
+}
+
+func (r *RetryWriter) write(level Level, e *Entry, p []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ew, _ := r.w.(EntryWriter)
+	lw, _ := r.w.(LevelWriter)
+	var written int
+	var err error
+	for i := 0; i < r.policy.Attempts; i++ {
+		if i > 0 {
+			r.sleep(r.policy.delay(i - 1))
+		}
+		var n int
+		switch {
+		case e != nil && ew != nil:
+			if _, err = ew.WriteEntry(e, p); err == nil {
+				return len(p), nil
+			}
+			continue
+		case lw != nil:
+			n, err = lw.WriteLevel(level, p[written:])
+		default:
+			n, err = r.w.Write(p[written:])
+		}
+		written += n
+		if err == nil {
+			return written, nil
+		}
+	}
+	return written, err
+}
+
+func (r *RetryWriter) Status() []SinkStatus {
+	return writerStatus(r.w)
+}
+
+func (r *RetryWriter) Flush() error {
+	return flushWriter(r.w)
+}
+
+func (r *RetryWriter) Close() error {
+	return closeWriter(r.w)
+}
@@ -0,0 +1,92 @@
+package alog
+
+import (
+	"errors"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+// Fails the first n writes, writing half of the data each time
+type flakyWriter struct {
+	Thief
+	failures int
+}
+
+func (f *flakyWriter) Write(p []byte) (int, error) {
+	if f.failures > 0 {
+		f.failures--
+		n := len(p) / 2
+		f.Thief.Write(p[:n])
+		return n, errors.New("flaky")
+	}
+	return f.Thief.Write(p)
+}
+
+func (s *Suite) TestRetryWriter(c *check.C) {
+	f := &flakyWriter{failures: 2}
+	r := NewRetryWriter(f, RetryPolicy{Initial: time.Second, Max: 3 * time.Second})
+	var delays []time.Duration
+	r.sleep = func(d time.Duration) { delays = append(delays, d) }
+
+	n, err := r.Write([]byte("abcdefgh"))
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 8)
+	c.Assert(f.msgs, check.DeepEquals, []string{"abcd", "ef", "gh"})
+	c.Assert(delays, check.DeepEquals, []time.Duration{time.Second, 2 * time.Second})
+
+	// Gives up after the attempts are used
+	f = &flakyWriter{failures: 5}
+	r = NewRetryWriter(f, RetryPolicy{Attempts: 2})
+	r.sleep = func(time.Duration) {}
+	n, err = r.Write([]byte("abcdefgh"))
+	c.Assert(err, check.ErrorMatches, "flaky")
+	c.Assert(n, check.Equals, 6)
+}
+
+// Fails the first n entries
+type flakyEntryWriter struct {
+	entryThief
+	failures int
+}
+
+func (f *flakyEntryWriter) WriteEntry(e *Entry, p []byte) (int, error) {
+	if f.failures > 0 {
+		f.failures--
+		return 0, errors.New("flaky")
+	}
+	return f.entryThief.WriteEntry(e, p)
+}
+
+func (s *Suite) TestRetryWriterForwards(c *check.C) {
+	lt := &levelThief{}
+	log := New(NewRetryWriter(lt, RetryPolicy{}))
+	log.SetFlags(0)
+	log.Warn("a")
+	c.Assert(lt.levels, check.DeepEquals, []Level{WarnLevel})
+
+	f := &flakyEntryWriter{failures: 1}
+	r := NewRetryWriter(f, RetryPolicy{})
+	r.sleep = func(time.Duration) {}
+	log = New(r)
+	log.Error("b")
+	c.Assert(f.entries, check.HasLen, 1)
+	c.Assert(f.entries[0].Message, check.Equals, "b")
+	c.Assert(f.entries[0].Level, check.Equals, ErrorLevel)
+}
+
+func (s *Suite) TestRetryPolicyDelay(c *check.C) {
+	p := RetryPolicy{Initial: time.Second, Max: 5 * time.Second}.withDefaults()
+	c.Assert(p.Attempts, check.Equals, 3)
+	c.Assert(p.delay(0), check.Equals, time.Second)
+	c.Assert(p.delay(1), check.Equals, 2*time.Second)
+	c.Assert(p.delay(2), check.Equals, 4*time.Second)
+	c.Assert(p.delay(3), check.Equals, 5*time.Second)
+	c.Assert(p.delay(100), check.Equals, 5*time.Second)
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := p.delay(0)
+		c.Assert(d >= 500*time.Millisecond && d <= time.Second, check.Equals, true)
+	}
+}
@@ -0,0 +1,124 @@
+package alog
+
+import (
+	"io"
+	"sync"
+)
+
+// Writer that keeps the last N entries in memory, for dumping on demand.
+// Each Write is one entry, as written by the logger.  Combine it with
+// another destination using io.MultiWriter.  Entries are kept with their
+// level, for Handler's level filter; those written without one, e.g.
+// through io.MultiWriter, count as InfoLevel.
+type Ring struct {
+	entries []ringEntry
+	next    int
+	full    bool
+	subs    map[chan ringEntry]struct{}
+	mutex   sync.Mutex
+}
+
+type ringEntry struct {
+	level Level
+	text  string
+}
+
+func NewRing(n int) *Ring {
+	if n < 1 {
+		n = 1
+	}
+	return &Ring{entries: make([]ringEntry, n)}
+}
+
+func (r *Ring) Write(p []byte) (int, error) {
+	return r.WriteLevel(InfoLevel, p)
+}
+
+func (r *Ring) WriteLevel(level Level, p []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	e := ringEntry{level, string(p)}
+	r.entries[r.next] = e
+	r.next++
+	if r.next == len(r.entries) {
+		r.next = 0
+		r.full = true
+	}
+
+	// Slow subscribers miss entries rather than blocking the logger
+	for ch := range r.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+// Returns the retained entries, oldest first
+func (r *Ring) Snapshot() []string {
+	r.mutex.Lock()
+	entries := r.snapshot()
+	r.mutex.Unlock()
+
+	s := make([]string, len(entries))
+	for i, e := range entries {
+		s[i] = e.text
+	}
+	return s
+}
+
+func (r *Ring) snapshot() []ringEntry {
+	if !r.full {
+		return append([]ringEntry(nil), r.entries[:r.next]...)
+	}
+	s := make([]ringEntry, 0, len(r.entries))
+	s = append(s, r.entries[r.next:]...)
+	return append(s, r.entries[:r.next]...)
+}
+
+// Returns a snapshot of the retained entries and a channel receiving new
+// ones until unsubscribe is called
+func (r *Ring) subscribe() ([]ringEntry, chan ringEntry) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ch := make(chan ringEntry, 64)
+	if r.subs == nil {
+		r.subs = make(map[chan ringEntry]struct{})
+	}
+	r.subs[ch] = struct{}{}
+	return r.snapshot(), ch
+}
+
+func (r *Ring) unsubscribe(ch chan ringEntry) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.subs, ch)
+}
+
+// Writes the retained entries to w, oldest first
+func (r *Ring) WriteTo(w io.Writer) (int64, error) {
+	var n int64
+	for _, e := range r.Snapshot() {
+		m, err := io.WriteString(w, e)
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Discards the retained entries
+func (r *Ring) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.entries {
+		r.entries[i] = ringEntry{}
+	}
+	r.next = 0
+	r.full = false
+}
@@ -0,0 +1,120 @@
+package alog
+
+import (
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Returns an http.Handler serving the ring's entries as plain text.
+// Query parameters:
+//
+//	level=warn only entries at the level or above
+//	match=k=v  only entries with field k=v; may be repeated
+//	q=text     only entries containing text
+//	follow=1   keep the connection open and stream new entries as
+//	           server-sent events
+func (r *Ring) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		filter := ringFilter{matches: query["match"], q: query.Get("q")}
+		if s := query.Get("level"); s != "" {
+			level, err := ParseLevel(s)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			filter.level, filter.leveled = level, true
+		}
+
+		if query.Get("follow") == "" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			r.mutex.Lock()
+			snap := r.snapshot()
+			r.mutex.Unlock()
+			for _, e := range snap {
+				if filter.keep(e) {
+					io.WriteString(w, e.text)
+				}
+			}
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		snap, ch := r.subscribe()
+		defer r.unsubscribe(ch)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		for _, e := range snap {
+			if filter.keep(e) {
+				writeEvent(w, e.text)
+			}
+		}
+		flusher.Flush()
+
+		for {
+			select {
+			case e := <-ch:
+				if filter.keep(e) {
+					writeEvent(w, e.text)
+					flusher.Flush()
+				}
+			case <-req.Context().Done():
+				return
+			}
+		}
+	})
+}
+
+type ringFilter struct {
+	matches []string
+	q       string
+	level   Level
+	leveled bool
+}
+
+func (f ringFilter) keep(e ringEntry) bool {
+	if f.leveled && e.level < f.level {
+		return false
+	}
+	if f.q != "" && !strings.Contains(e.text, f.q) {
+		return false
+	}
+	for _, m := range f.matches {
+		if !hasField(e.text, m) {
+			return false
+		}
+	}
+	return true
+}
+
+// Reports whether the formatted entry e has the field kv ("k=v") in its prefix
+func hasField(e, kv string) bool {
+	for i := 0; ; {
+		j := strings.Index(e[i:], kv)
+		if j < 0 {
+			return false
+		}
+		j += i
+		end := j + len(kv)
+		if j > 0 && (e[j-1] == '[' || e[j-1] == ' ') &&
+			end < len(e) && (e[end] == ']' || e[end] == ' ') {
+			return true
+		}
+		i = j + 1
+	}
+}
+
+// Writes one server-sent event; a multi-line entry becomes multi-line data
+func writeEvent(w io.Writer, e string) {
+	for _, line := range strings.Split(strings.TrimSuffix(e, "\n"), "\n") {
+		io.WriteString(w, "data: "+line+"\n")
+	}
+	io.WriteString(w, "\n")
+}
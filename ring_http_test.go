@@ -0,0 +1,120 @@
+package alog
+
+import (
+	"bufio"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestRingHandler(c *check.C) {
+	r := NewRing(10)
+	log := New(r)
+	log.SetFlags(0)
+
+	log.With("user", "a").Print("one")
+	log.With("user", "b").Print("two")
+	log.With("user", "ab").Print("three")
+	log.Print("user=a")
+
+	get := func(url string) string {
+		w := httptest.NewRecorder()
+		r.Handler().ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		c.Assert(w.Code, check.Equals, http.StatusOK)
+		return w.Body.String()
+	}
+
+	c.Assert(get("/"), check.Equals, "[user=a] one\n[user=b] two\n[user=ab] three\nuser=a\n")
+	c.Assert(get("/?match=user=a"), check.Equals, "[user=a] one\n")
+	c.Assert(get("/?q=t"), check.Equals, "[user=b] two\n[user=ab] three\n")
+	c.Assert(get("/?q=t&match=user=b"), check.Equals, "[user=b] two\n")
+
+	log.Warn("four")
+	log.With("user", "a").Error("five")
+	c.Assert(get("/?level=warn"), check.Equals, "WARN four\nERROR [user=a] five\n")
+	c.Assert(get("/?level=ERROR&match=user=a"), check.Equals, "ERROR [user=a] five\n")
+
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/?level=loud", nil))
+	c.Assert(w.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *Suite) TestRingHandlerFollowLevel(c *check.C) {
+	r := NewRing(10)
+	log := New(r)
+	log.SetFlags(0)
+	log.Print("old")
+	log.Error("old error")
+
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", srv.URL+"?follow=1&level=error", nil)
+	c.Assert(err, check.IsNil)
+	res, err := http.DefaultClient.Do(req)
+	c.Assert(err, check.IsNil)
+	defer res.Body.Close()
+
+	br := bufio.NewReader(res.Body)
+	log.Warn("skipped")
+	log.Error("new error")
+
+	var lines []string
+	for len(lines) < 4 {
+		line, err := br.ReadString('\n')
+		c.Assert(err, check.IsNil)
+		lines = append(lines, line)
+	}
+	c.Assert(lines, check.DeepEquals, []string{
+		"data: ERROR old error\n", "\n",
+		"data: ERROR new error\n", "\n",
+	})
+
+	cancel()
+	ioutil.ReadAll(br)
+}
+
+func (s *Suite) TestRingHandlerFollow(c *check.C) {
+	r := NewRing(10)
+	log := New(r)
+	log.SetFlags(0)
+	log.Print("old")
+
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", srv.URL+"?follow=1&match=k=v", nil)
+	c.Assert(err, check.IsNil)
+	res, err := http.DefaultClient.Do(req)
+	c.Assert(err, check.IsNil)
+	defer res.Body.Close()
+	c.Assert(res.Header.Get("Content-Type"), check.Equals, "text/event-stream")
+
+	// The snapshot is filtered too; once the stream is established, log a
+	// matching multi-line entry
+	br := bufio.NewReader(res.Body)
+	log.With("k", "v").Print("a\nb")
+	log.With("k", "w").Print("skipped")
+	log.With("k", "v").Print("new")
+
+	var lines []string
+	for len(lines) < 5 {
+		line, err := br.ReadString('\n')
+		c.Assert(err, check.IsNil)
+		lines = append(lines, line)
+	}
+	c.Assert(lines, check.DeepEquals, []string{
+		"data: [k=v] a\n", "data: b\n", "\n",
+		"data: [k=v] new\n", "\n",
+	})
+
+	cancel()
+	ioutil.ReadAll(br)
+}
@@ -0,0 +1,35 @@
+package alog
+
+import (
+	"bytes"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestRing(c *check.C) {
+	r := NewRing(3)
+	log := New(r)
+	log.SetFlags(0)
+
+	c.Assert(r.Snapshot(), check.HasLen, 0)
+
+	log.Print("one")
+	log.Print("two")
+	c.Assert(r.Snapshot(), check.DeepEquals, []string{"one\n", "two\n"})
+
+	// Wraps around, keeping the newest
+	log.Print("three")
+	log.Print("four")
+	c.Assert(r.Snapshot(), check.DeepEquals, []string{"two\n", "three\n", "four\n"})
+
+	var buf bytes.Buffer
+	n, err := r.WriteTo(&buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, int64(buf.Len()))
+	c.Assert(buf.String(), check.Equals, "two\nthree\nfour\n")
+
+	r.Reset()
+	c.Assert(r.Snapshot(), check.HasLen, 0)
+	log.Print("five")
+	c.Assert(r.Snapshot(), check.DeepEquals, []string{"five\n"})
+}
@@ -0,0 +1,72 @@
+package alog
+
+import (
+	"context"
+	"runtime/trace"
+)
+
+// Mirrors written entries as runtime/trace user log events while a trace
+// is being recorded, so that `go tool trace` shows them on the timeline.
+// Each event's category is the level, and its message the entry's
+// message followed by the fields with keys, e.g. "query done
+// [request_id=abc]".  Events belong to the task of the logger's trace
+// context, if WithTraceContext gave it one.  Entries cost nothing extra when
+// no trace is running.  Copies keep the setting.
+func (a *Log) SetRuntimeTrace(enabled bool, keys ...string) *Log {
+	if a == nil {
+		return nil
+	}
+	a.runtimeTrace = enabled
+	a.traceKeys = append([]string(nil), keys...)
+	return a
+}
+
+// Returns a copy of the logger whose runtime trace events, and regions
+// started with StartRegion, belong to the task carried by ctx, e.g. one
+// from trace.NewTask.
+func (a *Log) WithTraceContext(ctx context.Context) *Log {
+	if a == nil {
+		return nil
+	}
+	b := a.Copy()
+	b.traceCtx = ctx
+	return b
+}
+
+// Starts a runtime/trace region named name in the logger's trace context.
+// Call End on the result, typically deferred, from the same goroutine.
+func (a *Log) StartRegion(name string) *trace.Region {
+	return trace.StartRegion(a.traceContext(), name)
+}
+
+func (a *Log) traceContext() context.Context {
+	if a == nil || a.traceCtx == nil {
+		return context.Background()
+	}
+	return a.traceCtx
+}
+
+func (a *Log) logTraceEvent(level Level, m message) {
+	if !a.runtimeTrace || !trace.IsEnabled() {
+		return
+	}
+	b := m.appendTo(nil)
+	if n := len(b); n > 0 && b[n-1] == '\n' {
+		b = b[:n-1]
+	}
+	if len(a.traceKeys) > 0 {
+		all := flattenFields(a.formatValues(append(a.entryFields(), m.fields...)))
+		var fields []Field
+		for _, k := range a.traceKeys {
+			if v, ok := lookupField(all, k); ok {
+				fields = append(fields, Field{k, v})
+			}
+		}
+		if len(fields) > 0 {
+			b = append(b, " ["...)
+			b = appendFields(b, fields, " ")
+			b = append(b, ']')
+		}
+	}
+	trace.Log(a.traceContext(), level.String(), a.scrubString(string(b)))
+}
@@ -0,0 +1,53 @@
+package alog
+
+import (
+	"bytes"
+	"context"
+	"runtime/trace"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestRuntimeTrace(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("request_id", "abc").Set("user", "alice")
+
+	var buf bytes.Buffer
+	c.Assert(trace.Start(&buf), check.IsNil)
+	log.Print("untraced entry")
+	log.SetRuntimeTrace(true, "request_id", "missing")
+	log.Warn("traced entry")
+	trace.Stop()
+
+	checkLast(c, t, "WARN [request_id=abc user=alice] traced entry")
+	c.Assert(bytes.Contains(buf.Bytes(), []byte("traced entry [request_id=abc]")), check.Equals, true)
+	c.Assert(bytes.Contains(buf.Bytes(), []byte("untraced entry")), check.Equals, false)
+}
+
+func (s *Suite) TestRuntimeTraceContext(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.SetRuntimeTrace(true)
+
+	var buf bytes.Buffer
+	c.Assert(trace.Start(&buf), check.IsNil)
+	ctx, task := trace.NewTask(context.Background(), "request")
+	tlog := log.WithTraceContext(ctx)
+	region := tlog.StartRegion("query")
+	tlog.Warn("in task")
+	region.End()
+	task.End()
+	trace.Stop()
+
+	checkLast(c, t, "WARN in task")
+	c.Assert(tlog.traceContext(), check.Equals, ctx)
+	c.Assert(log.traceContext(), check.Equals, context.Background())
+	c.Assert(bytes.Contains(buf.Bytes(), []byte("in task")), check.Equals, true)
+	c.Assert(bytes.Contains(buf.Bytes(), []byte("query")), check.Equals, true)
+
+	var nilLog *Log
+	nilLog.StartRegion("nil").End()
+}
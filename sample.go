@@ -0,0 +1,77 @@
+package alog
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+// Sampling settings, shared by a logger and its copies
+type sampling struct {
+	key    string
+	rate   float64
+	exempt Level
+	mutex  sync.RWMutex
+}
+
+// Keeps about rate (0 to 1) of the entries that have a value for key,
+// e.g. a trace or request id.  The decision is made by hashing the value,
+// so all entries for a given request are kept or dropped together.
+// Entries without the key, and those at or above the level set with
+// SetSamplingExempt, are always written.  An empty key disables sampling.  Like the level, the setting is shared
+// with the logger's copies.
+func (a *Log) SetSampling(key string, rate float64) *Log {
+	if a == nil {
+		return nil
+	}
+	a.sampling.mutex.Lock()
+	defer a.sampling.mutex.Unlock()
+	a.sampling.key = key
+	a.sampling.rate = rate
+	return a
+}
+
+// Exempts entries at level and above from sampling, so that e.g. with
+// WarnLevel the warnings and errors of requests that are sampled away are
+// still written.  The default is PanicLevel.  Shared with the logger's
+// copies.
+func (a *Log) SetSamplingExempt(level Level) *Log {
+	if a == nil {
+		return nil
+	}
+	a.sampling.mutex.Lock()
+	defer a.sampling.mutex.Unlock()
+	a.sampling.exempt = level
+	return a
+}
+
+func (a *Log) sampled(level Level) bool {
+	a.sampling.mutex.RLock()
+	key, rate, exempt := a.sampling.key, a.sampling.rate, a.sampling.exempt
+	a.sampling.mutex.RUnlock()
+
+	if key == "" || rate >= 1 || level >= exempt {
+		return true
+	}
+	v := a.Meta.get(key)
+	if v == nil {
+		return true
+	}
+	return sampleHash(v) < rate
+}
+
+// Maps v to [0, 1)
+func sampleHash(v interface{}) float64 {
+	h := fnv.New64a()
+	fmt.Fprint(h, v)
+
+	// FNV's high bits are poorly mixed for short inputs; apply murmur3's
+	// finalizer before scaling
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return float64(x>>11) / (1 << 53)
+}
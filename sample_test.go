@@ -0,0 +1,59 @@
+package alog
+
+import (
+	"fmt"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestSampling(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	c.Assert(log.SetSampling("trace", 0.5), check.Equals, log)
+
+	// Entries without the key are kept
+	log.Print("test")
+	checkLast(c, t, "test")
+
+	// Decisions are consistent per value, and roughly match the rate
+	kept := 0
+	for i := 0; i < 1000; i++ {
+		l := log.With("trace", i)
+		n := len(t.msgs)
+		l.Print("a")
+		l.Print("b")
+		switch len(t.msgs) - n {
+		case 2:
+			kept++
+		case 0:
+		default:
+			c.Fatalf("trace %d partially sampled", i)
+		}
+	}
+	c.Assert(kept > 400 && kept < 600, check.Equals, true, check.Commentf("kept %d", kept))
+
+	// Panic is never sampled out
+	for i := 0; i < 100; i++ {
+		l := log.With("trace", i)
+		n := len(t.msgs)
+		c.Assert(func() { l.Panic("x") }, check.Panics, "x")
+		c.Assert(t.msgs, check.HasLen, n+1, check.Commentf("trace %d", i))
+	}
+
+	// Rate 0 drops everything with the key
+	log.SetSampling("trace", 0)
+	n := len(t.msgs)
+	for i := 0; i < 100; i++ {
+		log.With("trace", fmt.Sprint(i)).Print("x")
+	}
+	c.Assert(t.msgs, check.HasLen, n)
+
+	// Disabled
+	log.SetSampling("", 0)
+	log.With("trace", 1).Print("x")
+	c.Assert(t.msgs, check.HasLen, n+1)
+
+	var nilLog *Log
+	c.Assert(nilLog.SetSampling("trace", 0), check.IsNil)
+}
@@ -0,0 +1,97 @@
+package alog
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Escapes newlines and other control characters in messages and field
+// values, so user-supplied strings can't forge extra log lines or
+// terminal escape sequences.  \n, \r and \t are written as such, and other
+// control characters as \xNN or \uNNNN.  With a Formatter, strings, errors
+// and fmt.Stringers in fields are escaped before formatting.  Copies keep
+// the setting.
+func (a *Log) SetSanitize(enabled bool) *Log {
+	if a == nil {
+		return nil
+	}
+	a.sanitize = enabled
+	return a
+}
+
+// Control characters, including C1 controls, DEL and the Unicode line and
+// paragraph separators
+func needsEscape(r rune) bool {
+	return r < 0x20 || r == 0x7f || (r >= 0x80 && r <= 0x9f) || r == 0x2028 || r == 0x2029
+}
+
+// Escapes the control characters in buf[start:], a formatted entry without
+// its final newline
+func sanitize(buf []byte, start int) []byte {
+	i := start
+	for i < len(buf) {
+		r, n := utf8.DecodeRune(buf[i:])
+		if needsEscape(r) {
+			break
+		}
+		i += n
+	}
+	if i == len(buf) {
+		return buf
+	}
+
+	tail := append([]byte(nil), buf[i:]...)
+	buf = buf[:i]
+	for len(tail) > 0 {
+		r, n := utf8.DecodeRune(tail)
+		tail = tail[n:]
+		if !needsEscape(r) {
+			buf = utf8.AppendRune(buf, r)
+			continue
+		}
+		switch r {
+		case '\n':
+			buf = append(buf, `\n`...)
+		case '\r':
+			buf = append(buf, `\r`...)
+		case '\t':
+			buf = append(buf, `\t`...)
+		default:
+			q := strconv.QuoteRuneToASCII(r)
+			buf = append(buf, q[1:len(q)-1]...)
+		}
+	}
+	return buf
+}
+
+func sanitizeString(s string) string {
+	return string(sanitize([]byte(s), 0))
+}
+
+// Returns fields with their string, error and fmt.Stringer values escaped.
+// Errors stay errors with escaped text, and other values are left alone.
+func sanitizeFields(fields []Field) []Field {
+	out := make([]Field, len(fields))
+	for i, f := range fields {
+		out[i] = Field{sanitizeString(f.Key), sanitizeValue(resolveValue(f.Value))}
+	}
+	return out
+}
+
+func sanitizeValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case string:
+		return sanitizeString(x)
+	case GroupValue:
+		return GroupValue(sanitizeFields(x))
+	case error:
+		if e, ok := x.(scrubbedError); ok {
+			return scrubbedError{e.err, sanitizeString(e.text)}
+		}
+		return scrubbedError{x, sanitizeString(x.Error())}
+	case fmt.Stringer:
+		return sanitizeString(x.String())
+	}
+	return v
+}
@@ -0,0 +1,26 @@
+package alog
+
+import "gopkg.in/check.v1"
+
+func (s *Suite) TestSanitize(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.SetSanitize(true)
+	log.Set("user", "bob\n[user=admin] granted")
+
+	log.Print("login\r\nERROR fake entry\x1b[31m\t\u2028")
+	checkLast(c, t, `[user=bob\n[user=admin] granted] login\r\nERROR fake entry\x1b[31m\t\u2028`)
+
+	// The entry's own newline is kept
+	log.Println("ok")
+	checkLast(c, t, "[user=bob\\n[user=admin] granted] ok")
+
+	// Copies keep the setting; unicode is untouched
+	log.With("city", "Zürich").Print("héllo")
+	checkLast(c, t, `[user=bob\n[user=admin] granted city=Zürich] héllo`)
+
+	log.SetSanitize(false)
+	log.Print("a\nb")
+	checkLast(c, t, "[user=bob\n[user=admin] granted] a\nb")
+}
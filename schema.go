@@ -0,0 +1,106 @@
+package alog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Identifies the layout of a service's entries, written as "name/version",
+// e.g. "orders/3", so that consumers know which field names to expect
+type Schema struct {
+	Name    string
+	Version int
+}
+
+func (s Schema) String() string {
+	return s.Name + "/" + strconv.Itoa(s.Version)
+}
+
+// Parses a schema written as "name/version"
+func ParseSchema(s string) (Schema, error) {
+	i := strings.LastIndexByte(s, '/')
+	if i <= 0 {
+		return Schema{}, fmt.Errorf("alog: invalid schema %q", s)
+	}
+	v, err := strconv.Atoi(s[i+1:])
+	if err != nil || v < 0 {
+		return Schema{}, fmt.Errorf("alog: invalid schema %q", s)
+	}
+	return Schema{s[:i], v}, nil
+}
+
+// Adds a schema field, such as schema=orders/3, to every entry given to a
+// Formatter or EntryWriter.  Text entries without a formatter are left
+// alone.  A zero Schema removes it.  Copies keep the setting.
+func (a *Log) SetSchema(s Schema) *Log {
+	if a == nil {
+		return nil
+	}
+	a.schema = s
+	return a
+}
+
+var ErrNoSchema = errors.New("alog: entry has no schema")
+
+// Returns the schema of a JSON entry, read from its top level "schema"
+// key, or from the labels of the ECS and Google Cloud layouts
+func EntrySchema(line []byte) (Schema, error) {
+	var e struct {
+		Schema    string            `json:"schema"`
+		Labels    map[string]string `json:"labels"`
+		GCPLabels map[string]string `json:"logging.googleapis.com/labels"`
+	}
+	if err := json.Unmarshal(line, &e); err != nil {
+		return Schema{}, err
+	}
+	s := e.Schema
+	if s == "" {
+		s = e.Labels["schema"]
+	}
+	if s == "" {
+		s = e.GCPLabels["schema"]
+	}
+	if s == "" {
+		return Schema{}, ErrNoSchema
+	}
+	return ParseSchema(s)
+}
+
+// Passes JSON entries to the handler for their schema, for consumers of
+// entries from services on different schema versions:
+//
+//	var r alog.SchemaRouter
+//	r.Handle(alog.Schema{"orders", 2}, parseOrdersV2)
+//	r.Handle(alog.Schema{"orders", 3}, parseOrdersV3)
+//	err := r.Dispatch(line)
+type SchemaRouter struct {
+	// Called for entries without a handler for their schema, or without
+	// a schema.  If nil, Dispatch returns an error for them.
+	Fallback func(line []byte) error
+
+	handlers map[Schema]func(line []byte) error
+}
+
+func (r *SchemaRouter) Handle(s Schema, h func(line []byte) error) {
+	if r.handlers == nil {
+		r.handlers = make(map[Schema]func(line []byte) error)
+	}
+	r.handlers[s] = h
+}
+
+func (r *SchemaRouter) Dispatch(line []byte) error {
+	s, err := EntrySchema(line)
+	if err == nil {
+		if h, ok := r.handlers[s]; ok {
+			return h(line)
+		}
+		err = fmt.Errorf("alog: no handler for schema %s", s)
+	}
+	if r.Fallback != nil {
+		return r.Fallback(line)
+	}
+	return err
+}
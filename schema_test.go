@@ -0,0 +1,65 @@
+package alog
+
+import (
+	"errors"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestSchema(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.SetSchema(Schema{"orders", 3}).Set("id", 1)
+
+	// Text entries are left alone
+	log.Print("x")
+	checkLast(c, t, "[id=1] x")
+
+	log.SetFormatter(JSONFormatter{})
+	log.Copy().Print("x")
+	checkLast(c, t, `{"level":"info","msg":"x","id":1,"schema":"orders/3"}`)
+	sc, err := EntrySchema([]byte(t.last()))
+	c.Assert(err, check.IsNil)
+	c.Assert(sc, check.Equals, Schema{"orders", 3})
+
+	log.SetFormatter(ECSFormatter{})
+	log.Print("x")
+	sc, err = EntrySchema([]byte(t.last()))
+	c.Assert(err, check.IsNil)
+	c.Assert(sc, check.Equals, Schema{"orders", 3})
+
+	_, err = EntrySchema([]byte(`{"msg":"x"}`))
+	c.Assert(err, check.Equals, ErrNoSchema)
+	_, err = ParseSchema("orders")
+	c.Assert(err, check.ErrorMatches, `alog: invalid schema "orders"`)
+	sc, err = ParseSchema("a/b/12")
+	c.Assert(err, check.IsNil)
+	c.Assert(sc, check.Equals, Schema{"a/b", 12})
+	c.Assert(sc.String(), check.Equals, "a/b/12")
+	log.SetFormatter(nil)
+}
+
+func (s *Suite) TestSchemaRouter(c *check.C) {
+	var got []string
+	var r SchemaRouter
+	r.Handle(Schema{"orders", 2}, func(line []byte) error {
+		got = append(got, "v2")
+		return nil
+	})
+	r.Handle(Schema{"orders", 3}, func(line []byte) error {
+		got = append(got, "v3")
+		return nil
+	})
+
+	c.Assert(r.Dispatch([]byte(`{"schema":"orders/3"}`)), check.IsNil)
+	c.Assert(r.Dispatch([]byte(`{"schema":"orders/2"}`)), check.IsNil)
+	c.Assert(got, check.DeepEquals, []string{"v3", "v2"})
+	c.Assert(r.Dispatch([]byte(`{"schema":"orders/4"}`)), check.ErrorMatches, "alog: no handler for schema orders/4")
+	c.Assert(r.Dispatch([]byte(`{}`)), check.Equals, ErrNoSchema)
+
+	fallback := errors.New("fallback")
+	r.Fallback = func(line []byte) error { return fallback }
+	c.Assert(r.Dispatch([]byte(`{"schema":"orders/4"}`)), check.Equals, fallback)
+	c.Assert(r.Dispatch([]byte(`not json`)), check.Equals, fallback)
+}
@@ -0,0 +1,62 @@
+package alog
+
+// Sets k to v until the returned func is called, for temporary fields on a
+// shared logger without a copy:
+//
+//	defer log.Scope("job", id)()
+//
+// See ScopeFields.
+func (a *Log) Scope(k string, v interface{}) func() {
+	return a.ScopeFields(Field{k, v})
+}
+
+// Sets fields until the returned func is called, which removes exactly
+// those keys, or gives a key back the value it had before.  Other changes
+// made in the meantime are kept.  Keys are rewritten by the key policy, but
+// always overwrite, whatever the duplicate policy.
+func (a *Log) ScopeFields(fields ...Field) func() {
+	if a == nil {
+		return func() {}
+	}
+
+	type saved struct {
+		key    string
+		value  interface{}
+		exists bool
+	}
+	var prev []saved
+	for _, f := range fields {
+		k := f.Key
+		if a.keyPolicy != nil {
+			var err error
+			if k, err = a.keyPolicy.apply(k); err != nil {
+				if a.onError != nil {
+					a.onError(err)
+				}
+				continue
+			}
+		}
+		v, ok := a.Meta.lookup(k)
+		prev = append(prev, saved{k, v, ok})
+		a.Meta.set(k, f.Value)
+	}
+
+	return func() {
+		// In reverse, so a key scoped twice ends up as it started
+		for i := len(prev) - 1; i >= 0; i-- {
+			if p := prev[i]; p.exists {
+				a.Meta.set(p.key, p.value)
+			} else {
+				a.Meta.del(p.key)
+			}
+		}
+		prev = nil
+	}
+}
+
+func (m *Meta) lookup(k string) (interface{}, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	e, ok := m.entries[k]
+	return e.value, ok
+}
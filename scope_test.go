@@ -0,0 +1,27 @@
+package alog
+
+import "gopkg.in/check.v1"
+
+func (s *Suite) TestScope(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("app", "api").Set("user", "alice")
+
+	func() {
+		defer log.Scope("job", 7)()
+		end := log.ScopeFields(Field{"user", "bob"}, Field{"step", 1})
+		log.Set("other", true)
+		log.Print("x")
+		checkLast(c, t, "[app=api user=bob job=7 step=1 other=true] x")
+		end()
+		end()
+		log.Print("x")
+		checkLast(c, t, "[app=api user=alice job=7 other=true] x")
+	}()
+	log.Print("x")
+	checkLast(c, t, "[app=api user=alice other=true] x")
+
+	var nilLog *Log
+	nilLog.Scope("a", 1)()
+}
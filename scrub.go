@@ -0,0 +1,158 @@
+package alog
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Replaces sensitive values matched by Pattern.  Replacement may refer to
+// submatches as regexp.Expand does, e.g. "${1}[TOKEN]".  If Match is set,
+// only matches it accepts are replaced, for checks a pattern can't express.
+type Scrubber struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+	Match       func(match []byte) bool
+}
+
+var (
+	// Replaces email addresses with [EMAIL]
+	ScrubEmails = Scrubber{
+		Pattern:     regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+		Replacement: "[EMAIL]",
+	}
+	// Replaces card numbers of 13 to 19 digits, optionally separated by
+	// spaces or dashes, that pass the Luhn check, with [CARD]
+	ScrubCardNumbers = Scrubber{
+		Pattern:     regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		Replacement: "[CARD]",
+		Match:       luhn,
+	}
+	// Replaces the token after "Bearer " with [TOKEN]
+	ScrubBearerTokens = Scrubber{
+		Pattern:     regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`),
+		Replacement: "${1}[TOKEN]",
+	}
+)
+
+// Applies the scrubbers, in order, to messages and field values before
+// they are written, so sensitive data is caught wherever it appears.  With
+// a Formatter, strings, errors and fmt.Stringers in fields are scrubbed
+// before formatting.  Replaces any previous scrubbers; none removes them.
+// Copies keep the setting.
+func (a *Log) SetScrubbers(scrubbers ...Scrubber) *Log {
+	if a == nil {
+		return nil
+	}
+	a.scrubbers = append([]Scrubber(nil), scrubbers...)
+	return a
+}
+
+// Scrubs buf[start:], a formatted entry
+func (a *Log) scrub(buf []byte, start int) []byte {
+	if len(a.scrubbers) == 0 {
+		return buf
+	}
+	out := buf[start:]
+	for _, s := range a.scrubbers {
+		out = s.apply(out)
+	}
+	return append(buf[:start], out...)
+}
+
+func (s Scrubber) apply(b []byte) []byte {
+	matches := s.Pattern.FindAllSubmatchIndex(b, -1)
+	if len(matches) == 0 {
+		return b
+	}
+
+	var out []byte
+	last := 0
+	for _, m := range matches {
+		if s.Match != nil && !s.Match(b[m[0]:m[1]]) {
+			continue
+		}
+		out = append(out, b[last:m[0]]...)
+		out = s.Pattern.Expand(out, []byte(s.Replacement), b, m)
+		last = m[1]
+	}
+	if out == nil {
+		return b
+	}
+	return append(out, b[last:]...)
+}
+
+func (a *Log) scrubString(s string) string {
+	if len(a.scrubbers) == 0 {
+		return s
+	}
+	return string(a.scrub([]byte(s), 0))
+}
+
+// Returns fields with their string, error and fmt.Stringer values
+// scrubbed.  Strings and Stringers become strings, errors stay errors with
+// scrubbed text, and other values are left alone.
+func (a *Log) scrubFields(fields []Field) []Field {
+	if len(a.scrubbers) == 0 {
+		return fields
+	}
+	out := make([]Field, len(fields))
+	for i, f := range fields {
+		out[i] = Field{f.Key, a.scrubValue(resolveValue(f.Value))}
+	}
+	return out
+}
+
+func (a *Log) scrubValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case string:
+		return a.scrubString(x)
+	case GroupValue:
+		return GroupValue(a.scrubFields(x))
+	case error:
+		return scrubbedError{x, a.scrubString(x.Error())}
+	case fmt.Stringer:
+		return a.scrubString(x.String())
+	}
+	return v
+}
+
+// An error with its text scrubbed, so formatters still see an error
+type scrubbedError struct {
+	err  error
+	text string
+}
+
+func (e scrubbedError) Error() string {
+	return e.text
+}
+
+func (e scrubbedError) Unwrap() error {
+	return e.err
+}
+
+// Returns the type name of err, or of the error it was scrubbed from
+func errorTypeName(err error) string {
+	if x, ok := err.(scrubbedError); ok {
+		err = x.err
+	}
+	return fmt.Sprintf("%T", err)
+}
+
+// Reports whether the digits in b pass the Luhn checksum
+func luhn(b []byte) bool {
+	sum, n := 0, 0
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < '0' || b[i] > '9' {
+			continue
+		}
+		d := int(b[i] - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return sum%10 == 0
+}
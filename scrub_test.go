@@ -0,0 +1,55 @@
+package alog
+
+import (
+	"errors"
+	"os"
+	"regexp"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestScrubbers(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	c.Assert(log.SetScrubbers(ScrubEmails, ScrubCardNumbers, ScrubBearerTokens), check.Equals, log)
+
+	log.Set("user", "alice@example.com")
+	log.Printf("paid with 4111 1111 1111 1111, order 1234567890123, header Authorization: Bearer abc.def-123==")
+	c.Assert(t.last(), check.Equals,
+		"[user=[EMAIL]] paid with [CARD], order 1234567890123, header Authorization: Bearer [TOKEN]\n")
+
+	// Copies keep the scrubbers, and formatted entries are scrubbed too
+	log.SetFormatter(JSONFormatter{})
+	log.With("err", errors.New("no card 4111-1111-1111-1111")).Print("bob@example.org")
+	c.Assert(t.last(), check.Equals, `{"level":"info","msg":"[EMAIL]","user":"[EMAIL]","err":"no card [CARD]"}`+"\n")
+	log.SetFormatter(nil)
+
+	// Custom scrubbers
+	log.SetScrubbers(Scrubber{Pattern: regexp.MustCompile(`(key=)\w+`), Replacement: "${1}***"})
+	log.Print("key=secret")
+	c.Assert(t.last(), check.Equals, "[user=alice@example.com] key=***\n")
+
+	log.SetScrubbers()
+	log.Print("key=secret")
+	c.Assert(t.last(), check.Equals, "[user=alice@example.com] key=secret\n")
+}
+
+func (s *Suite) TestLuhn(c *check.C) {
+	c.Assert(luhn([]byte("4111 1111 1111 1111")), check.Equals, true)
+	c.Assert(luhn([]byte("79927398713")), check.Equals, true)
+	c.Assert(luhn([]byte("79927398710")), check.Equals, false)
+}
+
+func (s *Suite) TestScrubKeepsErrors(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.SetScrubbers(ScrubEmails)
+	log.SetFormatter(ECSFormatter{})
+
+	err := &os.PathError{Op: "open", Path: "bob@example.org", Err: os.ErrNotExist}
+	log.WithError(err).Print("failed")
+	c.Assert(t.last(), check.Equals,
+		`{"log.level":"info","message":"failed","ecs.version":"8.11.0","error":{"message":"open [EMAIL]: file does not exist","type":"*fs.PathError"}}`+"\n")
+}
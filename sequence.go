@@ -0,0 +1,31 @@
+package alog
+
+import "sync/atomic"
+
+// Includes a seq=N field in every entry written, numbered from 1, so
+// entries with the same timestamp can still be put in order after
+// aggregation.  The counter is shared with copies made afterwards.
+// Numbers are taken as entries are formatted, so concurrent entries may
+// reach the writer slightly out of order.  Disabling it only affects this
+// logger.
+func (a *Log) SetSequence(enabled bool) *Log {
+	if a == nil {
+		return nil
+	}
+	if !enabled {
+		a.seq = nil
+	} else if a.seq == nil {
+		a.seq = new(uint64)
+	}
+	return a
+}
+
+// Returns m with the next sequence number added, if enabled
+func (a *Log) sequenced(m message) message {
+	if a.seq == nil {
+		return m
+	}
+	n := len(m.fields)
+	m.fields = append(m.fields[:n:n], Field{"seq", atomic.AddUint64(a.seq, 1)})
+	return m
+}
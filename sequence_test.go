@@ -0,0 +1,55 @@
+package alog
+
+import (
+	"fmt"
+	"sync"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestSequence(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	c.Assert(log.SetSequence(true), check.Equals, log)
+
+	log.Print("a")
+	c.Assert(t.last(), check.Equals, "[seq=1] a\n")
+	log.With("k", "v").Print("b")
+	c.Assert(t.last(), check.Equals, "[k=v seq=2] b\n")
+
+	// Disabled entries don't use a number
+	log.SetLevel(InfoLevel)
+	log.Debug("skipped")
+	log.Print("c")
+	c.Assert(t.last(), check.Equals, "[seq=3] c\n")
+
+	// Formatted entries
+	log.SetFormatter(JSONFormatter{})
+	log.Print("d")
+	c.Assert(t.last(), check.Equals, `{"level":"info","msg":"d","seq":4}`+"\n")
+	log.SetFormatter(nil)
+
+	log.SetSequence(false)
+	log.Print("e")
+	c.Assert(t.last(), check.Equals, "e\n")
+
+	// Unique across goroutines
+	log.SetSequence(true)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Print("x")
+		}()
+	}
+	wg.Wait()
+	seen := map[string]bool{}
+	for _, m := range t.msgs[len(t.msgs)-10:] {
+		seen[m] = true
+	}
+	for i := 1; i <= 10; i++ {
+		c.Assert(seen[fmt.Sprintf("[seq=%d] x\n", i)], check.Equals, true)
+	}
+}
@@ -0,0 +1,73 @@
+package alog
+
+// Saved state of a logger's Meta, from Log.Snapshot
+type MetaSnapshot struct {
+	meta *Meta
+}
+
+// Saves the logger's fields, so that temporary changes to a shared logger
+// can be rolled back with Restore.  Only the Meta is saved, not settings
+// such as the level.  Neither Snapshot nor Restore copies the fields: the
+// logger and the snapshot share them until the logger next changes them.
+func (a *Log) Snapshot() MetaSnapshot {
+	if a == nil {
+		return MetaSnapshot{}
+	}
+	return MetaSnapshot{a.Meta.snapshot()}
+}
+
+// Reverts the logger's fields to those saved by Snapshot.  A snapshot can
+// be restored more than once, and to other loggers, where it is measured
+// and trimmed against that logger's SetMetaLimit.
+func (a *Log) Restore(s MetaSnapshot) *Log {
+	if a == nil || s.meta == nil {
+		return a
+	}
+	a.Meta.restore(s.meta)
+	return a
+}
+
+// Returns a Meta sharing m's entries.  It is never changed, so it can be
+// read without its lock.
+func (m *Meta) snapshot() *Meta {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.shared = true
+	return &Meta{
+		entries: m.entries,
+		keys:    m.keys,
+		limit:   m.limit,
+		size:    m.size,
+		trimmed: m.trimmed,
+		shared:  true,
+	}
+}
+
+func (m *Meta) restore(from *Meta) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.entries = from.entries
+	m.keys = from.keys
+	m.size = from.size
+	m.trimmed = from.trimmed
+	m.shared = true
+	// Sizes were measured against the snapshot's limit
+	if m.limit != from.limit {
+		m.applyLimit()
+	}
+}
+
+// Gives m its own copy of entries and keys if a snapshot shares them.
+// Called with the lock held, before changing either.
+func (m *Meta) own() {
+	if !m.shared {
+		return
+	}
+	entries := make(map[string]MetaEntry, len(m.entries))
+	for k, v := range m.entries {
+		entries[k] = v
+	}
+	m.entries = entries
+	m.keys = append([]string(nil), m.keys...)
+	m.shared = false
+}
@@ -0,0 +1,52 @@
+package alog
+
+import "gopkg.in/check.v1"
+
+func (s *Suite) TestSnapshot(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("a", 1).Set("b", 2)
+
+	snap := log.Snapshot()
+	log.Set("a", 3).Set("c", 4)
+	log.Print("x")
+	checkLast(c, t, "[a=3 b=2 c=4] x")
+
+	log.Restore(snap).Print("x")
+	checkLast(c, t, "[a=1 b=2] x")
+
+	// Restorable again after further changes
+	log.Set("d", 5)
+	log.Restore(snap).Set("e", 6).Print("x")
+	checkLast(c, t, "[a=1 b=2 e=6] x")
+
+	// A zero snapshot does nothing
+	log.Restore(MetaSnapshot{}).Print("x")
+	checkLast(c, t, "[a=1 b=2 e=6] x")
+
+	// Loggers restored from one snapshot don't share later changes
+	other := New(t)
+	other.SetFlags(0)
+	other.Restore(snap).Set("f", 7)
+	log.Restore(snap).Set("g", 8)
+	other.Print("x")
+	checkLast(c, t, "[a=1 b=2 f=7] x")
+	log.Print("x")
+	checkLast(c, t, "[a=1 b=2 g=8] x")
+	other.Restore(snap).Print("x")
+	checkLast(c, t, "[a=1 b=2] x")
+
+	// Restored entries are measured against the logger's own limit.  Each
+	// k=v takes 4 bytes with its separator.
+	limited := New(t)
+	limited.SetFlags(0)
+	limited.SetMetaLimit(0, 9, EvictOldest)
+	limited.Restore(log.Snapshot()).Print("x")
+	checkLast(c, t, "[b=2 g=8 meta_trimmed=1] x")
+	limited.Set("h", 9).Print("x")
+	checkLast(c, t, "[g=8 h=9 meta_trimmed=2] x")
+
+	var nilLog *Log
+	c.Assert(nilLog.Restore(nilLog.Snapshot()), check.IsNil)
+}
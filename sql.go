@@ -0,0 +1,405 @@
+package alog
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Settings for SQLDriver and SQLConnector
+type SQLOptions struct {
+	// Level of successful queries, InfoLevel by default.  Failed queries
+	// are logged at ErrorLevel.
+	Level Level
+	// Queries taking at least this long are logged at WarnLevel with
+	// slow=true.  0 to disable.
+	SlowThreshold time.Duration
+	// Names of named arguments, as in sql.Named("password", p), whose
+	// values are logged as [REDACTED].  Case insensitive.
+	Redact []string
+	// Reports whether an argument's value should be logged as [REDACTED],
+	// in addition to Redact.  Always returning true hides all arguments.
+	RedactArg func(query string, arg driver.NamedValue) bool
+}
+
+// Wraps a database/sql driver, logging each query with the fields query,
+// args, rows, duration and error.  rows is the number of rows affected by
+// an Exec, or read from a Query, whose entry is written when its rows are
+// closed.  The context's logger is used if it has one.
+//
+//	sql.Register("logged-postgres", log.SQLDriver(&pq.Driver{}, alog.SQLOptions{}))
+func (a *Log) SQLDriver(d driver.Driver, opts SQLOptions) driver.Driver {
+	return &sqlDriver{d, newSQLLogger(a, opts)}
+}
+
+// Like SQLDriver, for use with sql.OpenDB:
+//
+//	db := sql.OpenDB(log.SQLConnector(connector, alog.SQLOptions{}))
+func (a *Log) SQLConnector(c driver.Connector, opts SQLOptions) driver.Connector {
+	return &sqlConnector{c, newSQLLogger(a, opts)}
+}
+
+type sqlLogger struct {
+	log    *Log
+	opts   SQLOptions
+	redact map[string]bool
+}
+
+func newSQLLogger(a *Log, opts SQLOptions) *sqlLogger {
+	redact := make(map[string]bool, len(opts.Redact))
+	for _, name := range opts.Redact {
+		redact[strings.ToLower(name)] = true
+	}
+	return &sqlLogger{a, opts, redact}
+}
+
+// Logs a query.  rows is negative if unknown.
+func (l *sqlLogger) logQuery(ctx context.Context, query string, args []driver.NamedValue, start time.Time, rows int64, err error) {
+	if err == driver.ErrSkip {
+		return
+	}
+	log := FromContext(ctx)
+	if log == nil {
+		log = l.log
+	}
+
+	elapsed := time.Since(start)
+	level := l.opts.Level
+	slow := l.opts.SlowThreshold > 0 && elapsed >= l.opts.SlowThreshold
+	if slow && level < WarnLevel {
+		level = WarnLevel
+	}
+	if err != nil {
+		level = ErrorLevel
+	}
+	if !log.enabled(level) {
+		return
+	}
+
+	fields := []Field{{"query", query}}
+	if len(args) > 0 {
+		fields = append(fields, Field{"args", l.args(query, args)})
+	}
+	if rows >= 0 {
+		fields = append(fields, Field{"rows", rows})
+	}
+	fields = append(fields, Field{"duration", elapsed})
+	if slow {
+		fields = append(fields, Field{"slow", true})
+	}
+	if err != nil {
+		fields = append(fields, Field{"error", err})
+	}
+	log.output(level, message{fmtString, "sql query", nil, fields})
+}
+
+// Returns the arguments keyed by name or position, from 1
+func (l *sqlLogger) args(query string, args []driver.NamedValue) GroupValue {
+	g := make(GroupValue, len(args))
+	for i, arg := range args {
+		k := arg.Name
+		if k == "" {
+			k = strconv.Itoa(arg.Ordinal)
+		}
+		v := arg.Value
+		if l.redact[strings.ToLower(arg.Name)] || (l.opts.RedactArg != nil && l.opts.RedactArg(query, arg)) {
+			v = "[REDACTED]"
+		} else if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		g[i] = Field{k, v}
+	}
+	return g
+}
+
+type sqlDriver struct {
+	d driver.Driver
+	l *sqlLogger
+}
+
+func (d *sqlDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.d.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlConn{c, d.l}, nil
+}
+
+type sqlConnector struct {
+	c driver.Connector
+	l *sqlLogger
+}
+
+func (c *sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.c.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlConn{conn, c.l}, nil
+}
+
+func (c *sqlConnector) Driver() driver.Driver {
+	return &sqlDriver{c.c.Driver(), c.l}
+}
+
+// Connection passing the driver's optional interfaces through.  The logged
+// methods return driver.ErrSkip when the driver lacks them, so database/sql
+// falls back to prepared statements.
+type sqlConn struct {
+	c driver.Conn
+	l *sqlLogger
+}
+
+func (c *sqlConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *sqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var s driver.Stmt
+	var err error
+	if cp, ok := c.c.(driver.ConnPrepareContext); ok {
+		s, err = cp.PrepareContext(ctx, query)
+	} else {
+		s, err = c.c.Prepare(query)
+	}
+	if err != nil {
+		c.l.logQuery(ctx, query, nil, time.Now(), -1, err)
+		return nil, err
+	}
+	return &sqlStmt{s, query, c.c, c.l}, nil
+}
+
+func (c *sqlConn) Close() error {
+	return c.c.Close()
+}
+
+func (c *sqlConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+var errSQLTxOptions = errors.New("alog: driver does not support transaction options")
+
+func (c *sqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if cb, ok := c.c.(driver.ConnBeginTx); ok {
+		return cb.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != 0 || opts.ReadOnly {
+		return nil, errSQLTxOptions
+	}
+	return c.c.Begin()
+}
+
+func (c *sqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.c.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	res, err := ec.ExecContext(ctx, query, args)
+	c.l.logQuery(ctx, query, args, start, rowsAffected(res, err), err)
+	return res, err
+}
+
+func (c *sqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.c.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := qc.QueryContext(ctx, query, args)
+	if err != nil {
+		c.l.logQuery(ctx, query, args, start, -1, err)
+		return nil, err
+	}
+	return &sqlRows{rows: rows, ctx: ctx, query: query, args: args, start: start, l: c.l}, nil
+}
+
+func (c *sqlConn) Ping(ctx context.Context) error {
+	if p, ok := c.c.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *sqlConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.c.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *sqlConn) IsValid() bool {
+	if v, ok := c.c.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *sqlConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.c.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type sqlStmt struct {
+	s     driver.Stmt
+	query string
+	conn  driver.Conn
+	l     *sqlLogger
+}
+
+func (s *sqlStmt) Close() error {
+	return s.s.Close()
+}
+
+func (s *sqlStmt) NumInput() int {
+	return s.s.NumInput()
+}
+
+func (s *sqlStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *sqlStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *sqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var res driver.Result
+	var err error
+	if ec, ok := s.s.(driver.StmtExecContext); ok {
+		res, err = ec.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = plainValues(args); err == nil {
+			res, err = s.s.Exec(values)
+		}
+	}
+	s.l.logQuery(ctx, s.query, args, start, rowsAffected(res, err), err)
+	return res, err
+}
+
+func (s *sqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if qc, ok := s.s.(driver.StmtQueryContext); ok {
+		rows, err = qc.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = plainValues(args); err == nil {
+			rows, err = s.s.Query(values)
+		}
+	}
+	if err != nil {
+		s.l.logQuery(ctx, s.query, args, start, -1, err)
+		return nil, err
+	}
+	return &sqlRows{rows: rows, ctx: ctx, query: s.query, args: args, start: start, l: s.l}, nil
+}
+
+// Checks with the statement or else the connection, as database/sql would
+func (s *sqlStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := s.s.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	if nc, ok := s.conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func (s *sqlStmt) ColumnConverter(idx int) driver.ValueConverter {
+	if cc, ok := s.s.(driver.ColumnConverter); ok {
+		return cc.ColumnConverter(idx)
+	}
+	return driver.DefaultParameterConverter
+}
+
+// Rows counting what is read, and logging the query when closed
+type sqlRows struct {
+	rows   driver.Rows
+	ctx    context.Context
+	query  string
+	args   []driver.NamedValue
+	start  time.Time
+	n      int64
+	err    error
+	closed bool
+	l      *sqlLogger
+}
+
+func (r *sqlRows) Columns() []string {
+	return r.rows.Columns()
+}
+
+func (r *sqlRows) Next(dest []driver.Value) error {
+	err := r.rows.Next(dest)
+	if err == nil {
+		r.n++
+	} else if err != io.EOF {
+		r.err = err
+	}
+	return err
+}
+
+func (r *sqlRows) HasNextResultSet() bool {
+	if rs, ok := r.rows.(driver.RowsNextResultSet); ok {
+		return rs.HasNextResultSet()
+	}
+	return false
+}
+
+func (r *sqlRows) NextResultSet() error {
+	if rs, ok := r.rows.(driver.RowsNextResultSet); ok {
+		return rs.NextResultSet()
+	}
+	return io.EOF
+}
+
+func (r *sqlRows) Close() error {
+	err := r.rows.Close()
+	if !r.closed {
+		r.closed = true
+		r.l.logQuery(r.ctx, r.query, r.args, r.start, r.n, r.err)
+	}
+	return err
+}
+
+func rowsAffected(res driver.Result, err error) int64 {
+	if err != nil || res == nil {
+		return -1
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	nv := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		nv[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return nv
+}
+
+var errSQLNamedArgs = errors.New("alog: driver does not support named arguments")
+
+func plainValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errSQLNamedArgs
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
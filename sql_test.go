@@ -0,0 +1,188 @@
+package alog
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+// Driver with only the required methods.  Queries named "fail" fail, and
+// queries return two rows.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	if name == "ctx" {
+		return fakeCtxConn{}, nil
+	}
+	return fakeConn{}, nil
+}
+
+type fakeConnector struct {
+	name string
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return fakeDriver{}.Open(c.name)
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) {
+	if query == "bad" {
+		return nil, errors.New("syntax error")
+	}
+	return fakeStmt{query}, nil
+}
+
+func (fakeConn) Close() error {
+	return nil
+}
+
+func (fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("unsupported")
+}
+
+// Connection with the fast paths for unprepared queries
+type fakeCtxConn struct {
+	fakeConn
+}
+
+func (fakeCtxConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return fakeStmt{query}.Exec(nil)
+}
+
+func (fakeCtxConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return fakeStmt{query}.Query(nil)
+}
+
+type fakeStmt struct {
+	query string
+}
+
+func (fakeStmt) Close() error {
+	return nil
+}
+
+func (fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.query == "fail" {
+		return nil, errors.New("failed")
+	}
+	return driver.RowsAffected(3), nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if s.query == "fail" {
+		return nil, errors.New("failed")
+	}
+	return &fakeRows{n: 2}, nil
+}
+
+type fakeRows struct {
+	n int
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"x"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.n == 0 {
+		return io.EOF
+	}
+	r.n--
+	dest[0] = int64(r.n)
+	return nil
+}
+
+func (s *Suite) TestSQL(c *check.C) {
+	for _, name := range []string{"", "ctx"} {
+		t := &Thief{}
+		log := New(t)
+		log.SetFlags(0)
+		db := sql.OpenDB(log.SQLConnector(fakeConnector{name}, SQLOptions{Redact: []string{"Password"}}))
+
+		_, err := db.Exec("insert", 1, "alice", []byte("b"))
+		c.Assert(err, check.IsNil)
+		c.Assert(t.last(), check.Matches, `\[query=insert args\.1=1 args\.2=alice args\.3=b rows=3 duration=.*\] sql query\n`)
+
+		// Only drivers with ExecerContext take named arguments
+		_, err = db.Exec("insert", sql.Named("password", "secret"))
+		if name == "ctx" {
+			c.Assert(err, check.IsNil)
+			c.Assert(t.last(), check.Matches, `\[query=insert args\.password=\[REDACTED\] rows=3 .*\n`)
+		} else {
+			c.Assert(err, check.NotNil)
+		}
+
+		rows, err := db.Query("select")
+		c.Assert(err, check.IsNil)
+		n := len(t.msgs)
+		for rows.Next() {
+		}
+		c.Assert(rows.Close(), check.IsNil)
+		c.Assert(t.msgs, check.HasLen, n+1)
+		c.Assert(t.last(), check.Matches, `\[query=select rows=2 duration=.*\] sql query\n`)
+
+		// Both rows are scanned
+		var x int
+		c.Assert(db.QueryRow("select").Scan(&x), check.IsNil)
+		c.Assert(x, check.Equals, 1)
+
+		_, err = db.Exec("fail")
+		c.Assert(err, check.NotNil)
+		c.Assert(t.last(), check.Matches, `ERROR \[query=fail duration=.* error=failed\] sql query\n`)
+		_, err = db.Query("fail", 1)
+		c.Assert(err, check.NotNil)
+		c.Assert(t.last(), check.Matches, `ERROR \[query=fail args\.1=1 duration=.* error=failed\] sql query\n`)
+
+		// The context's logger
+		ctxLog := log.With("request_id", "r1")
+		_, err = db.ExecContext(NewContext(context.Background(), ctxLog), "insert")
+		c.Assert(err, check.IsNil)
+		c.Assert(t.last(), check.Matches, `\[request_id=r1 query=insert rows=3 .*\n`)
+		c.Assert(db.Close(), check.IsNil)
+	}
+
+	// Prepare failures, slow queries and RedactArg
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	db := sql.OpenDB(log.SQLConnector(fakeConnector{}, SQLOptions{
+		Level:         DebugLevel,
+		SlowThreshold: time.Nanosecond,
+		RedactArg: func(query string, arg driver.NamedValue) bool {
+			return arg.Ordinal == 2
+		},
+	}))
+	defer db.Close()
+	_, err := db.Exec("bad")
+	c.Assert(err, check.NotNil)
+	c.Assert(t.last(), check.Matches, `ERROR \[query=bad duration=.* error=syntax error\] sql query\n`)
+	_, err = db.Exec("insert", 1, 2)
+	c.Assert(err, check.IsNil)
+	c.Assert(t.last(), check.Matches, `WARN \[query=insert args\.1=1 args\.2=\[REDACTED\] rows=3 duration=.* slow=true\] sql query\n`)
+
+	// As a registered driver
+	conn, err := log.SQLDriver(fakeDriver{}, SQLOptions{}).Open("ctx")
+	c.Assert(err, check.IsNil)
+	_, err = conn.(driver.ExecerContext).ExecContext(context.Background(), "insert", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(t.last(), check.Matches, `\[query=insert rows=3 .*\n`)
+}
@@ -0,0 +1,47 @@
+package alog
+
+import (
+	"runtime"
+	"strconv"
+)
+
+// Disables stack capture, the default
+const noStackLevel = FatalLevel + 1
+
+// Captures the caller's stack for entries at or above level, e.g.
+// ErrorLevel.  Text entries have it after the message, one function and
+// file:line pair per frame, and formatters get it as a "stack" field.
+// Frames are trimmed as caller paths are under Llongfile.  Levels above
+// FatalLevel disable it, the default.  Copies keep the setting.
+func (a *Log) SetStackLevel(level Level) *Log {
+	if a == nil {
+		return nil
+	}
+	a.stackLevel = level
+	return a
+}
+
+// Formats the stack from the frame calldepth counts to, as for write
+func (a *Log) callerStack(calldepth int) string {
+	var pcs [64]uintptr
+	// Skip runtime.Callers and this function
+	n := runtime.Callers(calldepth+2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b []byte
+	for {
+		f, more := frames.Next()
+		if len(b) > 0 {
+			b = append(b, '\n')
+		}
+		b = append(b, f.Function...)
+		b = append(b, "\n\t"...)
+		b = append(b, a.paths.trim(f.File)...)
+		b = append(b, ':')
+		b = strconv.AppendInt(b, int64(f.Line), 10)
+		if !more {
+			break
+		}
+	}
+	return string(b)
+}
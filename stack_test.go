@@ -0,0 +1,35 @@
+package alog
+
+import (
+	"strings"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestStackLevel(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+
+	log.Error("failed")
+	checkLast(c, t, "ERROR failed")
+
+	log.SetStackLevel(ErrorLevel)
+	log.Warn("slow")
+	checkLast(c, t, "WARN slow")
+	log.Error("failed")
+	lines := strings.Split(t.last(), "\n")
+	c.Assert(lines[0], check.Equals, "ERROR failed")
+	c.Assert(lines[1], check.Equals, "github.com/xsleonard/alog.(*Suite).TestStackLevel")
+	c.Assert(lines[2], check.Matches, `\t.*/stack_test\.go:\d+`)
+
+	// Copies keep it, and formatters get a field
+	log.SetFormatter(ECSFormatter{})
+	log.With("k", "v").Errorf("failed %d", 2)
+	c.Assert(t.last(), check.Matches, `.*"error":\{"stack_trace":"github.com/xsleonard/alog\.\(\*Suite\)\.TestStackLevel\\n\\t.*/stack_test\.go:\d+\\n.*\n`)
+
+	log.SetFormatter(nil)
+	log.SetStackLevel(FatalLevel + 1)
+	log.Error("failed")
+	checkLast(c, t, "ERROR failed")
+}
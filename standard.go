@@ -0,0 +1,65 @@
+package alog
+
+import (
+	"os"
+	"path/filepath"
+	"runtime/debug"
+)
+
+// Sets the host, pid, app and env fields that identify a process.
+// app defaults to the executable's name.  env is omitted if empty.
+func (a *Log) SetStandardFields(app, env string) *Log {
+	if a == nil {
+		return nil
+	}
+
+	if host, err := os.Hostname(); err == nil {
+		a.Set("host", host)
+	}
+	a.Set("pid", os.Getpid())
+	if app == "" {
+		app = filepath.Base(os.Args[0])
+	}
+	a.Set("app", app)
+	if env != "" {
+		a.Set("env", env)
+	}
+	return a
+}
+
+// Shorthand for .Copy().SetStandardFields(app, env)
+func (a *Log) WithStandardFields(app, env string) *Log {
+	return a.Copy().SetStandardFields(app, env)
+}
+
+// Sets version, commit and dirty fields from the binary's build info, so
+// entries can be traced to the build that emitted them.  commit and dirty
+// are only available when built from a VCS checkout.
+func (a *Log) SetBuildInfo() *Log {
+	if a == nil {
+		return nil
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		a.setBuildInfo(bi)
+	}
+	return a
+}
+
+// Shorthand for .Copy().SetBuildInfo()
+func (a *Log) WithBuildInfo() *Log {
+	return a.Copy().SetBuildInfo()
+}
+
+func (a *Log) setBuildInfo(bi *debug.BuildInfo) {
+	if bi.Main.Version != "" {
+		a.Set("version", bi.Main.Version)
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			a.Set("commit", s.Value)
+		case "vcs.modified":
+			a.Set("dirty", s.Value == "true")
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package alog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestStandardFields(c *check.C) {
+	host, err := os.Hostname()
+	c.Assert(err, check.IsNil)
+
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+
+	log2 := log.WithStandardFields("", "prod")
+	log2.Print("test")
+	checkLast(c, t, fmt.Sprintf("[host=%s pid=%d app=%s env=prod] test",
+		host, os.Getpid(), filepath.Base(os.Args[0])))
+
+	// Original is unchanged
+	log.Print("test")
+	checkLast(c, t, "test")
+
+	log.SetStandardFields("api", "")
+	log.Print("test")
+	checkLast(c, t, fmt.Sprintf("[host=%s pid=%d app=api] test", host, os.Getpid()))
+
+	var nilLog *Log
+	c.Assert(nilLog.SetStandardFields("", ""), check.IsNil)
+	c.Assert(nilLog.WithStandardFields("", ""), check.IsNil)
+}
+
+func (s *Suite) TestBuildInfo(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+
+	log.setBuildInfo(&debug.BuildInfo{
+		Main: debug.Module{Version: "v1.2.3"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "abc123"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	})
+	log.Print("test")
+	checkLast(c, t, "[version=v1.2.3 commit=abc123 dirty=true] test")
+
+	// Doesn't modify the original
+	log = New(t)
+	log.SetFlags(0)
+	log.WithBuildInfo()
+	log.Print("test")
+	checkLast(c, t, "test")
+
+	var nilLog *Log
+	c.Assert(nilLog.SetBuildInfo(), check.IsNil)
+	c.Assert(nilLog.WithBuildInfo(), check.IsNil)
+}
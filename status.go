@@ -0,0 +1,165 @@
+package alog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// State of one sink in a logger's pipeline
+type SinkStatus struct {
+	// Describes the sink, e.g. "file /var/log/app.log" or "tcp host:514"
+	Name string
+	// False while the sink can't reach its destination, e.g. a network
+	// writer whose last dial failed
+	Connected bool
+	// Whether the most recent write or send failed
+	Failing bool
+	// The most recent failure, kept after the sink recovers
+	LastError     error
+	LastErrorTime time.Time
+	// Entries waiting to be written, for queueing and batching writers
+	Queued int
+	// Entries discarded, e.g. because a queue was full or a batch couldn't
+	// be sent
+	Dropped uint64
+}
+
+func (s SinkStatus) Healthy() bool {
+	return s.Connected && !s.Failing
+}
+
+// Implemented by writers that report their health.  Writers wrapping others
+// include the wrapped writers' statuses after their own.
+type StatusReporter interface {
+	Status() []SinkStatus
+}
+
+// Reports the state of each sink the logger writes to.  Writers that don't
+// implement StatusReporter are reported as connected.
+func (a *Log) Status() []SinkStatus {
+	if a == nil {
+		return nil
+	}
+	return writerStatus(a.Logger.Writer())
+}
+
+// Reports whether every sink is connected and its last write succeeded,
+// for readiness checks
+func (a *Log) Healthy() bool {
+	for _, s := range a.Status() {
+		if !s.Healthy() {
+			return false
+		}
+	}
+	return true
+}
+
+func writerStatus(w io.Writer) []SinkStatus {
+	switch w {
+	case os.Stdout:
+		return []SinkStatus{{Name: "stdout", Connected: true}}
+	case os.Stderr:
+		return []SinkStatus{{Name: "stderr", Connected: true}}
+	}
+	if r, ok := w.(StatusReporter); ok {
+		return r.Status()
+	}
+	return []SinkStatus{{Name: fmt.Sprintf("%T", w), Connected: true}}
+}
+
+// Records the outcome of a sink's writes
+type sinkHealth struct {
+	failing bool
+	err     error
+	errTime time.Time
+	mutex   sync.Mutex
+}
+
+// Records err, if any, or a success, and returns err
+func (h *sinkHealth) record(err error) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.failing = err != nil
+	if err != nil {
+		h.err = err
+		h.errTime = time.Now()
+	}
+	return err
+}
+
+func (h *sinkHealth) status(name string) SinkStatus {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return SinkStatus{
+		Name:          name,
+		Connected:     true,
+		Failing:       h.failing,
+		LastError:     h.err,
+		LastErrorTime: h.errTime,
+	}
+}
+
+// Implemented by writers that can fail in the background, after Write has
+// returned, such as AsyncWriter and the batching writers.  Log's
+// SetErrorHandler passes its handler on to its writer when it is one; a
+// writer wrapped in another must be given the handler directly.
+type ErrorReporter interface {
+	SetErrorHandler(h func(error))
+}
+
+// Passes a writer's background failures to a handler on a goroutine of its
+// own, so that a handler logging through the same writer can't stall it.
+// Errors arriving while the handler is behind are dropped; they are still
+// counted in the writer's status.
+type backgroundErrors struct {
+	h      func(error)
+	errs   chan error
+	closed bool
+	mutex  sync.Mutex
+}
+
+func (b *backgroundErrors) set(h func(error)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.h = h
+	if b.errs == nil && !b.closed {
+		b.errs = make(chan error, 16)
+		go b.run(b.errs)
+	}
+}
+
+func (b *backgroundErrors) run(errs chan error) {
+	for err := range errs {
+		b.mutex.Lock()
+		h := b.h
+		b.mutex.Unlock()
+		if h != nil {
+			h(err)
+		}
+	}
+}
+
+func (b *backgroundErrors) report(err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.errs == nil || b.closed {
+		return
+	}
+	select {
+	case b.errs <- err:
+	default:
+	}
+}
+
+// Stops the handler's goroutine once it has caught up
+func (b *backgroundErrors) close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.errs != nil && !b.closed {
+		close(b.errs)
+	}
+	b.closed = true
+}
@@ -0,0 +1,92 @@
+package alog
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestStatus(c *check.C) {
+	// Writers without a status are reported as connected
+	log := New(&Thief{})
+	c.Assert(log.Status(), check.DeepEquals, []SinkStatus{{Name: "*alog.Thief", Connected: true}})
+	c.Assert(log.Healthy(), check.Equals, true)
+	c.Assert(New(os.Stderr).Status()[0].Name, check.Equals, "stderr")
+
+	var nilLog *Log
+	c.Assert(nilLog.Status(), check.IsNil)
+	c.Assert(nilLog.Healthy(), check.Equals, true)
+
+	// Failed batches are dropped and reported
+	fail := errors.New("fail")
+	var sendErr error
+	b := NewBatchFunc(func([][]byte) error { return sendErr }, BatchConfig{MaxDelay: -1})
+	log = New(b)
+	log.Print("a")
+	log.Print("b")
+	st := log.Status()
+	c.Assert(st, check.HasLen, 1)
+	c.Assert(st[0].Name, check.Equals, "batch")
+	c.Assert(st[0].Queued, check.Equals, 2)
+
+	sendErr = fail
+	c.Assert(log.Flush(), check.Equals, fail)
+	st = log.Status()
+	c.Assert(st[0].Failing, check.Equals, true)
+	c.Assert(st[0].LastError, check.Equals, fail)
+	c.Assert(st[0].LastErrorTime.IsZero(), check.Equals, false)
+	c.Assert(st[0].Dropped, check.Equals, uint64(2))
+	c.Assert(log.Healthy(), check.Equals, false)
+
+	// Recovers, keeping the last error
+	sendErr = nil
+	log.Print("c")
+	c.Assert(log.Flush(), check.IsNil)
+	st = log.Status()
+	c.Assert(st[0].Failing, check.Equals, false)
+	c.Assert(st[0].LastError, check.Equals, fail)
+	c.Assert(log.Healthy(), check.Equals, true)
+	b.Close()
+
+	// Wrappers include the wrapped writers
+	path := filepath.Join(c.MkDir(), "app.log")
+	fw, err := OpenFile(path)
+	c.Assert(err, check.IsNil)
+	a := NewAsyncWriter(fw, 10, Block)
+	log = New(a)
+	log.Print("d")
+	c.Assert(a.Flush(), check.IsNil)
+	st = log.Status()
+	c.Assert(st, check.HasLen, 2)
+	c.Assert(st[0].Name, check.Equals, "async")
+	c.Assert(st[0].Connected, check.Equals, true)
+	c.Assert(st[1].Name, check.Equals, "file "+path)
+	c.Assert(log.Healthy(), check.Equals, true)
+	c.Assert(a.Close(), check.IsNil)
+	c.Assert(log.Status()[0].Connected, check.Equals, false)
+}
+
+func (s *Suite) TestNetWriterStatus(c *check.C) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	addr := l.Addr().String()
+	l.Close()
+
+	w := NewNetWriter("tcp", addr, NetOptions{})
+	fb := NewFallbackWriter(w, &Thief{}, 0)
+	log := New(fb)
+	log.Print("lost")
+
+	st := log.Status()
+	c.Assert(st, check.HasLen, 3)
+	c.Assert(st[0], check.DeepEquals, SinkStatus{Name: "fallback", Connected: true, Failing: true})
+	c.Assert(st[1].Name, check.Equals, "tcp "+addr)
+	c.Assert(st[1].Connected, check.Equals, false)
+	c.Assert(st[1].Failing, check.Equals, true)
+	c.Assert(st[1].LastError, check.NotNil)
+	c.Assert(st[2].Name, check.Equals, "*alog.Thief")
+	c.Assert(log.Healthy(), check.Equals, false)
+}
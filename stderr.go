@@ -0,0 +1,96 @@
+package alog
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+var stderrCapture struct {
+	active bool
+	mutex  sync.Mutex
+}
+
+var errStderrCaptured = errors.New("alog: stderr is already captured")
+
+// Replaces the process's stderr with a pipe whose lines are logged at level
+// with source=stderr, so stray prints, including those of C libraries, and
+// panics in other goroutines reach the logger.  On systems other than
+// Linux, macOS and the BSDs only os.Stderr is replaced.  A fatal runtime
+// error exits the process before it can be logged, so from Go 1.23 it is
+// also written to the original stderr.  If the logger writes to stderr,
+// even through wrappers that report it in their status, such as Build's
+// logger, AsyncWriter and FallbackWriter, the captured lines go only to the
+// original stderr, since logging them would capture them again.  Other
+// wrappers around stderr must not be used.  Call restore to put stderr
+// back; it waits for the remaining lines to be logged.
+func (a *Log) CaptureStderr(level Level) (restore func(), err error) {
+	stderrCapture.mutex.Lock()
+	defer stderrCapture.mutex.Unlock()
+	if stderrCapture.active {
+		return nil, errStderrCaptured
+	}
+
+	// Checked before os.Stderr is replaced
+	toStderr := a != nil && writesToStderr(a.Logger.Writer())
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	orig, err := redirectStderr(w)
+	if err != nil {
+		r.Close()
+		w.Close()
+		return nil, err
+	}
+	old := os.Stderr
+	os.Stderr = w
+	setCrashOutput(orig)
+	stderrCapture.active = true
+
+	log := a
+	if a == nil {
+		log = New(orig)
+	} else if toStderr {
+		log = a.Copy()
+		log.Logger.SetOutput(orig)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s := bufio.NewScanner(r)
+		s.Buffer(nil, 1<<20)
+		for s.Scan() {
+			log.output(level, message{fmtString, s.Text(), nil, []Field{{"source", "stderr"}}})
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			stderrCapture.mutex.Lock()
+			defer stderrCapture.mutex.Unlock()
+			restoreStderr(orig)
+			os.Stderr = old
+			setCrashOutput(nil)
+			w.Close()
+			<-done
+			r.Close()
+			stderrCapture.active = false
+		})
+	}, nil
+}
+
+// Reports whether w is stderr or wraps it, as far as its status shows
+func writesToStderr(w io.Writer) bool {
+	for _, s := range writerStatus(w) {
+		if s.Name == "stderr" {
+			return true
+		}
+	}
+	return false
+}
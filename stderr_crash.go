@@ -0,0 +1,13 @@
+//go:build go1.23
+
+package alog
+
+import (
+	"os"
+	"runtime/debug"
+)
+
+// Also writes fatal runtime errors to f, or stops if f is nil
+func setCrashOutput(f *os.File) {
+	debug.SetCrashOutput(f, debug.CrashOptions{})
+}
@@ -0,0 +1,8 @@
+package alog
+
+import "syscall"
+
+// Linux on arm64 and newer architectures has only dup3
+func dup2(oldfd, newfd int) error {
+	return syscall.Dup3(oldfd, newfd, 0)
+}
@@ -0,0 +1,8 @@
+//go:build !go1.23
+
+package alog
+
+import "os"
+
+// Crash output can't be redirected before Go 1.23
+func setCrashOutput(f *os.File) {}
@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package alog
+
+import "os"
+
+// Only os.Stderr can be replaced, without a portable dup2
+func redirectStderr(w *os.File) (*os.File, error) {
+	return os.Stderr, nil
+}
+
+func restoreStderr(orig *os.File) {}
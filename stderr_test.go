@@ -0,0 +1,51 @@
+package alog
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestCaptureStderr(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+
+	old := os.Stderr
+	restore, err := log.CaptureStderr(WarnLevel)
+	c.Assert(err, check.IsNil)
+	c.Assert(os.Stderr, check.Not(check.Equals), old)
+	_, err = log.CaptureStderr(WarnLevel)
+	c.Assert(err, check.Equals, errStderrCaptured)
+
+	fmt.Fprint(os.Stderr, "one\ntwo\nthree")
+	restore()
+	restore()
+	c.Assert(os.Stderr, check.Equals, old)
+	c.Assert(t.msgs, check.DeepEquals, []string{
+		"WARN [source=stderr] one\n",
+		"WARN [source=stderr] two\n",
+		"WARN [source=stderr] three\n",
+	})
+
+	// Can capture again
+	restore, err = log.CaptureStderr(InfoLevel)
+	c.Assert(err, check.IsNil)
+	restore()
+}
+
+func (s *Suite) TestWritesToStderr(c *check.C) {
+	c.Assert(writesToStderr(os.Stderr), check.Equals, true)
+	c.Assert(writesToStderr(&Thief{}), check.Equals, false)
+
+	log, err := Build(Config{})
+	c.Assert(err, check.IsNil)
+	c.Assert(writesToStderr(log.Logger.Writer()), check.Equals, true)
+
+	a := NewAsyncWriter(os.Stderr, 1, Block)
+	defer a.Close()
+	c.Assert(writesToStderr(a), check.Equals, true)
+	c.Assert(writesToStderr(NewFallbackWriter(&Thief{}, os.Stderr, time.Second)), check.Equals, true)
+}
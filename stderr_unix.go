@@ -0,0 +1,27 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package alog
+
+import (
+	"os"
+	"syscall"
+)
+
+// Points file descriptor 2 at w, returning a file for the original stderr
+func redirectStderr(w *os.File) (*os.File, error) {
+	fd, err := syscall.Dup(2)
+	if err != nil {
+		return nil, err
+	}
+	syscall.CloseOnExec(fd)
+	if err := dup2(int(w.Fd()), 2); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), "/dev/stderr"), nil
+}
+
+func restoreStderr(orig *os.File) {
+	dup2(int(orig.Fd()), 2)
+	orig.Close()
+}
@@ -0,0 +1,12 @@
+//go:build !windows && !plan9
+
+package alog
+
+import (
+	"io"
+	"log/syslog"
+)
+
+func dialSyslog(c OutputConfig) (io.Writer, error) {
+	return syslog.Dial(c.Network, c.Address, syslog.LOG_INFO|syslog.LOG_USER, c.Tag)
+}
@@ -0,0 +1,12 @@
+//go:build windows || plan9
+
+package alog
+
+import (
+	"errors"
+	"io"
+)
+
+func dialSyslog(c OutputConfig) (io.Writer, error) {
+	return nil, errors.New("alog: syslog is not supported on this platform")
+}
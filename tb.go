@@ -0,0 +1,45 @@
+package alog
+
+import (
+	"log"
+	"testing"
+)
+
+// Returns a logger that writes each entry to t.Log, so output appears with
+// the test that produced it, and only when the test fails or runs with -v.
+// Flags are set to Lshortfile: the file and line t.Log adds are inside
+// alog, so each entry carries its caller instead.
+func NewTB(t testing.TB) *Log {
+	return NewTBFailing(t, FatalLevel+1)
+}
+
+// Like NewTB, but entries at level or above are written with t.Error,
+// failing the test, e.g. NewTBFailing(t, ErrorLevel).
+func NewTBFailing(t testing.TB, level Level) *Log {
+	a := New(&tbWriter{t, level})
+	a.SetFlags(log.Lshortfile)
+	return a
+}
+
+type tbWriter struct {
+	t       testing.TB
+	failing Level
+}
+
+func (w *tbWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(InfoLevel, p)
+}
+
+func (w *tbWriter) WriteLevel(level Level, p []byte) (int, error) {
+	w.t.Helper()
+	s := string(p)
+	if len(s) > 0 && s[len(s)-1] == '\n' {
+		s = s[:len(s)-1]
+	}
+	if level >= w.failing {
+		w.t.Error(s)
+	} else {
+		w.t.Log(s)
+	}
+	return len(p), nil
+}
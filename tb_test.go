@@ -0,0 +1,46 @@
+package alog
+
+import (
+	"fmt"
+	"testing"
+
+	"gopkg.in/check.v1"
+)
+
+// Records what would be passed to a testing.TB
+type fakeTB struct {
+	testing.TB
+	logs   []string
+	errors []string
+}
+
+func (t *fakeTB) Helper() {}
+
+func (t *fakeTB) Log(v ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprint(v...))
+}
+
+func (t *fakeTB) Error(v ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprint(v...))
+}
+
+func (s *Suite) TestTB(c *check.C) {
+	t := &fakeTB{}
+	log := NewTB(t).Set("foo", "bar")
+	log.Print("hello")
+	c.Assert(t.logs, check.HasLen, 1)
+	c.Assert(t.logs[0], check.Matches, `tb_test\.go:\d+: \[foo=bar\] hello`)
+
+	log.SetFlags(0)
+	log.Error("failed")
+	c.Assert(t.logs[1], check.Equals, "ERROR [foo=bar] failed")
+	c.Assert(t.errors, check.HasLen, 0)
+
+	t = &fakeTB{}
+	log = NewTBFailing(t, ErrorLevel)
+	log.SetFlags(0)
+	log.Warn("careful")
+	log.Errorf("failed: %d", 1)
+	c.Assert(t.logs, check.DeepEquals, []string{"WARN careful"})
+	c.Assert(t.errors, check.DeepEquals, []string{"ERROR failed: 1"})
+}
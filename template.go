@@ -0,0 +1,73 @@
+package alog
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+)
+
+// Formats entries with a text/template, for layouts the built in formatters
+// don't cover.  The template is executed with a TemplateEntry:
+//
+//	f, err := alog.NewTemplateFormatter(`{{.Time.Format "15:04:05"}} {{.Level}} {{.Message}} {{.Fields}}`)
+//
+// One trailing newline is removed from the output, since the logger adds
+// its own.  If the template fails, the message is written, followed by
+// !TEMPLATE and the error.
+type TemplateFormatter struct {
+	Template *template.Template
+}
+
+// What a TemplateFormatter's template is executed with
+type TemplateEntry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  TemplateFields
+	File    string
+	Line    int
+}
+
+// An entry's fields, with groups expanded into dotted keys.  Printed as
+// k=v pairs separated by spaces, as in the text prefix.
+type TemplateFields []Field
+
+// Returns the value of the field with key k, or nil if there isn't one
+func (f TemplateFields) Get(k string) interface{} {
+	for _, fl := range f {
+		if fl.Key == k {
+			return fl.Value
+		}
+	}
+	return nil
+}
+
+func (f TemplateFields) String() string {
+	return string(appendFields(nil, f, " "))
+}
+
+// Parses text as the template of a TemplateFormatter
+func NewTemplateFormatter(text string) (TemplateFormatter, error) {
+	t, err := template.New("alog").Parse(text)
+	if err != nil {
+		return TemplateFormatter{}, err
+	}
+	return TemplateFormatter{t}, nil
+}
+
+func (f TemplateFormatter) Format(b []byte, e *Entry) []byte {
+	buf := bytes.NewBuffer(b)
+	err := f.Template.Execute(buf, TemplateEntry{
+		Time:    e.Time,
+		Level:   e.Level,
+		Message: e.Message,
+		Fields:  TemplateFields(flattenFields(e.Fields)),
+		File:    e.File,
+		Line:    e.Line,
+	})
+	if err != nil {
+		return fmt.Appendf(b, "%s !TEMPLATE(%v)", e.Message, err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
@@ -0,0 +1,38 @@
+package alog
+
+import (
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestTemplateFormatter(c *check.C) {
+	f, err := NewTemplateFormatter(`{{.Time.Format "15:04:05"}} <{{.Level}}> {{.Message}} user={{.Fields.Get "user"}} {{.Fields}}` + "\n")
+	c.Assert(err, check.IsNil)
+
+	e := &Entry{
+		Time:    time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   WarnLevel,
+		Message: "slow",
+		Fields:  []Field{{"user", "alice"}, {"http", Group("status", 200)}},
+	}
+	c.Assert(string(f.Format([]byte("x"), e)), check.Equals, "x03:04:05 <warn> slow user=alice user=alice http.status=200")
+
+	// Through a logger
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	f, err = NewTemplateFormatter(`{{.Level}}: {{.Message}}{{range .Fields}} {{.Key}}:{{.Value}}{{end}}`)
+	c.Assert(err, check.IsNil)
+	log.SetFormatter(f)
+	log.Set("a", 1).Error("failed")
+	c.Assert(t.last(), check.Equals, "error: failed a:1\n")
+
+	// Execution errors keep the message
+	f, err = NewTemplateFormatter(`{{.Message}} {{.Missing}}`)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(f.Format(nil, e)), check.Matches, `slow !TEMPLATE\(.*Missing.*\)`)
+
+	_, err = NewTemplateFormatter(`{{.Message`)
+	c.Assert(err, check.NotNil)
+}
@@ -0,0 +1,34 @@
+package alog
+
+import "time"
+
+// Starts timing an operation.  Calling the returned func logs op and the
+// elapsed time as fields:
+//
+//	defer log.Timed("rebuild index")()
+//
+// logs "[op=rebuild index elapsed=1.2s] done".
+func (a *Log) Timed(op string) func() {
+	return a.TimedErr(op, nil)
+}
+
+// Like Timed, but also records the outcome from *errp, normally a named
+// error result, when the returned func is called:
+//
+//	func rebuild() (err error) {
+//		defer log.TimedErr("rebuild index", &err)()
+//
+// A non-nil error is added as error=, and the entry is written at
+// ErrorLevel with the message "failed".
+func (a *Log) TimedErr(op string, errp *error) func() {
+	start := time.Now()
+	return func() {
+		fields := []Field{{"op", op}, {"elapsed", time.Since(start)}}
+		if errp != nil && *errp != nil {
+			fields = append(fields, Field{"error", *errp})
+			a.output(ErrorLevel, message{fmtString, "failed", nil, fields})
+			return
+		}
+		a.output(InfoLevel, message{fmtString, "done", nil, fields})
+	}
+}
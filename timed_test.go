@@ -0,0 +1,41 @@
+package alog
+
+import (
+	"errors"
+	stdlog "log"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestTimed(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("app", "api")
+
+	func() {
+		defer log.Timed("rebuild index")()
+	}()
+	c.Assert(t.last(), check.Matches, `\[app=api op=rebuild index elapsed=[0-9.]+[nµm]?s\] done\n`)
+
+	fail := func() (err error) {
+		defer log.TimedErr("load", &err)()
+		return errors.New("missing")
+	}
+	fail()
+	c.Assert(t.last(), check.Matches, `ERROR \[app=api op=load elapsed=\S+ error=missing\] failed\n`)
+
+	ok := func() (err error) {
+		defer log.TimedErr("load", &err)()
+		return nil
+	}
+	ok()
+	c.Assert(t.last(), check.Matches, `\[app=api op=load elapsed=\S+\] done\n`)
+
+	// Caller is the deferring function
+	log.SetFlags(stdlog.Lshortfile)
+	func() {
+		defer log.Timed("x")()
+	}()
+	c.Assert(t.last(), check.Matches, `timed_test\.go:\d+: .*\n`)
+}
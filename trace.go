@@ -0,0 +1,77 @@
+package alog
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Returns a copy of the logger that keeps the last n entries below its
+// level in memory instead of discarding them.  They are written, in order,
+// before the next Error or Panic entry, and dropped if none comes, so a
+// request-scoped logger shows its debug trail only when the request fails.
+// The buffer is shared with the copy's own copies.
+func (a *Log) WithTrace(n int) *Log {
+	if a == nil {
+		return nil
+	}
+	b := a.Copy()
+	if n > 0 {
+		b.trace = &traceBuffer{max: n}
+	} else {
+		b.trace = nil
+	}
+	return b
+}
+
+// Wraps an http.Handler, giving each request a logger with a trace buffer
+// of n entries.  The request's logger is used if its context has one, e.g.
+// from RequestIDHandler, so the handler should be inside it.
+func (a *Log) TraceHandler(h http.Handler, n int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log := FromContext(r.Context())
+		if log == nil {
+			log = a
+		}
+		ctx := NewContext(r.Context(), log.WithTrace(n))
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+type traceEntry struct {
+	level Level
+	e     Entry
+	p     []byte
+}
+
+// Ring of the most recent entries held back by a logger
+type traceBuffer struct {
+	max     int
+	entries []traceEntry
+	next    int
+	mutex   sync.Mutex
+}
+
+// Keeps copies of e and p, which belong to the caller's pools
+func (t *traceBuffer) add(level Level, e *Entry, p []byte) {
+	te := traceEntry{level, *e, append([]byte(nil), p...)}
+	te.e.Fields = append([]Field(nil), e.Fields...)
+
+	t.mutex.Lock()
+	if len(t.entries) < t.max {
+		t.entries = append(t.entries, te)
+	} else {
+		t.entries[t.next] = te
+		t.next = (t.next + 1) % t.max
+	}
+	t.mutex.Unlock()
+}
+
+// Removes and returns the buffered entries, oldest first
+func (t *traceBuffer) take() []traceEntry {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	entries := append(t.entries[t.next:len(t.entries):len(t.entries)], t.entries[:t.next]...)
+	t.entries = nil
+	t.next = 0
+	return entries
+}
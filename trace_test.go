@@ -0,0 +1,83 @@
+package alog
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestWithTrace(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.SetLevel(InfoLevel)
+
+	tr := log.WithTrace(2)
+	c.Assert(tr.DebugEnabled(), check.Equals, true)
+	tr.Debug("a")
+	tr.Debug("b")
+	tr.At(DebugLevel).Str("k", "v").Msg("c")
+	tr.Print("d")
+	c.Assert(t.msgs, check.DeepEquals, []string{"d\n"})
+
+	// The last n held entries precede the error
+	tr.With("x", 1).Error("e")
+	c.Assert(t.msgs[1:], check.DeepEquals, []string{
+		"DEBUG b\n",
+		"DEBUG [k=v] c\n",
+		"ERROR [x=1] e\n",
+	})
+
+	// The buffer is emptied
+	tr.Error("f")
+	c.Assert(t.last(), check.Equals, "ERROR f\n")
+	c.Assert(t.msgs, check.HasLen, 5)
+
+	// The original logger is unaffected
+	log.Debug("g")
+	log.Error("h")
+	c.Assert(t.last(), check.Equals, "ERROR h\n")
+	c.Assert(log.DebugEnabled(), check.Equals, false)
+
+	// Entries passed to an EntryWriter are copies
+	ew := &entryThief{}
+	tr = New(ew).WithTrace(4)
+	tr.SetLevel(InfoLevel)
+	tr.With("k", 1).Debug("a")
+	tr.Error("b")
+	c.Assert(ew.entries, check.HasLen, 2)
+	c.Assert(ew.entries[0].Message, check.Equals, "a")
+	c.Assert(ew.entries[0].Fields, check.DeepEquals, []Field{{"k", 1}})
+	c.Assert(ew.entries[0].Time.IsZero(), check.Equals, false)
+}
+
+func (s *Suite) TestTraceHandler(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.SetLevel(InfoLevel)
+
+	h := log.TraceHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := FromContext(r.Context())
+		l.Debug("start")
+		if r.URL.Path == "/fail" {
+			l.Error("failed")
+		}
+	}), 10)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	c.Assert(t.msgs, check.HasLen, 0)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+	c.Assert(t.msgs, check.DeepEquals, []string{"DEBUG start\n", "ERROR failed\n"})
+}
+
+type entryThief struct {
+	Thief
+	entries []Entry
+}
+
+func (t *entryThief) WriteEntry(e *Entry, p []byte) (int, error) {
+	t.entries = append(t.entries, *e)
+	return t.Write(p)
+}
@@ -0,0 +1,207 @@
+package alog
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Settings for a logging RoundTripper
+type TransportOptions struct {
+	// Retries requests that fail, or receive a 429, 502, 503 or 504
+	// response, if their body can be replayed.  nil to not retry.
+	Retry *RetryPolicy
+	// Log each request's and response's headers at debug level
+	Headers bool
+	// Log up to this many bytes of each request and response body at debug
+	// level.  Bodies also pass through the logger's scrubbers.
+	BodyLimit int
+	// Headers logged as [REDACTED], in addition to Authorization,
+	// Proxy-Authorization, Cookie and Set-Cookie
+	Redact []string
+}
+
+// Wraps an http.RoundTripper, http.DefaultTransport if nil, logging each
+// outbound request with the fields method, url, status, duration and
+// retries.  Failed requests are logged at ErrorLevel with their error, and
+// 5xx responses at WarnLevel.  The request's logger is used if its context
+// has one.
+//
+//	client := &http.Client{Transport: log.Transport(nil, alog.TransportOptions{})}
+func (a *Log) Transport(rt http.RoundTripper, opts TransportOptions) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	redact := map[string]bool{
+		"Authorization":       true,
+		"Proxy-Authorization": true,
+		"Cookie":              true,
+		"Set-Cookie":          true,
+	}
+	for _, h := range opts.Redact {
+		redact[http.CanonicalHeaderKey(h)] = true
+	}
+	t := &loggingTransport{log: a, rt: rt, opts: opts, redact: redact, sleep: sleepContext}
+	if opts.Retry != nil {
+		p := opts.Retry.withDefaults()
+		t.opts.Retry = &p
+	}
+	return t
+}
+
+type loggingTransport struct {
+	log    *Log
+	rt     http.RoundTripper
+	opts   TransportOptions
+	redact map[string]bool
+	sleep  func(*http.Request, time.Duration) error
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	log := FromContext(req.Context())
+	if log == nil {
+		log = t.log
+	}
+	debug := log.DebugEnabled() && (t.opts.Headers || t.opts.BodyLimit > 0)
+	if debug {
+		log.output(DebugLevel, message{fmtString, "http request", nil, t.debugFields(req.Header, t.requestBody(req))})
+	}
+
+	start := time.Now()
+	resp, retries, err := t.send(req)
+	fields := []Field{
+		{"method", req.Method},
+		{"url", req.URL.Redacted()},
+		{"status", 0},
+		{"duration", time.Since(start)},
+		{"retries", retries},
+	}
+	if err != nil {
+		fields = append(fields, Field{"error", err})
+		log.output(ErrorLevel, message{fmtString, "http request failed", nil, fields})
+		return nil, err
+	}
+
+	fields[2].Value = resp.StatusCode
+	level := InfoLevel
+	if resp.StatusCode >= 500 {
+		level = WarnLevel
+	}
+	log.output(level, message{fmtString, "http request", nil, fields})
+	if debug {
+		log.output(DebugLevel, message{fmtString, "http response", nil, t.debugFields(resp.Header, t.responseBody(resp))})
+	}
+	return resp, nil
+}
+
+// Sends req, retrying according to the policy.  Returns the number of
+// retries made.
+func (t *loggingTransport) send(req *http.Request) (*http.Response, int, error) {
+	attempts := 1
+	if p := t.opts.Retry; p != nil && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil) {
+		attempts = p.Attempts
+	}
+
+	for i := 0; ; i++ {
+		r := req
+		if i > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, i, err
+			}
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+
+		resp, err := t.rt.RoundTrip(r)
+		if i+1 >= attempts || !retryable(resp, err) || req.Context().Err() != nil {
+			return resp, i, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		if err := t.sleep(req, t.opts.Retry.delay(i)); err != nil {
+			return nil, i, err
+		}
+	}
+}
+
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Waits for d, or until the request is canceled
+func sleepContext(req *http.Request, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
+
+func (t *loggingTransport) debugFields(h http.Header, body []byte) []Field {
+	var fields []Field
+	if t.opts.Headers {
+		fields = append(fields, Field{"headers", t.headerGroup(h)})
+	}
+	if body != nil {
+		fields = append(fields, Field{"body", string(body)})
+	}
+	return fields
+}
+
+func (t *loggingTransport) headerGroup(h http.Header) GroupValue {
+	g := make(GroupValue, 0, len(h))
+	for k, v := range h {
+		if t.redact[k] {
+			g = append(g, Field{k, "[REDACTED]"})
+		} else {
+			g = append(g, Field{k, strings.Join(v, ", ")})
+		}
+	}
+	sort.Slice(g, func(i, j int) bool { return g[i].Key < g[j].Key })
+	return g
+}
+
+// Returns the start of the request's body, if it can be read again
+func (t *loggingTransport) requestBody(req *http.Request) []byte {
+	if t.opts.BodyLimit <= 0 || req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+	b, _ := io.ReadAll(io.LimitReader(body, int64(t.opts.BodyLimit)))
+	return b
+}
+
+// Returns the start of the response's body, which is put back for the
+// caller
+func (t *loggingTransport) responseBody(resp *http.Response) []byte {
+	if t.opts.BodyLimit <= 0 || resp.Body == nil || resp.Body == http.NoBody {
+		return nil
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, int64(t.opts.BodyLimit)))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(b), resp.Body), resp.Body}
+	return b
+}
@@ -0,0 +1,70 @@
+package alog
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"sync"
+)
+
+// Returns an io.WriteCloser that logs each line written to it, with the
+// logger's prefix.  Incomplete lines are held until their newline arrives,
+// and Close logs whatever remains.  Use it to capture the output of libraries
+// that only accept an io.Writer.  The lines have no caller, as the code that
+// wrote them isn't known, so Lshortfile and Llongfile are ignored.
+//
+// Note that this shadows log.Logger's Writer, which returns the destination
+// writer.
+func (a *Log) Writer() io.WriteCloser {
+	return &lineWriter{log: a}
+}
+
+// Returns a standard library logger that writes through this logger, for code
+// that requires a *log.Logger.  Timestamps and other flags are applied by
+// this logger, not the returned one.
+func (a *Log) StdLogger() *log.Logger {
+	return log.New(a.Writer(), "", 0)
+}
+
+type lineWriter struct {
+	log   *Log
+	buf   []byte
+	mutex sync.Mutex
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.writeLine(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Logs any incomplete line
+func (w *lineWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if len(w.buf) > 0 {
+		w.writeLine(string(w.buf))
+		w.buf = nil
+	}
+	return nil
+}
+
+func (w *lineWriter) writeLine(line string) {
+	m := message{fmtString, line, nil, nil}
+	if w.log == nil {
+		w.log.output(InfoLevel, m)
+	} else if w.log.enabled(InfoLevel) {
+		w.log.write(noCaller, InfoLevel, m)
+	}
+}
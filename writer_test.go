@@ -0,0 +1,53 @@
+package alog
+
+import (
+	"fmt"
+	stdlog "log"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *Suite) TestWriter(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("foo", "bar")
+
+	w := log.Writer()
+	fmt.Fprint(w, "one\ntwo\nthr")
+	c.Assert(t.msgs, check.DeepEquals, []string{"[foo=bar] one\n", "[foo=bar] two\n"})
+
+	fmt.Fprint(w, "ee\n")
+	checkLast(c, t, "[foo=bar] three")
+
+	// Close logs the incomplete line
+	fmt.Fprint(w, "four")
+	c.Assert(t.msgs, check.HasLen, 3)
+	c.Assert(w.Close(), check.IsNil)
+	checkLast(c, t, "[foo=bar] four")
+	c.Assert(w.Close(), check.IsNil)
+	c.Assert(t.msgs, check.HasLen, 4)
+
+	// Lines have no caller
+	log.SetFlags(stdlog.Lshortfile)
+	fmt.Fprint(w, "five\n")
+	checkLast(c, t, "[foo=bar] five")
+
+	// Nil safe
+	var nilLog *Log
+	fmt.Fprint(nilLog.Writer(), "foo\n")
+}
+
+func (s *Suite) TestStdLogger(c *check.C) {
+	t := &Thief{}
+	log := New(t)
+	log.SetFlags(0)
+	log.Set("foo", "bar")
+
+	std := log.StdLogger()
+	std.Print("one")
+	checkLast(c, t, "[foo=bar] one")
+	std.Printf("%d\n", 2)
+	checkLast(c, t, "[foo=bar] 2")
+	c.Assert(t.msgs, check.HasLen, 2)
+}
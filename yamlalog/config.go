@@ -0,0 +1,37 @@
+// Package yamlalog reads an alog.Config from YAML, using the yaml tags on
+// alog's config types:
+//
+//	cfg, err := yamlalog.LoadConfig("/etc/api/log.yaml")
+//	if err != nil {
+//		return err
+//	}
+//	log, err := alog.Build(cfg)
+package yamlalog
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/xsleonard/alog"
+	"gopkg.in/yaml.v3"
+)
+
+// Reads a YAML Config from a file.  Keys are those of the JSON form:
+//
+//	level: debug
+//	outputs:
+//	  - type: stderr
+//	  - type: file
+//	    path: /var/log/app.log
+//	sampling: {key: trace_id, rate: 0.1}
+func LoadConfig(path string) (alog.Config, error) {
+	var cfg alog.Config
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("alog: parsing %s: %v", path, err)
+	}
+	return cfg, nil
+}
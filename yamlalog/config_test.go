@@ -0,0 +1,53 @@
+package yamlalog
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/xsleonard/alog"
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type Suite struct{}
+
+var _ = check.Suite(&Suite{})
+
+func (s *Suite) TestLoadConfig(c *check.C) {
+	dir := c.MkDir()
+	path := filepath.Join(dir, "log.yaml")
+	c.Assert(ioutil.WriteFile(path, []byte(`
+level: warn
+format: pretty-json-compact
+color: true
+outputs:
+  - type: stderr
+  - type: file
+    path: /var/log/app.log
+    max_size: 1048576
+    max_backups: 3
+sampling: {key: trace, rate: 0.5}
+`), 0644), check.IsNil)
+
+	cfg, err := LoadConfig(path)
+	c.Assert(err, check.IsNil)
+	c.Assert(cfg, check.DeepEquals, alog.Config{
+		Level:  alog.WarnLevel,
+		Format: "pretty-json-compact",
+		Color:  true,
+		Outputs: []alog.OutputConfig{
+			{Type: "stderr"},
+			{Type: "file", Path: "/var/log/app.log", MaxSize: 1 << 20, MaxBackups: 3},
+		},
+		Sampling: &alog.SamplingConfig{Key: "trace", Rate: 0.5},
+	})
+
+	c.Assert(ioutil.WriteFile(path, []byte("level: loud\n"), 0644), check.IsNil)
+	_, err = LoadConfig(path)
+	c.Assert(err, check.ErrorMatches, `alog: parsing .*log.yaml: .*alog: unknown level "loud"`)
+
+	_, err = LoadConfig(filepath.Join(dir, "missing.yaml"))
+	c.Assert(err, check.NotNil)
+}
@@ -0,0 +1,255 @@
+// Package zapalog adapts alog to zap, so zap-based code writes through an
+// alog logger's writer and Meta prefix.
+package zapalog
+
+import (
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/xsleonard/alog"
+	"go.uber.org/zap/zapcore"
+)
+
+type core struct {
+	log *alog.Log
+}
+
+// Returns a zapcore.Core writing to log.  Zap fields are added to the
+// entry's Meta in order, after the logger's own.  Enabled levels follow
+// log's level.  Under Lshortfile or Llongfile, the caller is the one zap
+// records with zap.AddCaller, or else the first caller outside zap.
+//
+//	logger := zap.New(zapalog.NewCore(log))
+func NewCore(log *alog.Log) zapcore.Core {
+	return &core{log}
+}
+
+// Maps a zap level to alog's.  DPanic is logged as an error.
+func level(l zapcore.Level) alog.Level {
+	switch {
+	case l <= zapcore.DebugLevel:
+		return alog.DebugLevel
+	case l == zapcore.InfoLevel:
+		return alog.InfoLevel
+	case l == zapcore.WarnLevel:
+		return alog.WarnLevel
+	case l == zapcore.PanicLevel:
+		return alog.PanicLevel
+	case l == zapcore.FatalLevel:
+		return alog.FatalLevel
+	default:
+		return alog.ErrorLevel
+	}
+}
+
+func (c *core) Enabled(l zapcore.Level) bool {
+	return level(l) >= c.log.Level()
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	log := c.log.Copy()
+	setFields(log, fields)
+	return &core{log}
+}
+
+func (c *core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	log := c.log
+	if ent.LoggerName != "" || len(fields) > 0 {
+		log = log.Copy()
+		if ent.LoggerName != "" {
+			log.Set("logger", ent.LoggerName)
+		}
+		setFields(log, fields)
+	}
+
+	// Zap panics or exits after writing Panic and Fatal entries itself
+	return log.Output(callerDepth(ent), level(ent.Level), ent.Message)
+}
+
+// Returns the calldepth at which Output, called from Write, finds ent's
+// caller
+func callerDepth(ent zapcore.Entry) int {
+	var pcs [64]uintptr
+	// Skip runtime.Callers, this function and Write
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for depth := 2; ; depth++ {
+		f, more := frames.Next()
+		if ent.Caller.Defined {
+			if f.File == ent.Caller.File && f.Line == ent.Caller.Line {
+				return depth
+			}
+		} else if !strings.HasPrefix(f.Function, "go.uber.org/zap.") && !strings.HasPrefix(f.Function, "go.uber.org/zap/") {
+			return depth
+		}
+		if !more {
+			return 1
+		}
+	}
+}
+
+func (c *core) Sync() error {
+	return c.log.Flush()
+}
+
+// Sets each field on log, in order, using zap's encoding of its value
+func setFields(log *alog.Log, fields []zapcore.Field) {
+	enc := &orderedEncoder{MapObjectEncoder: zapcore.NewMapObjectEncoder()}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	for _, k := range enc.keys {
+		log.Set(k, enc.Fields[k])
+	}
+}
+
+// MapObjectEncoder that remembers the order its top level keys were added
+// in.  Keys added after a namespace is opened go into the namespace.
+type orderedEncoder struct {
+	*zapcore.MapObjectEncoder
+	keys       []string
+	namespaced bool
+}
+
+func (e *orderedEncoder) key(k string) {
+	if e.namespaced {
+		return
+	}
+	if _, ok := e.Fields[k]; !ok {
+		e.keys = append(e.keys, k)
+	}
+}
+
+func (e *orderedEncoder) AddArray(k string, v zapcore.ArrayMarshaler) error {
+	e.key(k)
+	return e.MapObjectEncoder.AddArray(k, v)
+}
+
+func (e *orderedEncoder) AddObject(k string, v zapcore.ObjectMarshaler) error {
+	e.key(k)
+	return e.MapObjectEncoder.AddObject(k, v)
+}
+
+func (e *orderedEncoder) AddBinary(k string, v []byte) {
+	e.key(k)
+	e.MapObjectEncoder.AddBinary(k, v)
+}
+
+func (e *orderedEncoder) AddByteString(k string, v []byte) {
+	e.key(k)
+	e.MapObjectEncoder.AddByteString(k, v)
+}
+
+func (e *orderedEncoder) AddBool(k string, v bool) {
+	e.key(k)
+	e.MapObjectEncoder.AddBool(k, v)
+}
+
+func (e *orderedEncoder) AddComplex128(k string, v complex128) {
+	e.key(k)
+	e.MapObjectEncoder.AddComplex128(k, v)
+}
+
+func (e *orderedEncoder) AddComplex64(k string, v complex64) {
+	e.key(k)
+	e.MapObjectEncoder.AddComplex64(k, v)
+}
+
+func (e *orderedEncoder) AddDuration(k string, v time.Duration) {
+	e.key(k)
+	e.MapObjectEncoder.AddDuration(k, v)
+}
+
+func (e *orderedEncoder) AddFloat64(k string, v float64) {
+	e.key(k)
+	e.MapObjectEncoder.AddFloat64(k, v)
+}
+
+func (e *orderedEncoder) AddFloat32(k string, v float32) {
+	e.key(k)
+	e.MapObjectEncoder.AddFloat32(k, v)
+}
+
+func (e *orderedEncoder) AddInt(k string, v int) {
+	e.key(k)
+	e.MapObjectEncoder.AddInt(k, v)
+}
+
+func (e *orderedEncoder) AddInt64(k string, v int64) {
+	e.key(k)
+	e.MapObjectEncoder.AddInt64(k, v)
+}
+
+func (e *orderedEncoder) AddInt32(k string, v int32) {
+	e.key(k)
+	e.MapObjectEncoder.AddInt32(k, v)
+}
+
+func (e *orderedEncoder) AddInt16(k string, v int16) {
+	e.key(k)
+	e.MapObjectEncoder.AddInt16(k, v)
+}
+
+func (e *orderedEncoder) AddInt8(k string, v int8) {
+	e.key(k)
+	e.MapObjectEncoder.AddInt8(k, v)
+}
+
+func (e *orderedEncoder) AddString(k string, v string) {
+	e.key(k)
+	e.MapObjectEncoder.AddString(k, v)
+}
+
+func (e *orderedEncoder) AddTime(k string, v time.Time) {
+	e.key(k)
+	e.MapObjectEncoder.AddTime(k, v)
+}
+
+func (e *orderedEncoder) AddUint(k string, v uint) {
+	e.key(k)
+	e.MapObjectEncoder.AddUint(k, v)
+}
+
+func (e *orderedEncoder) AddUint64(k string, v uint64) {
+	e.key(k)
+	e.MapObjectEncoder.AddUint64(k, v)
+}
+
+func (e *orderedEncoder) AddUint32(k string, v uint32) {
+	e.key(k)
+	e.MapObjectEncoder.AddUint32(k, v)
+}
+
+func (e *orderedEncoder) AddUint16(k string, v uint16) {
+	e.key(k)
+	e.MapObjectEncoder.AddUint16(k, v)
+}
+
+func (e *orderedEncoder) AddUint8(k string, v uint8) {
+	e.key(k)
+	e.MapObjectEncoder.AddUint8(k, v)
+}
+
+func (e *orderedEncoder) AddUintptr(k string, v uintptr) {
+	e.key(k)
+	e.MapObjectEncoder.AddUintptr(k, v)
+}
+
+func (e *orderedEncoder) AddReflected(k string, v interface{}) error {
+	e.key(k)
+	return e.MapObjectEncoder.AddReflected(k, v)
+}
+
+func (e *orderedEncoder) OpenNamespace(k string) {
+	e.key(k)
+	e.MapObjectEncoder.OpenNamespace(k)
+	e.namespaced = true
+}
@@ -0,0 +1,98 @@
+package zapalog
+
+import (
+	"errors"
+	"fmt"
+	stdlog "log"
+	"runtime"
+	"testing"
+
+	"github.com/xsleonard/alog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type Suite struct{}
+
+var _ = check.Suite(&Suite{})
+
+type Thief struct {
+	msgs []string
+}
+
+func (t *Thief) Write(s []byte) (int, error) {
+	t.msgs = append(t.msgs, string(s))
+	return len(s), nil
+}
+
+func (s *Suite) TestCore(c *check.C) {
+	t := &Thief{}
+	log := alog.New(t)
+	log.SetFlags(0)
+	log.Set("app", "api")
+
+	z := zap.New(NewCore(log))
+	z.Info("hello", zap.String("user", "alice"), zap.Int("n", 3))
+	z.Debug("hidden")
+	z.Named("db").Warn("slow", zap.Error(errors.New("timeout")))
+	z.With(zap.Bool("cached", true)).Error("failed")
+
+	c.Assert(t.msgs, check.DeepEquals, []string{
+		"[app=api user=alice n=3] hello\n",
+		"WARN [app=api logger=db error=timeout] slow\n",
+		"ERROR [app=api cached=true] failed\n",
+	})
+
+	// Levels follow the alog logger
+	log.SetLevel(alog.DebugLevel)
+	z.Debug("shown")
+	c.Assert(t.msgs[len(t.msgs)-1], check.Equals, "DEBUG [app=api] shown\n")
+
+	// Zap does the panicking
+	c.Assert(func() { z.Panic("boom") }, check.Panics, "boom")
+	c.Assert(t.msgs[len(t.msgs)-1], check.Equals, "PANIC [app=api] boom\n")
+
+	c.Assert(z.Sync(), check.IsNil)
+}
+
+func (s *Suite) TestCoreFieldOrder(c *check.C) {
+	t := &Thief{}
+	log := alog.New(t)
+	log.SetFlags(0)
+
+	z := zap.New(NewCore(log))
+	z.Info("ordered", zap.Inline(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		for _, k := range []string{"z", "m", "a", "q", "b"} {
+			enc.AddString(k, k)
+		}
+		return nil
+	})), zap.Namespace("ns"), zap.Int("n", 1))
+	c.Assert(t.msgs, check.DeepEquals, []string{"[z=z m=m a=a q=q b=b ns=map[n:1]] ordered\n"})
+}
+
+// Logs through a helper that zap skips with AddCallerSkip
+func logVia(z *zap.Logger) {
+	z.Info("via helper")
+}
+
+func (s *Suite) TestCoreCaller(c *check.C) {
+	t := &Thief{}
+	log := alog.New(t)
+	log.SetFlags(stdlog.Lshortfile)
+
+	// Without zap's caller, the first frame outside zap
+	z := zap.New(NewCore(log))
+	z.Info("direct")
+	c.Assert(t.msgs[0], check.Matches, `core_test\.go:\d+: direct\n`)
+	z.Sugar().Infof("sugared")
+	c.Assert(t.msgs[1], check.Matches, `core_test\.go:\d+: sugared\n`)
+
+	// With it, the frame zap chose
+	z = zap.New(NewCore(log), zap.AddCaller(), zap.AddCallerSkip(1))
+	_, _, line, _ := runtime.Caller(0)
+	logVia(z)
+	c.Assert(t.msgs[2], check.Equals, fmt.Sprintf("core_test.go:%d: via helper\n", line+1))
+}